@@ -3,6 +3,7 @@ package sdk
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/algorand/go-algorand-sdk/v2/abi"
 	"github.com/algorand/go-algorand-sdk/v2/encoding/json"
@@ -68,10 +69,11 @@ func ABIMethodJSONFromSignature(signature string) (string, error) {
 	return string(json.Encode(method)), nil
 }
 
-// GetABIMethodSignature takes a method JSON representation and returns the method signature.
+// GetABIMethodSignature takes a method JSON representation and returns the method signature. Fields
+// unknown to ARC-4, such as those of ARC-56 method descriptions, are ignored.
 func GetABIMethodSignature(methodJSON string) (string, error) {
 	var method abi.Method
-	err := json.Decode([]byte(methodJSON), &method)
+	err := json.LenientDecode([]byte(methodJSON), &method)
 	if err != nil {
 		return "", err
 	}
@@ -81,6 +83,9 @@ func GetABIMethodSignature(methodJSON string) (string, error) {
 // AtomicTransactionComposer is a class for constructing and signing atomic transaction groups.
 type AtomicTransactionComposer struct {
 	value transaction.AtomicTransactionComposer
+
+	// methods holds the method JSON of each transaction in the group, or "" if it is not a method call
+	methods []string
 }
 
 // NewAtomicTransactionComposer creates a new AtomicTransactionComposer.
@@ -110,7 +115,20 @@ func (c *AtomicTransactionComposer) Count() int {
 // Clone creates a new composer with the same underlying transactions. The new composer's status will
 // be BUILDING, so additional transactions may be added to it.
 func (c *AtomicTransactionComposer) Clone() *AtomicTransactionComposer {
-	return &AtomicTransactionComposer{c.value.Clone()}
+	return &AtomicTransactionComposer{
+		value:   c.value.Clone(),
+		methods: append([]string(nil), c.methods...),
+	}
+}
+
+// GetMethodJSON returns the JSON description of the method called by the transaction at the given
+// index, as passed to `NewAddMethodCallParams()`, or an empty string if the transaction is not a
+// method call.
+func (c *AtomicTransactionComposer) GetMethodJSON(index int) string {
+	if index < 0 || index >= len(c.methods) {
+		return ""
+	}
+	return c.methods[index]
 }
 
 // AddTransaction adds a transaction to this atomic group.
@@ -123,15 +141,25 @@ func (c *AtomicTransactionComposer) AddTransaction(encodedTx []byte, signer Tran
 	if err != nil {
 		return err
 	}
-	return c.value.AddTransaction(transaction.TransactionWithSigner{
+	err = c.value.AddTransaction(transaction.TransactionWithSigner{
 		Txn:    tx,
 		Signer: externalToInternalSigner{signer},
 	})
+	if err != nil {
+		return err
+	}
+	c.methods = append(c.methods, "")
+	return nil
 }
 
 // AddMethodCallParams contains the parameters for the method `AtomicTransactionComposer.AddMethodCall`
 type AddMethodCallParams struct {
-	value transaction.AddMethodCallParams
+	value      transaction.AddMethodCallParams
+	methodJSON string
+
+	// argStructs holds the ARC-56 struct name of each method argument, if any
+	argStructs []string
+	structs    *ABIStructDefinitions
 }
 
 // NewAddMethodCallParams creates a new AddMethodCallParams object.
@@ -163,11 +191,22 @@ func NewAddMethodCallParams(
 		return nil, fmt.Errorf("invalid onComplete value: %d", onComplete)
 	}
 
+	// ARC-56 method descriptions contain fields unknown to abi.Method, such as "struct"
 	var method abi.Method
-	err := json.Decode([]byte(methodJson), &method)
+	err := json.LenientDecode([]byte(methodJson), &method)
 	if err != nil {
 		return nil, fmt.Errorf("could not decode method from JSON: %w", err)
 	}
+	methodStructs, err := parseMethodStructs(methodJson)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode method from JSON: %w", err)
+	}
+	argStructs := make([]string, len(method.Args))
+	for i := range methodStructs.Args {
+		if i < len(argStructs) {
+			argStructs[i] = methodStructs.Args[i].Struct
+		}
+	}
 
 	internalForeignApps := make([]uint64, foreignApps.Length())
 	for i := range internalForeignApps {
@@ -210,12 +249,22 @@ func NewAddMethodCallParams(
 		Sender:          senderAddr,
 		Signer:          externalToInternalSigner{signer},
 	}
-	return &AddMethodCallParams{params}, nil
+	return &AddMethodCallParams{value: params, methodJSON: methodJson, argStructs: argStructs}, nil
+}
+
+// SetStructDefinitions sets the ARC-56 struct definitions used by AddMethodArgument. Once set,
+// arguments which the method JSON marks with a struct name, as in
+// `{"type":"(address,uint64)","struct":"Order"}`, may be given as JSON objects with named fields.
+func (p *AddMethodCallParams) SetStructDefinitions(structs *ABIStructDefinitions) {
+	p.structs = structs
 }
 
 // AddMethodArgument adds an ABI argument to the method call. This uses the same format as `ABIType.Encode()`
 // for the argument value. This method can handle basic and reference ABI types, but not transaction
 // argument types. See `AddMethodArgumentTransaction()` for transaction type support.
+//
+// If struct definitions were provided with `SetStructDefinitions()`, struct arguments may also be
+// given as JSON objects with named fields.
 func (p *AddMethodCallParams) AddMethodArgument(valueJson string) error {
 	numArgs := len(p.value.MethodArgs)
 	if numArgs+1 > len(p.value.Method.Args) {
@@ -248,6 +297,13 @@ func (p *AddMethodCallParams) AddMethodArgument(valueJson string) error {
 			return err
 		}
 	}
+	if p.structs != nil && p.argStructs[numArgs] != "" && strings.HasPrefix(strings.TrimSpace(valueJson), "{") {
+		tupleJson, err := p.structs.StructToTupleJSON(p.argStructs[numArgs], valueJson)
+		if err != nil {
+			return fmt.Errorf("cannot convert struct value for argument type %s: %w", argSpec.Type, err)
+		}
+		valueJson = tupleJson
+	}
 	goValue, err := typeToDecode.UnmarshalFromJSON([]byte(valueJson))
 	if err != nil {
 		return fmt.Errorf("cannot decode JSON value for argument type %s: %w", argSpec.Type, err)
@@ -302,13 +358,34 @@ func (p *AddMethodCallParams) AddAppSchema(globalSchemaUint, globalSchemaByteSli
 	return nil
 }
 
+// SetTransactionOptions sets the lease and rekey address of the method call transaction. Nil
+// options clear both.
+func (p *AddMethodCallParams) SetTransactionOptions(options *TransactionOptions) {
+	p.value.Lease = [32]byte{}
+	p.value.RekeyTo = types.Address{}
+	if options != nil {
+		p.value.Lease = options.lease
+		p.value.RekeyTo = options.rekeyTo
+	}
+}
+
 // AddMethodCall adds a smart contract method call to this atomic group.
 //
 // An error will be thrown if the composer's status is not BUILDING, if adding this transaction
 // causes the current group to exceed MaxAtomicGroupSize (16), or if the provided arguments are invalid
 // for the given method.
 func (c *AtomicTransactionComposer) AddMethodCall(params *AddMethodCallParams) error {
-	return c.value.AddMethodCall(params.value)
+	countBefore := c.value.Count()
+	err := c.value.AddMethodCall(params.value)
+	if err != nil {
+		return err
+	}
+	// Transaction arguments precede the application call in the group
+	for i := countBefore; i < c.value.Count()-1; i++ {
+		c.methods = append(c.methods, "")
+	}
+	c.methods = append(c.methods, params.methodJSON)
+	return nil
 }
 
 // BuildGroup finalizes the transaction group and returns the finalized unsigned transactions.
@@ -76,6 +76,8 @@ func TestABIMethodFunctions(t *testing.T) {
 	t.Parallel()
 	methodJSONWithArgNames := `{"name":"add","args":[{"name":"a","type":"uint8"},{"name":"b","type":"uint16"}],"returns":{"type":"uint32"}}`
 	methodJSONWithoutArgNames := `{"name":"add","args":[{"type":"uint8"},{"type":"uint16"}],"returns":{"type":"uint32"}}`
+	// ARC-56 method descriptions have fields unknown to ARC-4
+	methodJSONARC56 := `{"name":"add","args":[{"name":"a","type":"uint8","struct":null},{"name":"b","type":"uint16"}],"returns":{"type":"uint32"},"actions":{"create":[],"call":["NoOp"]},"readonly":true,"events":[],"recommendations":{}}`
 	signature := "add(uint8,uint16)uint32"
 
 	methodFromSignature, err := ABIMethodJSONFromSignature(signature)
@@ -83,7 +85,7 @@ func TestABIMethodFunctions(t *testing.T) {
 	ok, err := jsonEqual(methodJSONWithoutArgNames, methodFromSignature)
 	require.True(t, ok, "expected: %s, actual %s", methodJSONWithoutArgNames, methodFromSignature)
 
-	for _, method := range []string{methodJSONWithArgNames, methodJSONWithoutArgNames} {
+	for _, method := range []string{methodJSONWithArgNames, methodJSONWithoutArgNames, methodJSONARC56} {
 		signatureFromJSON, err := GetABIMethodSignature(method)
 		require.NoError(t, err)
 		require.Equal(t, signature, signatureFromJSON)
@@ -314,7 +316,7 @@ func TestATCAddMethodCall(t *testing.T) {
 
 	err = atc.AddMethodCall(
 		&AddMethodCallParams{
-			transaction.AddMethodCallParams{
+			value: transaction.AddMethodCallParams{
 				AppID:  4,
 				Method: method,
 				Sender: addr,
@@ -351,7 +353,7 @@ func TestATCAddMethodCallWithManualForeignArgs(t *testing.T) {
 		ForeignAssets:   []uint64{5},
 		ForeignAccounts: []string{arg_addr_str},
 	}
-	err = atc.AddMethodCall(&AddMethodCallParams{params})
+	err = atc.AddMethodCall(&AddMethodCallParams{value: params})
 	require.NoError(t, err)
 	require.Equal(t, atc.GetStatus(), transaction.BUILDING)
 	require.Equal(t, atc.Count(), 1)
@@ -0,0 +1,333 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+)
+
+// abiStructField is a single field of an ARC-56 struct. The type of a field is either an ABI type,
+// the name of another struct, or an anonymous struct described by fields.
+type abiStructField struct {
+	name     string
+	typeName string
+	fields   []abiStructField
+}
+
+// ABIStructDefinitions holds the named structs of an application, as defined by ARC-56. Structs
+// are encoded as ABI tuples, and these definitions allow converting between JSON objects with named
+// fields and the positional JSON arrays used for tuples by `ABIType.Encode()` and `ABIType.Decode()`.
+type ABIStructDefinitions struct {
+	structs map[string][]abiStructField
+}
+
+type arc56StructField struct {
+	Name string          `json:"name"`
+	Type json.RawMessage `json:"type"`
+}
+
+func parseStructFields(specFields []arc56StructField) ([]abiStructField, error) {
+	fields := make([]abiStructField, len(specFields))
+	for i, specField := range specFields {
+		if specField.Name == "" {
+			return nil, fmt.Errorf("struct field %d has no name", i)
+		}
+		fields[i].name = specField.Name
+		if bytes.HasPrefix(bytes.TrimSpace(specField.Type), []byte{'['}) {
+			var nested []arc56StructField
+			err := json.Unmarshal(specField.Type, &nested)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode fields of '%s': %w", specField.Name, err)
+			}
+			fields[i].fields, err = parseStructFields(nested)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err := json.Unmarshal(specField.Type, &fields[i].typeName)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode type of '%s': %w", specField.Name, err)
+		}
+	}
+	return fields, nil
+}
+
+// ABIStructDefinitionsFromJSON parses struct definitions in the format of the "structs" section of
+// an ARC-56 app spec:
+//
+//	{"Order":[{"name":"owner","type":"address"},{"name":"amount","type":"uint64"}]}
+func ABIStructDefinitionsFromJSON(structsJSON string) (*ABIStructDefinitions, error) {
+	var spec map[string][]arc56StructField
+	err := json.Unmarshal([]byte(structsJSON), &spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode struct definitions: %w", err)
+	}
+	return makeABIStructDefinitions(spec)
+}
+
+// ParseARC56Structs returns the struct definitions declared in the "structs" section of an ARC-56
+// app spec.
+func ParseARC56Structs(arc56JSON string) (*ABIStructDefinitions, error) {
+	var spec struct {
+		Structs map[string][]arc56StructField `json:"structs"`
+	}
+	err := json.Unmarshal([]byte(arc56JSON), &spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ARC-56 app spec: %w", err)
+	}
+	return makeABIStructDefinitions(spec.Structs)
+}
+
+func makeABIStructDefinitions(spec map[string][]arc56StructField) (*ABIStructDefinitions, error) {
+	defs := &ABIStructDefinitions{structs: make(map[string][]abiStructField, len(spec))}
+	for name, specFields := range spec {
+		fields, err := parseStructFields(specFields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid struct '%s': %w", name, err)
+		}
+		defs.structs[name] = fields
+	}
+	// Resolve every struct now to report unknown types and recursive definitions early
+	for name := range defs.structs {
+		_, err := defs.tupleTypeString(name, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+// HasStruct returns true if a struct with the given name is defined.
+func (d *ABIStructDefinitions) HasStruct(structName string) bool {
+	_, ok := d.structs[structName]
+	return ok
+}
+
+func (d *ABIStructDefinitions) lookup(structName string) ([]abiStructField, error) {
+	fields, ok := d.structs[structName]
+	if !ok {
+		return nil, fmt.Errorf("unknown struct: '%s'", structName)
+	}
+	return fields, nil
+}
+
+func (d *ABIStructDefinitions) tupleTypeString(structName string, seen []string) (string, error) {
+	for _, s := range seen {
+		if s == structName {
+			return "", fmt.Errorf("struct '%s' is recursive", structName)
+		}
+	}
+	fields, err := d.lookup(structName)
+	if err != nil {
+		return "", err
+	}
+	return d.fieldsTypeString(fields, append(seen, structName))
+}
+
+func (d *ABIStructDefinitions) fieldsTypeString(fields []abiStructField, seen []string) (string, error) {
+	types := make([]string, len(fields))
+	for i, field := range fields {
+		var err error
+		switch {
+		case field.fields != nil:
+			types[i], err = d.fieldsTypeString(field.fields, seen)
+		case d.HasStruct(field.typeName):
+			types[i], err = d.tupleTypeString(field.typeName, seen)
+		default:
+			_, err = abi.TypeOf(field.typeName)
+			types[i] = field.typeName
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid field '%s': %w", field.name, err)
+		}
+	}
+	return "(" + strings.Join(types, ",") + ")", nil
+}
+
+// ABIType returns the tuple type which a struct is encoded as.
+func (d *ABIStructDefinitions) ABIType(structName string) (*ABIType, error) {
+	typeString, err := d.tupleTypeString(structName, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ParseABIType(typeString)
+}
+
+// fieldsToTuple converts a JSON object with named fields into a positional JSON array.
+func (d *ABIStructDefinitions) fieldsToTuple(fields []abiStructField, namedJSON []byte) (json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	err := json.Unmarshal(namedJSON, &object)
+	if err != nil {
+		return nil, fmt.Errorf("cannot cast JSON encoded (%s) to struct: %w", string(namedJSON), err)
+	}
+	if len(object) != len(fields) {
+		return nil, fmt.Errorf("struct has %d fields, but the JSON object has %d", len(fields), len(object))
+	}
+	values := make([]json.RawMessage, len(fields))
+	for i, field := range fields {
+		value, ok := object[field.name]
+		if !ok {
+			return nil, fmt.Errorf("missing struct field '%s'", field.name)
+		}
+		switch {
+		case field.fields != nil:
+			value, err = d.fieldsToTuple(field.fields, value)
+		case d.HasStruct(field.typeName):
+			value, err = d.fieldsToTuple(d.structs[field.typeName], value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return json.Marshal(values)
+}
+
+// tupleToFields converts a positional JSON array into a JSON object with named fields. The fields
+// keep the order of the struct definition.
+func (d *ABIStructDefinitions) tupleToFields(fields []abiStructField, tupleJSON []byte) (json.RawMessage, error) {
+	var values []json.RawMessage
+	err := json.Unmarshal(tupleJSON, &values)
+	if err != nil {
+		return nil, fmt.Errorf("cannot cast JSON encoded (%s) to tuple: %w", string(tupleJSON), err)
+	}
+	if len(values) != len(fields) {
+		return nil, fmt.Errorf("struct has %d fields, but the tuple has %d elements", len(fields), len(values))
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		value := values[i]
+		switch {
+		case field.fields != nil:
+			value, err = d.tupleToFields(field.fields, value)
+		case d.HasStruct(field.typeName):
+			value, err = d.tupleToFields(d.structs[field.typeName], value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// StructToTupleJSON converts a struct value given as a JSON object with named fields into the
+// positional JSON array accepted by `ABIType.Encode()`. Nested structs are converted as well.
+func (d *ABIStructDefinitions) StructToTupleJSON(structName, jsonValue string) (string, error) {
+	fields, err := d.lookup(structName)
+	if err != nil {
+		return "", err
+	}
+	tuple, err := d.fieldsToTuple(fields, []byte(jsonValue))
+	return string(tuple), err
+}
+
+// TupleToStructJSON converts a positional JSON array, as returned by `ABIType.Decode()`, into a JSON
+// object with the named fields of a struct. Nested structs are converted as well.
+func (d *ABIStructDefinitions) TupleToStructJSON(structName, tupleJSON string) (string, error) {
+	fields, err := d.lookup(structName)
+	if err != nil {
+		return "", err
+	}
+	object, err := d.tupleToFields(fields, []byte(tupleJSON))
+	return string(object), err
+}
+
+// Encode takes a struct value as a JSON object with named fields and encodes it into a byte array.
+func (d *ABIStructDefinitions) Encode(structName, jsonValue string) ([]byte, error) {
+	abiType, err := d.ABIType(structName)
+	if err != nil {
+		return nil, err
+	}
+	tupleJSON, err := d.StructToTupleJSON(structName, jsonValue)
+	if err != nil {
+		return nil, err
+	}
+	return abiType.Encode(tupleJSON)
+}
+
+// Decode takes an encoded struct value and decodes it into a JSON object with named fields.
+func (d *ABIStructDefinitions) Decode(structName string, encodedValue []byte) (string, error) {
+	abiType, err := d.ABIType(structName)
+	if err != nil {
+		return "", err
+	}
+	tupleJSON, err := abiType.Decode(encodedValue)
+	if err != nil {
+		return "", err
+	}
+	return d.TupleToStructJSON(structName, tupleJSON)
+}
+
+// arc56MethodStructs holds the struct names which an ARC-56 method description attaches to its
+// arguments and return value. The abi.Method type does not retain these.
+type arc56MethodStructs struct {
+	Args []struct {
+		Struct string `json:"struct"`
+	} `json:"args"`
+	Returns struct {
+		Struct string `json:"struct"`
+	} `json:"returns"`
+}
+
+func parseMethodStructs(methodJSON string) (arc56MethodStructs, error) {
+	var methodStructs arc56MethodStructs
+	err := json.Unmarshal([]byte(methodJSON), &methodStructs)
+	return methodStructs, err
+}
+
+// abiReturnPrefix is the prefix of the log entry which holds the return value of an ABI method call.
+var abiReturnPrefix = []byte{0x15, 0x1f, 0x7c, 0x75}
+
+// DecodeABIMethodReturn decodes the return value of an ABI method call from the last log entry of
+// the application call transaction.
+//
+// methodJSON is the JSON description of the method. If the method's return value refers to an
+// ARC-56 struct, as in `"returns":{"type":"(address,uint64)","struct":"Order"}`, and structs is not
+// nil, the result is a JSON object with named fields. Otherwise it has the same format as
+// `ABIType.Decode()`.
+//
+// An empty string is returned for methods which return void.
+func DecodeABIMethodReturn(methodJSON string, structs *ABIStructDefinitions, lastLog []byte) (string, error) {
+	var method abi.Method
+	err := json.Unmarshal([]byte(methodJSON), &method)
+	if err != nil {
+		return "", fmt.Errorf("could not decode method from JSON: %w", err)
+	}
+	if method.Returns.IsVoid() {
+		return "", nil
+	}
+	if !bytes.HasPrefix(lastLog, abiReturnPrefix) {
+		return "", errors.New("log entry does not contain an ABI return value")
+	}
+	returnType, err := method.Returns.GetTypeObject()
+	if err != nil {
+		return "", err
+	}
+	decoded, err := (&ABIType{returnType}).Decode(lastLog[len(abiReturnPrefix):])
+	if err != nil {
+		return "", fmt.Errorf("could not decode return value: %w", err)
+	}
+
+	methodStructs, err := parseMethodStructs(methodJSON)
+	if err != nil {
+		return "", err
+	}
+	if structs == nil || methodStructs.Returns.Struct == "" {
+		return decoded, nil
+	}
+	return structs.TupleToStructJSON(methodStructs.Returns.Struct, decoded)
+}
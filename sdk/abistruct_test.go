@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testARC56StructSpec = `{
+	"name": "Exchange",
+	"structs": {
+		"Order": [
+			{"name": "owner", "type": "address"},
+			{"name": "amount", "type": "uint64"},
+			{"name": "limits", "type": "Limits"}
+		],
+		"Limits": [
+			{"name": "min", "type": "uint32"},
+			{"name": "max", "type": "uint32"}
+		],
+		"Receipt": [
+			{"name": "id", "type": "uint64"},
+			{"name": "meta", "type": [{"name": "filled", "type": "bool"}, {"name": "memo", "type": "string"}]}
+		]
+	}
+}`
+
+func TestABIStructDefinitions(t *testing.T) {
+	t.Parallel()
+	structs, err := ParseARC56Structs(testARC56StructSpec)
+	require.NoError(t, err)
+	require.True(t, structs.HasStruct("Order"))
+	require.False(t, structs.HasStruct("Missing"))
+
+	orderType, err := structs.ABIType("Order")
+	require.NoError(t, err)
+	require.Equal(t, "(address,uint64,(uint32,uint32))", orderType.String())
+
+	orderJSON := `{"owner":"DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA","amount":5,"limits":{"min":1,"max":10}}`
+	tupleJSON, err := structs.StructToTupleJSON("Order", orderJSON)
+	require.NoError(t, err)
+	require.JSONEq(t, `["DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA",5,[1,10]]`, tupleJSON)
+
+	encoded, err := structs.Encode("Order", orderJSON)
+	require.NoError(t, err)
+	expected, err := orderType.Encode(tupleJSON)
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	decoded, err := structs.Decode("Order", encoded)
+	require.NoError(t, err)
+	// fields keep the order of the struct definition
+	require.Equal(t, orderJSON, decoded)
+
+	receiptJSON := `{"id":3,"meta":{"filled":true,"memo":"done"}}`
+	encoded, err = structs.Encode("Receipt", receiptJSON)
+	require.NoError(t, err)
+	decoded, err = structs.Decode("Receipt", encoded)
+	require.NoError(t, err)
+	require.Equal(t, receiptJSON, decoded)
+
+	_, err = structs.Encode("Order", `{"owner":"DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA","amount":5}`)
+	require.Error(t, err)
+	_, err = structs.Encode("Order", `{"owner":"DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA","amount":5,"limit":{"min":1,"max":10}}`)
+	require.Error(t, err)
+	_, err = structs.Encode("Missing", `{}`)
+	require.Error(t, err)
+}
+
+func TestABIStructDefinitionsInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := ABIStructDefinitionsFromJSON(`{"A":[{"name":"b","type":"B"}],"B":[{"name":"a","type":"A"}]}`)
+	require.Error(t, err)
+
+	_, err = ABIStructDefinitionsFromJSON(`{"A":[{"name":"x","type":"NotAType"}]}`)
+	require.Error(t, err)
+
+	structs, err := ABIStructDefinitionsFromJSON(`{"Pair":[{"name":"a","type":"uint8"},{"name":"b","type":"uint8"}]}`)
+	require.NoError(t, err)
+	pairType, err := structs.ABIType("Pair")
+	require.NoError(t, err)
+	require.Equal(t, "(uint8,uint8)", pairType.String())
+}
+
+func TestAddMethodArgumentStruct(t *testing.T) {
+	t.Parallel()
+	structs, err := ParseARC56Structs(testARC56StructSpec)
+	require.NoError(t, err)
+
+	methodJSON := `{"name":"place","args":[{"type":"(address,uint64,(uint32,uint32))","struct":"Order","name":"order"},{"type":"uint64","name":"nonce"}],"returns":{"type":"void"}}`
+	params, err := NewAddMethodCallParams(
+		1,
+		0,
+		methodJSON,
+		&StringArray{},
+		&Int64Array{},
+		&Int64Array{},
+		&AppBoxRefArray{},
+		&SuggestedParams{Fee: 1000, FlatFee: true, FirstRoundValid: 1, LastRoundValid: 1001},
+		nil,
+		"DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA",
+		nil,
+	)
+	require.NoError(t, err)
+
+	orderJSON := `{"owner":"DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA","amount":5,"limits":{"min":1,"max":10}}`
+
+	// named objects are rejected without struct definitions
+	require.Error(t, params.AddMethodArgument(orderJSON))
+
+	params.SetStructDefinitions(structs)
+	require.NoError(t, params.AddMethodArgument(orderJSON))
+	require.NoError(t, params.AddMethodArgument(`7`))
+
+	owner := mustDecodeAddress(t, "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA")
+	require.Equal(t, []interface{}{owner[:], uint64(5), []interface{}{uint32(1), uint32(10)}}, params.value.MethodArgs[0])
+	require.Equal(t, types.Address(owner), params.value.Sender)
+}
+
+func TestDecodeABIMethodReturn(t *testing.T) {
+	t.Parallel()
+	structs, err := ParseARC56Structs(testARC56StructSpec)
+	require.NoError(t, err)
+
+	limitsType, err := structs.ABIType("Limits")
+	require.NoError(t, err)
+	encoded, err := limitsType.Encode(`[2,20]`)
+	require.NoError(t, err)
+	returnLog := append([]byte{0x15, 0x1f, 0x7c, 0x75}, encoded...)
+
+	methodJSON := `{"name":"limits","args":[],"returns":{"type":"(uint32,uint32)","struct":"Limits"}}`
+	decoded, err := DecodeABIMethodReturn(methodJSON, structs, returnLog)
+	require.NoError(t, err)
+	require.Equal(t, `{"min":2,"max":20}`, decoded)
+
+	decoded, err = DecodeABIMethodReturn(methodJSON, nil, returnLog)
+	require.NoError(t, err)
+	require.Equal(t, `[2,20]`, decoded)
+
+	decoded, err = DecodeABIMethodReturn(`{"name":"noop","args":[],"returns":{"type":"void"}}`, structs, nil)
+	require.NoError(t, err)
+	require.Equal(t, "", decoded)
+
+	_, err = DecodeABIMethodReturn(methodJSON, structs, encoded)
+	require.Error(t, err)
+}
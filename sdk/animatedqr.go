@@ -0,0 +1,389 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Payload types for animated QR codes. Any other type made of lowercase letters, digits and hyphens
+// may be used as well.
+const (
+	// QRPayloadBytes is an arbitrary byte payload.
+	QRPayloadBytes = "bytes"
+	// QRPayloadTransactions is a group of unsigned transactions, as returned by `BytesArray.Flatten()`.
+	QRPayloadTransactions = "algo-txns"
+	// QRPayloadSignedTransactions is a group of signed transactions, as returned by `BytesArray.Flatten()`.
+	QRPayloadSignedTransactions = "algo-stxns"
+	// QRPayloadMultisigExchange is a document returned by `MultisigExchange.Encode()`.
+	QRPayloadMultisigExchange = "algo-msigx"
+	// QRPayloadLogicSigAccount is a JSON document returned by `LogicSigAccount.ToJSON()`.
+	QRPayloadLogicSigAccount = "algo-lsig"
+	// QRPayloadComposerState is a document returned by `AtomicTransactionComposer.Export()`.
+	QRPayloadComposerState = "algo-atc"
+)
+
+const (
+	// animatedQRScheme is our own scheme. The frames are not BC-UR, so they must not use its "ur:"
+	// scheme, which UR-aware scanners would accept and then fail to decode.
+	animatedQRScheme = "algo-aqr:"
+	// animatedQRHeaderSize is the size of the sequence number, fragment count, payload length and
+	// payload checksum which precede the fragment data in a frame.
+	animatedQRHeaderSize = 16
+	// animatedQRChecksumSize is the size of the checksum which ends every frame.
+	animatedQRChecksumSize = 4
+	// animatedQRMaxPayloadLength and animatedQRMaxFragmentCount bound what a decoder allocates for
+	// the header of a scanned frame, which anyone can forge.
+	animatedQRMaxPayloadLength = 4 << 20
+	animatedQRMaxFragmentCount = 1 << 15
+)
+
+func validQRPayloadType(payloadType string) bool {
+	if payloadType == "" {
+		return false
+	}
+	for _, c := range payloadType {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// chooseFragments returns the indexes of the fragments which are mixed into the frame with the given
+// sequence number. The first fragmentCount frames each hold a single fragment. Later frames mix a
+// pseudo-random set of fragments, which both sides derive from the sequence number and the payload
+// checksum, so that a receiver can recover fragments it missed from any sufficient set of frames.
+func chooseFragments(seq, fragmentCount int, checksum uint32) []int {
+	if seq <= fragmentCount {
+		return []int{seq - 1}
+	}
+	rng := rand.New(rand.NewSource(int64(checksum)<<32 | int64(uint32(seq))))
+
+	// The degree is chosen with a probability proportional to 1/degree, so that most frames mix few
+	// fragments and can be peeled early
+	var total float64
+	for d := 1; d <= fragmentCount; d++ {
+		total += 1 / float64(d)
+	}
+	target := rng.Float64() * total
+	degree := fragmentCount
+	for d := 1; d <= fragmentCount; d++ {
+		target -= 1 / float64(d)
+		if target <= 0 {
+			degree = d
+			break
+		}
+	}
+	return rng.Perm(fragmentCount)[:degree]
+}
+
+// AnimatedQREncoder splits a payload into a sequence of frames, each small enough for a QR code,
+// which can be displayed in a loop as an animated QR code.
+//
+// Frames have the form "algo-aqr:<type>/<seq>-<count>/<data>". The first count frames each hold one
+// fragment of the payload, and the frames after them combine several fragments, so a receiver which
+// misses some frames does not have to wait for the loop to come around. Every frame is checksummed.
+type AnimatedQREncoder struct {
+	payloadType string
+	payload     []byte
+	checksum    uint32
+	fragments   [][]byte
+	seq         int
+}
+
+// NewAnimatedQREncoder creates an AnimatedQREncoder for a payload. payloadType tells the receiver
+// what the payload is, see the QRPayload constants. maxFragmentLength is the maximum number of
+// payload bytes in each frame.
+func NewAnimatedQREncoder(payloadType string, payload []byte, maxFragmentLength int) (*AnimatedQREncoder, error) {
+	if !validQRPayloadType(payloadType) {
+		return nil, fmt.Errorf("invalid payload type: '%s'", payloadType)
+	}
+	if len(payload) == 0 {
+		return nil, errors.New("payload is empty")
+	}
+	if maxFragmentLength <= 0 {
+		return nil, fmt.Errorf("invalid fragment length: %d", maxFragmentLength)
+	}
+	if len(payload) > animatedQRMaxPayloadLength {
+		return nil, fmt.Errorf("payload is %d bytes, more than the maximum of %d", len(payload), animatedQRMaxPayloadLength)
+	}
+
+	fragmentCount := (len(payload) + maxFragmentLength - 1) / maxFragmentLength
+	if fragmentCount > animatedQRMaxFragmentCount {
+		return nil, fmt.Errorf("payload needs %d fragments, more than the maximum of %d", fragmentCount, animatedQRMaxFragmentCount)
+	}
+	// Spread the payload evenly, so that the padding of the last fragment is as small as possible
+	fragmentLength := (len(payload) + fragmentCount - 1) / fragmentCount
+	fragments := make([][]byte, fragmentCount)
+	for i := range fragments {
+		fragments[i] = make([]byte, fragmentLength)
+		start := i * fragmentLength
+		end := start + fragmentLength
+		if end > len(payload) {
+			end = len(payload)
+		}
+		copy(fragments[i], payload[start:end])
+	}
+
+	return &AnimatedQREncoder{
+		payloadType: payloadType,
+		payload:     payload,
+		checksum:    crc32.ChecksumIEEE(payload),
+		fragments:   fragments,
+	}, nil
+}
+
+// FragmentCount returns the number of fragments the payload is split into. At least this many
+// frames are needed to reassemble the payload.
+func (e *AnimatedQREncoder) FragmentCount() int {
+	return len(e.fragments)
+}
+
+// IsSingleFrame returns true if the whole payload fits in a single frame.
+func (e *AnimatedQREncoder) IsSingleFrame() bool {
+	return len(e.fragments) == 1
+}
+
+// Frame returns the frame with the given sequence number, starting from 1. Sequence numbers beyond
+// `FragmentCount()` produce frames which combine several fragments.
+func (e *AnimatedQREncoder) Frame(seq int) (string, error) {
+	if seq < 1 {
+		return "", fmt.Errorf("invalid sequence number: %d", seq)
+	}
+	fragmentLength := len(e.fragments[0])
+	data := make([]byte, fragmentLength)
+	for _, index := range chooseFragments(seq, len(e.fragments), e.checksum) {
+		for i, b := range e.fragments[index] {
+			data[i] ^= b
+		}
+	}
+
+	body := make([]byte, animatedQRHeaderSize, animatedQRHeaderSize+fragmentLength+animatedQRChecksumSize)
+	binary.BigEndian.PutUint32(body[0:], uint32(seq))
+	binary.BigEndian.PutUint32(body[4:], uint32(len(e.fragments)))
+	binary.BigEndian.PutUint32(body[8:], uint32(len(e.payload)))
+	binary.BigEndian.PutUint32(body[12:], e.checksum)
+	body = append(body, data...)
+	body = binary.BigEndian.AppendUint32(body, crc32.ChecksumIEEE(body))
+
+	return fmt.Sprintf("%s%s/%d-%d/%s", animatedQRScheme, e.payloadType, seq, len(e.fragments), base64.RawURLEncoding.EncodeToString(body)), nil
+}
+
+// NextFrame returns the next frame to display. It can be called indefinitely.
+func (e *AnimatedQREncoder) NextFrame() string {
+	e.seq++
+	frame, _ := e.Frame(e.seq)
+	return frame
+}
+
+type animatedQRFrame struct {
+	payloadType   string
+	seq           int
+	fragmentCount int
+	payloadLength int
+	checksum      uint32
+	data          []byte
+}
+
+func parseAnimatedQRFrame(frame string) (animatedQRFrame, error) {
+	if !strings.HasPrefix(strings.ToLower(frame), animatedQRScheme) {
+		return animatedQRFrame{}, errors.New("not an animated QR frame")
+	}
+	parts := strings.Split(frame[len(animatedQRScheme):], "/")
+	if len(parts) != 3 {
+		return animatedQRFrame{}, errors.New("malformed animated QR frame")
+	}
+	payloadType := strings.ToLower(parts[0])
+	if !validQRPayloadType(payloadType) {
+		return animatedQRFrame{}, fmt.Errorf("invalid payload type: '%s'", parts[0])
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return animatedQRFrame{}, fmt.Errorf("malformed animated QR frame: %w", err)
+	}
+	if len(body) <= animatedQRHeaderSize+animatedQRChecksumSize {
+		return animatedQRFrame{}, errors.New("animated QR frame is too short")
+	}
+	checksumOffset := len(body) - animatedQRChecksumSize
+	if crc32.ChecksumIEEE(body[:checksumOffset]) != binary.BigEndian.Uint32(body[checksumOffset:]) {
+		return animatedQRFrame{}, errors.New("animated QR frame checksum does not match")
+	}
+
+	// The fragment count and payload length are bounded before they are converted to int, which
+	// is only 32 bits wide on some devices
+	fragmentCount := binary.BigEndian.Uint32(body[4:])
+	payloadLength := binary.BigEndian.Uint32(body[8:])
+	if fragmentCount > animatedQRMaxFragmentCount || payloadLength > animatedQRMaxPayloadLength {
+		return animatedQRFrame{}, errors.New("animated QR payload is too large")
+	}
+	fragmentLength := uint64(checksumOffset - animatedQRHeaderSize)
+	if fragmentCount < 1 || uint64(payloadLength) > uint64(fragmentCount)*fragmentLength || uint64(payloadLength) <= uint64(fragmentCount-1)*fragmentLength {
+		return animatedQRFrame{}, errors.New("invalid animated QR payload length")
+	}
+
+	parsed := animatedQRFrame{
+		payloadType:   payloadType,
+		seq:           int(binary.BigEndian.Uint32(body[0:])),
+		fragmentCount: int(fragmentCount),
+		payloadLength: int(payloadLength),
+		checksum:      binary.BigEndian.Uint32(body[12:]),
+		data:          body[animatedQRHeaderSize:checksumOffset],
+	}
+	if parsed.seq < 1 {
+		return animatedQRFrame{}, errors.New("invalid animated QR frame sequence")
+	}
+	if parts[1] != strconv.Itoa(parsed.seq)+"-"+strconv.Itoa(parsed.fragmentCount) {
+		return animatedQRFrame{}, errors.New("animated QR frame sequence does not match its data")
+	}
+	return parsed, nil
+}
+
+// mixedFragments is a received frame which combines fragments that are not known yet.
+type mixedFragments struct {
+	indexes map[int]bool
+	data    []byte
+}
+
+// AnimatedQRDecoder reassembles a payload from the frames of an animated QR code, as created by
+// AnimatedQREncoder. Frames may be received in any order, and duplicates are ignored.
+type AnimatedQRDecoder struct {
+	first     *animatedQRFrame
+	fragments [][]byte
+	solved    int
+	pending   []*mixedFragments
+	payload   []byte
+}
+
+// NewAnimatedQRDecoder creates an empty AnimatedQRDecoder.
+func NewAnimatedQRDecoder() *AnimatedQRDecoder {
+	return &AnimatedQRDecoder{}
+}
+
+// ReceiveFrame adds a scanned frame. An error is returned if the frame is malformed, fails its
+// checksum, or belongs to a different payload than the frames received before. If the reassembled
+// payload fails its checksum, an error is returned and the decoder is reset.
+func (d *AnimatedQRDecoder) ReceiveFrame(frame string) error {
+	parsed, err := parseAnimatedQRFrame(frame)
+	if err != nil {
+		return err
+	}
+	if d.first == nil {
+		d.first = &parsed
+		d.fragments = make([][]byte, parsed.fragmentCount)
+	} else if parsed.payloadType != d.first.payloadType || parsed.fragmentCount != d.first.fragmentCount ||
+		parsed.payloadLength != d.first.payloadLength || parsed.checksum != d.first.checksum || len(parsed.data) != len(d.first.data) {
+		return errors.New("frame belongs to a different payload")
+	}
+	if d.IsComplete() {
+		return nil
+	}
+
+	part := &mixedFragments{indexes: make(map[int]bool), data: append([]byte(nil), parsed.data...)}
+	for _, index := range chooseFragments(parsed.seq, parsed.fragmentCount, parsed.checksum) {
+		part.indexes[index] = true
+	}
+	d.addPart(part)
+
+	if d.solved == len(d.fragments) {
+		var payload []byte
+		for _, fragment := range d.fragments {
+			payload = append(payload, fragment...)
+		}
+		payload = payload[:d.first.payloadLength]
+		if crc32.ChecksumIEEE(payload) != d.first.checksum {
+			// The frames were forged or corrupted in a way their checksums did not catch, so start
+			// over rather than keep the fragments
+			d.Reset()
+			return errors.New("checksum of the reassembled payload does not match")
+		}
+		d.payload = payload
+	}
+	return nil
+}
+
+// Reset discards every frame received so far, so that the decoder can receive another payload.
+func (d *AnimatedQRDecoder) Reset() {
+	*d = AnimatedQRDecoder{}
+}
+
+// reduce removes the known fragments from a mixed part.
+func (d *AnimatedQRDecoder) reduce(part *mixedFragments) {
+	for index := range part.indexes {
+		if d.fragments[index] != nil {
+			for i, b := range d.fragments[index] {
+				part.data[i] ^= b
+			}
+			delete(part.indexes, index)
+		}
+	}
+}
+
+// addPart records a part, and peels the pending parts for as long as new fragments become known.
+func (d *AnimatedQRDecoder) addPart(part *mixedFragments) {
+	queue := []*mixedFragments{part}
+	for len(queue) > 0 {
+		part, queue = queue[0], queue[1:]
+		d.reduce(part)
+		switch len(part.indexes) {
+		case 0:
+			continue
+		case 1:
+			for index := range part.indexes {
+				d.fragments[index] = part.data
+				d.solved++
+			}
+			// Every pending part may now be reducible
+			queue = append(queue, d.pending...)
+			d.pending = nil
+		default:
+			d.pending = append(d.pending, part)
+		}
+	}
+}
+
+// IsComplete returns true once the payload has been reassembled.
+func (d *AnimatedQRDecoder) IsComplete() bool {
+	return d.payload != nil
+}
+
+// Progress returns the fraction of the payload fragments which are known, between 0 and 1.
+func (d *AnimatedQRDecoder) Progress() float64 {
+	if len(d.fragments) == 0 {
+		return 0
+	}
+	return float64(d.solved) / float64(len(d.fragments))
+}
+
+// ExpectedFragmentCount returns the number of fragments of the payload, or 0 if no frame has been
+// received yet.
+func (d *AnimatedQRDecoder) ExpectedFragmentCount() int {
+	return len(d.fragments)
+}
+
+// ReceivedFragmentCount returns the number of payload fragments which are known.
+func (d *AnimatedQRDecoder) ReceivedFragmentCount() int {
+	return d.solved
+}
+
+// PayloadType returns the type of the payload, or an empty string if no frame has been received yet.
+func (d *AnimatedQRDecoder) PayloadType() string {
+	if d.first == nil {
+		return ""
+	}
+	return d.first.payloadType
+}
+
+// Payload returns the reassembled payload. An error is returned if the payload is not complete.
+func (d *AnimatedQRDecoder) Payload() ([]byte, error) {
+	if !d.IsComplete() {
+		return nil, fmt.Errorf("payload is incomplete: %d of %d fragments received", d.solved, len(d.fragments))
+	}
+	return d.payload, nil
+}
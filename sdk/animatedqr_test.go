@@ -0,0 +1,160 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// makeTestAnimatedQRFrame forges a frame with the given header, and a valid frame checksum.
+func makeTestAnimatedQRFrame(seq, fragmentCount, payloadLength, checksum uint32, data []byte) string {
+	body := make([]byte, animatedQRHeaderSize)
+	binary.BigEndian.PutUint32(body[0:], seq)
+	binary.BigEndian.PutUint32(body[4:], fragmentCount)
+	binary.BigEndian.PutUint32(body[8:], payloadLength)
+	binary.BigEndian.PutUint32(body[12:], checksum)
+	body = append(body, data...)
+	body = binary.BigEndian.AppendUint32(body, crc32.ChecksumIEEE(body))
+	return fmt.Sprintf("%s%s/%d-%d/%s", animatedQRScheme, QRPayloadBytes, seq, fragmentCount, base64.RawURLEncoding.EncodeToString(body))
+}
+
+func TestAnimatedQRRoundTrip(t *testing.T) {
+	t.Parallel()
+	payload := make([]byte, 1000)
+	randomBytes(payload)
+
+	encoder, err := NewAnimatedQREncoder(QRPayloadSignedTransactions, payload, 120)
+	require.NoError(t, err)
+	require.Equal(t, 9, encoder.FragmentCount())
+	require.False(t, encoder.IsSingleFrame())
+
+	frame := encoder.NextFrame()
+	require.True(t, strings.HasPrefix(frame, "algo-aqr:algo-stxns/1-9/"))
+
+	// receive the frames in order
+	decoder := NewAnimatedQRDecoder()
+	require.Equal(t, 0.0, decoder.Progress())
+	require.NoError(t, decoder.ReceiveFrame(frame))
+	require.Equal(t, QRPayloadSignedTransactions, decoder.PayloadType())
+	require.Equal(t, 9, decoder.ExpectedFragmentCount())
+	require.Equal(t, 1, decoder.ReceivedFragmentCount())
+	_, err = decoder.Payload()
+	require.Error(t, err)
+	for !decoder.IsComplete() {
+		require.NoError(t, decoder.ReceiveFrame(encoder.NextFrame()))
+	}
+	require.Equal(t, 1.0, decoder.Progress())
+	decoded, err := decoder.Payload()
+	require.NoError(t, err)
+	require.Equal(t, payload, decoded)
+}
+
+func TestAnimatedQRMissedFrames(t *testing.T) {
+	t.Parallel()
+	payload := bytes.Repeat([]byte("algorand"), 100)
+	encoder, err := NewAnimatedQREncoder(QRPayloadBytes, payload, 50)
+	require.NoError(t, err)
+
+	// every third frame is missed, and the rest arrive out of order, so some fragments can only be
+	// recovered from the mixed frames
+	var frames []string
+	for seq := 1; seq <= 200; seq++ {
+		if seq%3 == 0 {
+			continue
+		}
+		frame, err := encoder.Frame(seq)
+		require.NoError(t, err)
+		frames = append(frames, frame)
+	}
+	decoder := NewAnimatedQRDecoder()
+	for i := len(frames) - 1; i >= 0 && !decoder.IsComplete(); i-- {
+		require.NoError(t, decoder.ReceiveFrame(frames[i]))
+	}
+	require.True(t, decoder.IsComplete())
+	decoded, err := decoder.Payload()
+	require.NoError(t, err)
+	require.Equal(t, payload, decoded)
+}
+
+func TestAnimatedQRInvalidFrames(t *testing.T) {
+	t.Parallel()
+	encoder, err := NewAnimatedQREncoder(QRPayloadLogicSigAccount, []byte(`{"lsig":{}}`), 100)
+	require.NoError(t, err)
+	require.True(t, encoder.IsSingleFrame())
+	frame := encoder.NextFrame()
+
+	decoder := NewAnimatedQRDecoder()
+	require.Error(t, decoder.ReceiveFrame("not a frame"))
+	require.Error(t, decoder.ReceiveFrame(strings.Replace(frame, "/1-1/", "/2-1/", 1)))
+
+	// flipping a character of the data fails the checksum
+	corrupted := []byte(frame)
+	last := len(corrupted) - 5
+	if corrupted[last] == 'A' {
+		corrupted[last] = 'B'
+	} else {
+		corrupted[last] = 'A'
+	}
+	require.Error(t, decoder.ReceiveFrame(string(corrupted)))
+
+	require.NoError(t, decoder.ReceiveFrame(frame))
+	require.True(t, decoder.IsComplete())
+
+	other, err := NewAnimatedQREncoder(QRPayloadBytes, []byte("other"), 100)
+	require.NoError(t, err)
+	require.Error(t, decoder.ReceiveFrame(other.NextFrame()))
+
+	_, err = NewAnimatedQREncoder("Not Valid", []byte{1}, 10)
+	require.Error(t, err)
+	_, err = NewAnimatedQREncoder(QRPayloadBytes, nil, 10)
+	require.Error(t, err)
+}
+
+func TestAnimatedQRForgedHeader(t *testing.T) {
+	t.Parallel()
+	decoder := NewAnimatedQRDecoder()
+
+	// a huge fragment count is rejected before the decoder allocates anything
+	err := decoder.ReceiveFrame(makeTestAnimatedQRFrame(1, 1<<32-1, 1<<22, 0, []byte{1}))
+	require.ErrorContains(t, err, "too large")
+	err = decoder.ReceiveFrame(makeTestAnimatedQRFrame(1, 1, 1<<32-1, 0, []byte{1}))
+	require.ErrorContains(t, err, "too large")
+	err = decoder.ReceiveFrame(makeTestAnimatedQRFrame(1, 1<<15, 1<<22, 0, []byte{1}))
+	require.ErrorContains(t, err, "invalid animated QR payload length")
+	require.Equal(t, 0, decoder.ExpectedFragmentCount())
+
+	_, err = NewAnimatedQREncoder(QRPayloadBytes, make([]byte, animatedQRMaxPayloadLength+1), 1000)
+	require.Error(t, err)
+	_, err = NewAnimatedQREncoder(QRPayloadBytes, make([]byte, animatedQRMaxFragmentCount+1), 1)
+	require.Error(t, err)
+}
+
+func TestAnimatedQRPayloadChecksumMismatch(t *testing.T) {
+	t.Parallel()
+	decoder := NewAnimatedQRDecoder()
+
+	// the frame itself is valid, but the payload does not match the payload checksum
+	err := decoder.ReceiveFrame(makeTestAnimatedQRFrame(1, 1, 3, 12345, []byte("abc")))
+	require.ErrorContains(t, err, "checksum of the reassembled payload does not match")
+	require.False(t, decoder.IsComplete())
+	require.Equal(t, 0.0, decoder.Progress())
+	require.Equal(t, 0, decoder.ExpectedFragmentCount())
+	require.Equal(t, "", decoder.PayloadType())
+
+	// the decoder is not stuck, and receives the correct payload afterwards
+	encoder, err := NewAnimatedQREncoder(QRPayloadBytes, []byte("abc"), 10)
+	require.NoError(t, err)
+	require.NoError(t, decoder.ReceiveFrame(encoder.NextFrame()))
+	payload, err := decoder.Payload()
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), payload)
+
+	decoder.Reset()
+	require.False(t, decoder.IsComplete())
+}
@@ -0,0 +1,431 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+)
+
+// AVM value types which may be used in place of an ABI type in an AppStateSchema, as defined by ARC-56.
+const (
+	avmBytesType  = "AVMBytes"
+	avmStringType = "AVMString"
+	avmUint64Type = "AVMUint64"
+)
+
+// TEAL value types as reported by algod in TealValue.Type
+const (
+	tealBytesType = 1
+	tealUintType  = 2
+)
+
+// stateValueType is either an AVM type, an ABI type, or an ARC-56 struct used to decode a piece of
+// application state. Structs are decoded as their ABI tuple type, then given their field names.
+type stateValueType struct {
+	avmType    string
+	abiType    *ABIType
+	structName string
+	structs    *ABIStructDefinitions
+}
+
+func parseStateValueType(typeString string, structs *ABIStructDefinitions) (stateValueType, error) {
+	switch typeString {
+	case avmBytesType, avmStringType, avmUint64Type:
+		return stateValueType{avmType: typeString}, nil
+	}
+	if structs != nil && structs.HasStruct(typeString) {
+		abiType, err := structs.ABIType(typeString)
+		if err != nil {
+			return stateValueType{}, fmt.Errorf("could not parse state type '%s': %w", typeString, err)
+		}
+		return stateValueType{abiType: abiType, structName: typeString, structs: structs}, nil
+	}
+	abiType, err := ParseABIType(typeString)
+	if err != nil {
+		return stateValueType{}, fmt.Errorf("could not parse state type '%s': %w", typeString, err)
+	}
+	return stateValueType{abiType: abiType}, nil
+}
+
+// decodeBytes decodes a byte slice value into its JSON representation.
+func (t stateValueType) decodeBytes(value []byte) (json.RawMessage, error) {
+	switch t.avmType {
+	case avmBytesType:
+		return json.Marshal(value)
+	case avmStringType:
+		return json.Marshal(string(value))
+	case avmUint64Type:
+		if len(value) > 8 {
+			return nil, fmt.Errorf("cannot decode %d bytes as %s", len(value), avmUint64Type)
+		}
+		padded := make([]byte, 8)
+		copy(padded[8-len(value):], value)
+		return json.Marshal(binary.BigEndian.Uint64(padded))
+	}
+	decoded, err := t.abiType.Decode(value)
+	if err != nil {
+		return nil, err
+	}
+	if t.structName != "" {
+		decoded, err = t.structs.TupleToStructJSON(t.structName, decoded)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.RawMessage(decoded), nil
+}
+
+// decodeUint decodes a uint TEAL value into its JSON representation.
+func (t stateValueType) decodeUint(value uint64) (json.RawMessage, error) {
+	if t.avmType == avmUint64Type {
+		return json.Marshal(value)
+	}
+	if t.avmType != "" {
+		return nil, fmt.Errorf("cannot decode a uint value as %s", t.avmType)
+	}
+	// A bool is stored as the uint 0 or 1 in TEAL, but ABI encodes true as 0x80
+	if t.abiType.String() == "bool" {
+		if value > 1 {
+			return nil, fmt.Errorf("uint value %d is not a bool, which must be 0 or 1", value)
+		}
+		return json.Marshal(value == 1)
+	}
+	encoded := EncodeUIntAsBytes(value)
+	// Narrower ABI types such as uint32 are stored as a uint in TEAL
+	if byteLen, err := t.abiType.value.ByteLen(); err == nil && byteLen < len(encoded) {
+		trimmed := len(encoded) - byteLen
+		if !bytes.Equal(encoded[:trimmed], make([]byte, trimmed)) {
+			return nil, fmt.Errorf("uint value %d overflows type %s", value, t.abiType.String())
+		}
+		encoded = encoded[trimmed:]
+	}
+	return t.decodeBytes(encoded)
+}
+
+type stateKeySpec struct {
+	name      string
+	key       []byte
+	valueType stateValueType
+}
+
+type stateMapSpec struct {
+	name      string
+	prefix    []byte
+	keyType   stateValueType
+	valueType stateValueType
+}
+
+// AppStateSchema describes how to decode the raw keys and values of an application's global state,
+// local state, or boxes.
+//
+// A schema consists of single keys, which map an exact state key to a name, and maps, which match
+// every state key beginning with a prefix. The remainder of a map key after its prefix is decoded
+// with the map's key type.
+type AppStateSchema struct {
+	keys    []stateKeySpec
+	maps    []stateMapSpec
+	structs *ABIStructDefinitions
+}
+
+// NewAppStateSchema creates an empty AppStateSchema. Use AddKey and AddMap to describe the state.
+func NewAppStateSchema() *AppStateSchema {
+	return &AppStateSchema{}
+}
+
+// SetStructs sets the ARC-56 structs which the types of keys and maps added afterwards may name.
+// Values of a struct type are decoded into JSON objects with named fields.
+func (s *AppStateSchema) SetStructs(structs *ABIStructDefinitions) {
+	s.structs = structs
+}
+
+// AddKey adds a single state key to the schema.
+//
+// valueType is either an ABI type string, such as "uint64" or "(address,uint64)", one of the
+// ARC-56 AVM types "AVMBytes", "AVMString", or "AVMUint64", or the name of a struct set with
+// SetStructs.
+func (s *AppStateSchema) AddKey(name string, key []byte, valueType string) error {
+	parsedValueType, err := parseStateValueType(valueType, s.structs)
+	if err != nil {
+		return err
+	}
+	s.keys = append(s.keys, stateKeySpec{
+		name:      name,
+		key:       append([]byte{}, key...),
+		valueType: parsedValueType,
+	})
+	return nil
+}
+
+// AddMap adds a map to the schema. Every state key which begins with prefix belongs to this map.
+//
+// keyType and valueType are either ABI type strings, ARC-56 AVM types or struct names, see AddKey.
+func (s *AppStateSchema) AddMap(name string, prefix []byte, keyType, valueType string) error {
+	parsedKeyType, err := parseStateValueType(keyType, s.structs)
+	if err != nil {
+		return err
+	}
+	parsedValueType, err := parseStateValueType(valueType, s.structs)
+	if err != nil {
+		return err
+	}
+	s.maps = append(s.maps, stateMapSpec{
+		name:      name,
+		prefix:    append([]byte{}, prefix...),
+		keyType:   parsedKeyType,
+		valueType: parsedValueType,
+	})
+	// Match longer prefixes first so that a map with an empty prefix does not shadow the others
+	sort.SliceStable(s.maps, func(i, j int) bool {
+		return len(s.maps[i].prefix) > len(s.maps[j].prefix)
+	})
+	return nil
+}
+
+type arc56StorageKey struct {
+	KeyType   string `json:"keyType"`
+	ValueType string `json:"valueType"`
+	Key       []byte `json:"key"`
+}
+
+type arc56StorageMap struct {
+	KeyType   string `json:"keyType"`
+	ValueType string `json:"valueType"`
+	Prefix    []byte `json:"prefix"`
+}
+
+type arc56State struct {
+	Keys struct {
+		Global map[string]arc56StorageKey `json:"global"`
+		Local  map[string]arc56StorageKey `json:"local"`
+		Box    map[string]arc56StorageKey `json:"box"`
+	} `json:"keys"`
+	Maps struct {
+		Global map[string]arc56StorageMap `json:"global"`
+		Local  map[string]arc56StorageMap `json:"local"`
+		Box    map[string]arc56StorageMap `json:"box"`
+	} `json:"maps"`
+}
+
+// ParseARC56StateSchema creates an AppStateSchema from the "state" section of an ARC-56 app spec.
+// Key and value types which name a struct of the "structs" section are decoded as that struct.
+//
+// storage selects which state the schema describes. The accepted values are "global", "local" and
+// "box".
+func ParseARC56StateSchema(arc56JSON string, storage string) (*AppStateSchema, error) {
+	var spec struct {
+		State arc56State `json:"state"`
+	}
+	err := json.Unmarshal([]byte(arc56JSON), &spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ARC-56 app spec: %w", err)
+	}
+
+	var keys map[string]arc56StorageKey
+	var maps map[string]arc56StorageMap
+	switch storage {
+	case "global":
+		keys, maps = spec.State.Keys.Global, spec.State.Maps.Global
+	case "local":
+		keys, maps = spec.State.Keys.Local, spec.State.Maps.Local
+	case "box":
+		keys, maps = spec.State.Keys.Box, spec.State.Maps.Box
+	default:
+		return nil, fmt.Errorf("invalid storage type: '%s'", storage)
+	}
+
+	keyNames := make([]string, 0, len(keys))
+	for name := range keys {
+		keyNames = append(keyNames, name)
+	}
+	sort.Strings(keyNames)
+	mapNames := make([]string, 0, len(maps))
+	for name := range maps {
+		mapNames = append(mapNames, name)
+	}
+	sort.Strings(mapNames)
+
+	structs, err := ParseARC56Structs(arc56JSON)
+	if err != nil {
+		return nil, err
+	}
+	schema := NewAppStateSchema()
+	schema.SetStructs(structs)
+	for _, name := range keyNames {
+		key := keys[name]
+		err = schema.AddKey(name, key.Key, key.ValueType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key '%s': %w", name, err)
+		}
+	}
+	for _, name := range mapNames {
+		m := maps[name]
+		err = schema.AddMap(name, m.Prefix, m.KeyType, m.ValueType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid map '%s': %w", name, err)
+		}
+	}
+	return schema, nil
+}
+
+type stateMapEntry struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// stateDecoder accumulates decoded state values keyed by name.
+type stateDecoder struct {
+	schema  *AppStateSchema
+	keys    map[string]json.RawMessage
+	entries map[string][]stateMapEntry
+}
+
+func (s *AppStateSchema) newDecoder() *stateDecoder {
+	return &stateDecoder{
+		schema:  s,
+		keys:    make(map[string]json.RawMessage),
+		entries: make(map[string][]stateMapEntry),
+	}
+}
+
+// add decodes a single key and value. decodeValue is called with the value type matching the key.
+// Keys which are not described by the schema are ignored.
+func (d *stateDecoder) add(key []byte, decodeValue func(stateValueType) (json.RawMessage, error)) error {
+	for _, k := range d.schema.keys {
+		if bytes.Equal(k.key, key) {
+			value, err := decodeValue(k.valueType)
+			if err != nil {
+				return fmt.Errorf("could not decode value of '%s': %w", k.name, err)
+			}
+			d.keys[k.name] = value
+			return nil
+		}
+	}
+	for _, m := range d.schema.maps {
+		if !bytes.HasPrefix(key, m.prefix) {
+			continue
+		}
+		decodedKey, err := m.keyType.decodeBytes(key[len(m.prefix):])
+		if err != nil {
+			// The key may belong to a map with a shorter prefix
+			continue
+		}
+		value, err := decodeValue(m.valueType)
+		if err != nil {
+			return fmt.Errorf("could not decode value of '%s' entry %s: %w", m.name, decodedKey, err)
+		}
+		d.entries[m.name] = append(d.entries[m.name], stateMapEntry{Key: decodedKey, Value: value})
+		return nil
+	}
+	return nil
+}
+
+func (d *stateDecoder) result() (string, error) {
+	output := make(map[string]json.RawMessage, len(d.keys)+len(d.entries))
+	for name, value := range d.keys {
+		output[name] = value
+	}
+	for name, entries := range d.entries {
+		encoded, err := json.Marshal(entries)
+		if err != nil {
+			return "", err
+		}
+		output[name] = encoded
+	}
+	encoded, err := json.Marshal(output)
+	return string(encoded), err
+}
+
+// DecodeKeyValues decodes application global or local state, as returned by algod, using this schema.
+//
+// tealKeyValueJSON is a JSON array of TEAL key-value objects, for example the "global-state" field
+// of an application or the "key-value" field of an account's application local state:
+//
+//	[{"key":"<base64>","value":{"type":1,"bytes":"<base64>","uint":0}}]
+//
+// The result is a JSON object keyed by the names in the schema. Single keys map directly to their
+// decoded value, and maps become an array of {"key":<decoded key>,"value":<decoded value>} objects.
+// State keys which are not described by the schema are omitted.
+func (s *AppStateSchema) DecodeKeyValues(tealKeyValueJSON string) (string, error) {
+	var kvs []models.TealKeyValue
+	err := json.Unmarshal([]byte(tealKeyValueJSON), &kvs)
+	if err != nil {
+		return "", fmt.Errorf("could not decode TEAL key-value array: %w", err)
+	}
+
+	decoder := s.newDecoder()
+	for _, kv := range kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return "", fmt.Errorf("could not decode state key '%s': %w", kv.Key, err)
+		}
+		tealValue := kv.Value
+		err = decoder.add(key, func(t stateValueType) (json.RawMessage, error) {
+			switch tealValue.Type {
+			case tealBytesType:
+				value, err := base64.StdEncoding.DecodeString(tealValue.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				return t.decodeBytes(value)
+			case tealUintType:
+				return t.decodeUint(tealValue.Uint)
+			default:
+				return nil, fmt.Errorf("unknown TEAL value type: %d", tealValue.Type)
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return decoder.result()
+}
+
+// DecodeBoxes decodes application boxes using this schema.
+//
+// boxesJSON is a JSON array of box objects as returned by algod for individual boxes:
+//
+//	[{"name":"<base64>","value":"<base64>"}]
+//
+// The result has the same format as DecodeKeyValues. Boxes which are not described by the schema
+// are omitted.
+func (s *AppStateSchema) DecodeBoxes(boxesJSON string) (string, error) {
+	var boxes []models.Box
+	err := json.Unmarshal([]byte(boxesJSON), &boxes)
+	if err != nil {
+		return "", fmt.Errorf("could not decode box array: %w", err)
+	}
+
+	decoder := s.newDecoder()
+	for _, box := range boxes {
+		value := box.Value
+		err = decoder.add(box.Name, func(t stateValueType) (json.RawMessage, error) {
+			return t.decodeBytes(value)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return decoder.result()
+}
+
+// DecodeBox decodes a single application box using this schema. The result has the same format as
+// DecodeKeyValues, and an error is returned if the box is not described by the schema.
+func (s *AppStateSchema) DecodeBox(name, value []byte) (string, error) {
+	decoder := s.newDecoder()
+	err := decoder.add(name, func(t stateValueType) (json.RawMessage, error) {
+		return t.decodeBytes(value)
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(decoder.keys) == 0 && len(decoder.entries) == 0 {
+		return "", errors.New("box name does not match any key or map in the schema")
+	}
+	return decoder.result()
+}
@@ -0,0 +1,197 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testARC56StateSpec = `{
+	"name": "Vault",
+	"state": {
+		"keys": {
+			"global": {
+				"owner": {"keyType": "AVMString", "valueType": "address", "key": "b3duZXI="},
+				"total": {"keyType": "AVMString", "valueType": "uint64", "key": "dG90YWw="},
+				"label": {"keyType": "AVMString", "valueType": "AVMString", "key": "bGFiZWw="}
+			},
+			"local": {},
+			"box": {
+				"config": {"keyType": "AVMString", "valueType": "(uint64,bool)", "key": "Y29uZmln"}
+			}
+		},
+		"maps": {
+			"global": {},
+			"local": {},
+			"box": {
+				"balances": {"keyType": "address", "valueType": "uint64", "prefix": "Yg=="},
+				"names": {"keyType": "uint64", "valueType": "string", "prefix": "bg=="}
+			}
+		}
+	}
+}`
+
+func TestDecodeKeyValues(t *testing.T) {
+	t.Parallel()
+	schema, err := ParseARC56StateSchema(testARC56StateSpec, "global")
+	require.NoError(t, err)
+
+	owner := mustDecodeAddress(t, "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA")
+	globalState := `[
+		{"key":"b3duZXI=","value":{"type":1,"bytes":"` + base64.StdEncoding.EncodeToString(owner[:]) + `","uint":0}},
+		{"key":"dG90YWw=","value":{"type":2,"bytes":"","uint":1000000}},
+		{"key":"bGFiZWw=","value":{"type":1,"bytes":"bXkgdmF1bHQ=","uint":0}},
+		{"key":"dW5rbm93bg==","value":{"type":2,"bytes":"","uint":5}}
+	]`
+
+	decoded, err := schema.DecodeKeyValues(globalState)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"owner": "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA",
+		"total": 1000000,
+		"label": "my vault"
+	}`, decoded)
+
+	_, err = schema.DecodeKeyValues(`[{"key":"dG90YWw=","value":{"type":1,"bytes":"AQ==","uint":0}}]`)
+	require.Error(t, err)
+
+	_, err = ParseARC56StateSchema(testARC56StateSpec, "other")
+	require.Error(t, err)
+}
+
+func TestDecodeBoxes(t *testing.T) {
+	t.Parallel()
+	schema, err := ParseARC56StateSchema(testARC56StateSpec, "box")
+	require.NoError(t, err)
+
+	holder := mustDecodeAddress(t, "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA")
+	balanceBoxName := append([]byte("b"), holder[:]...)
+	namesBoxName := append([]byte("n"), EncodeUIntAsBytes(7)...)
+
+	configType, err := ParseABIType("(uint64,bool)")
+	require.NoError(t, err)
+	configValue, err := configType.Encode(`[42,true]`)
+	require.NoError(t, err)
+	nameType, err := ParseABIType("string")
+	require.NoError(t, err)
+	nameValue, err := nameType.Encode(`"seven"`)
+	require.NoError(t, err)
+
+	boxes := `[
+		{"name":"Y29uZmln","value":"` + base64.StdEncoding.EncodeToString(configValue) + `"},
+		{"name":"` + base64.StdEncoding.EncodeToString(balanceBoxName) + `","value":"` + base64.StdEncoding.EncodeToString(EncodeUIntAsBytes(250)) + `"},
+		{"name":"` + base64.StdEncoding.EncodeToString(namesBoxName) + `","value":"` + base64.StdEncoding.EncodeToString(nameValue) + `"}
+	]`
+
+	decoded, err := schema.DecodeBoxes(boxes)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"config": [42, true],
+		"balances": [{"key": "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA", "value": 250}],
+		"names": [{"key": 7, "value": "seven"}]
+	}`, decoded)
+
+	decoded, err = schema.DecodeBox(balanceBoxName, EncodeUIntAsBytes(1))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"balances": [{"key": "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA", "value": 1}]}`, decoded)
+
+	_, err = schema.DecodeBox([]byte("missing"), []byte{1})
+	require.Error(t, err)
+}
+
+func TestAppStateSchemaExplicitTypes(t *testing.T) {
+	t.Parallel()
+	schema := NewAppStateSchema()
+	require.NoError(t, schema.AddKey("counter", []byte("c"), "AVMUint64"))
+	require.NoError(t, schema.AddKey("data", []byte("d"), "AVMBytes"))
+	require.NoError(t, schema.AddMap("votes", []byte("v_"), "AVMString", "uint32"))
+	require.NoError(t, schema.AddKey("open", []byte("o"), "bool"))
+	require.Error(t, schema.AddKey("bad", []byte("x"), "notatype"))
+
+	localState := `[
+		{"key":"Yw==","value":{"type":2,"bytes":"","uint":3}},
+		{"key":"ZA==","value":{"type":1,"bytes":"AQID","uint":0}},
+		{"key":"dl95ZXM=","value":{"type":2,"bytes":"","uint":12}},
+		{"key":"bw==","value":{"type":2,"bytes":"","uint":1}}
+	]`
+	decoded, err := schema.DecodeKeyValues(localState)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"counter": 3,
+		"data": "AQID",
+		"votes": [{"key": "yes", "value": 12}],
+		"open": true
+	}`, decoded)
+
+	// a bool stored as a uint is 0 or 1
+	decoded, err = schema.DecodeKeyValues(`[{"key":"bw==","value":{"type":2,"bytes":"","uint":0}}]`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"open": false}`, decoded)
+	_, err = schema.DecodeKeyValues(`[{"key":"bw==","value":{"type":2,"bytes":"","uint":2}}]`)
+	require.ErrorContains(t, err, "must be 0 or 1")
+}
+
+const testARC56StructStateSpec = `{
+	"name": "Lending",
+	"structs": {
+		"Position": [
+			{"name": "collateral", "type": "uint64"},
+			{"name": "open", "type": "bool"}
+		],
+		"PositionKey": [
+			{"name": "owner", "type": "address"},
+			{"name": "id", "type": "uint16"}
+		]
+	},
+	"state": {
+		"keys": {
+			"global": {
+				"defaults": {"keyType": "AVMString", "valueType": "Position", "key": "ZGVmYXVsdHM="}
+			},
+			"local": {},
+			"box": {}
+		},
+		"maps": {
+			"global": {},
+			"local": {},
+			"box": {
+				"positions": {"keyType": "PositionKey", "valueType": "Position", "prefix": "cA=="}
+			}
+		}
+	}
+}`
+
+func TestDecodeStructState(t *testing.T) {
+	t.Parallel()
+	structs, err := ParseARC56Structs(testARC56StructStateSpec)
+	require.NoError(t, err)
+	position, err := structs.Encode("Position", `{"collateral": 500, "open": true}`)
+	require.NoError(t, err)
+	owner := mustDecodeAddress(t, "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA")
+	positionKey, err := structs.Encode("PositionKey", `{"owner": "`+owner.String()+`", "id": 3}`)
+	require.NoError(t, err)
+
+	schema, err := ParseARC56StateSchema(testARC56StructStateSpec, "global")
+	require.NoError(t, err)
+	decoded, err := schema.DecodeKeyValues(`[
+		{"key":"ZGVmYXVsdHM=","value":{"type":1,"bytes":"` + base64.StdEncoding.EncodeToString(position) + `","uint":0}}
+	]`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"defaults": {"collateral": 500, "open": true}}`, decoded)
+
+	schema, err = ParseARC56StateSchema(testARC56StructStateSpec, "box")
+	require.NoError(t, err)
+	decoded, err = schema.DecodeBox(append([]byte("p"), positionKey...), position)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"positions": [{
+		"key": {"owner": "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA", "id": 3},
+		"value": {"collateral": 500, "open": true}
+	}]}`, decoded)
+
+	// struct names only resolve once the structs are set
+	schema = NewAppStateSchema()
+	require.Error(t, schema.AddKey("defaults", []byte("defaults"), "Position"))
+	schema.SetStructs(structs)
+	require.NoError(t, schema.AddKey("defaults", []byte("defaults"), "Position"))
+}
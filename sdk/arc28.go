@@ -0,0 +1,254 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+)
+
+type abiEventArg struct {
+	name    string
+	abiType abi.Type
+}
+
+// ABIEvent represents an ARC-28 event which an application may emit in its logs.
+type ABIEvent struct {
+	name string
+	args []abiEventArg
+}
+
+// ABIEventFromSignature parses an event signature, such as "Transfer(address,address,uint64)",
+// into an ABIEvent. The arguments of the event will not have names.
+func ABIEventFromSignature(signature string) (*ABIEvent, error) {
+	// An event signature has the same format as a method signature without the return type
+	method, err := abi.MethodFromSignature(signature + abi.VoidReturnType)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse event signature '%s': %w", signature, err)
+	}
+	args := make([]abiEventArg, len(method.Args))
+	for i, arg := range method.Args {
+		if arg.IsTransactionArg() || arg.IsReferenceArg() {
+			return nil, fmt.Errorf("event argument type not allowed: '%s'", arg.Type)
+		}
+		args[i].abiType, err = arg.GetTypeObject()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ABIEvent{name: method.Name, args: args}, nil
+}
+
+type arc28EventSpec struct {
+	Name string `json:"name"`
+	Args []struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"args"`
+}
+
+func (spec arc28EventSpec) toEvent() (*ABIEvent, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("event has no name")
+	}
+	args := make([]abiEventArg, len(spec.Args))
+	for i, arg := range spec.Args {
+		abiType, err := abi.TypeOf(arg.Type)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse type of argument %d of event '%s': %w", i, spec.Name, err)
+		}
+		args[i] = abiEventArg{name: arg.Name, abiType: abiType}
+	}
+	return &ABIEvent{name: spec.Name, args: args}, nil
+}
+
+// ABIEventFromJSON parses the JSON description of an event, as found in ARC-28 and ARC-56 app specs:
+//
+//	{"name":"Transfer","args":[{"type":"address","name":"from"},{"type":"address","name":"to"},{"type":"uint64","name":"amount"}]}
+func ABIEventFromJSON(eventJSON string) (*ABIEvent, error) {
+	var spec arc28EventSpec
+	err := json.Unmarshal([]byte(eventJSON), &spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event from JSON: %w", err)
+	}
+	return spec.toEvent()
+}
+
+// Name returns the name of the event.
+func (e *ABIEvent) Name() string {
+	return e.name
+}
+
+// Signature returns the signature of the event, for example "Transfer(address,address,uint64)".
+func (e *ABIEvent) Signature() string {
+	argTypes := make([]string, len(e.args))
+	for i, arg := range e.args {
+		argTypes[i] = arg.abiType.String()
+	}
+	return e.name + "(" + strings.Join(argTypes, ",") + ")"
+}
+
+// Selector returns the 4-byte selector which prefixes every log entry of this event.
+func (e *ABIEvent) Selector() []byte {
+	hash := sha512.Sum512_256([]byte(e.Signature()))
+	return hash[:4]
+}
+
+func (e *ABIEvent) argName(index int) string {
+	if e.args[index].name != "" {
+		return e.args[index].name
+	}
+	return fmt.Sprintf("arg%d", index)
+}
+
+type decodedABIEvent struct {
+	Name      string                     `json:"name"`
+	Signature string                     `json:"signature"`
+	Args      map[string]json.RawMessage `json:"args"`
+}
+
+func (e *ABIEvent) decode(logEntry []byte) (decodedABIEvent, error) {
+	selector := e.Selector()
+	if !bytes.HasPrefix(logEntry, selector) {
+		return decodedABIEvent{}, fmt.Errorf("log entry does not match the selector of event '%s'", e.Signature())
+	}
+
+	argTypes := make([]abi.Type, len(e.args))
+	for i, arg := range e.args {
+		argTypes[i] = arg.abiType
+	}
+	tupleType, err := abi.MakeTupleType(argTypes)
+	if err != nil {
+		return decodedABIEvent{}, err
+	}
+	decoded, err := tupleType.Decode(logEntry[len(selector):])
+	if err != nil {
+		return decodedABIEvent{}, fmt.Errorf("could not decode arguments of event '%s': %w", e.Signature(), err)
+	}
+	values := decoded.([]interface{})
+
+	args := make(map[string]json.RawMessage, len(values))
+	for i, value := range values {
+		args[e.argName(i)], err = e.args[i].abiType.MarshalToJSON(value)
+		if err != nil {
+			return decodedABIEvent{}, err
+		}
+	}
+	return decodedABIEvent{
+		Name:      e.name,
+		Signature: e.Signature(),
+		Args:      args,
+	}, nil
+}
+
+// Decode decodes a single log entry emitted by an application into a JSON object:
+//
+//	{"name":"Transfer","signature":"Transfer(address,address,uint64)","args":{"from":"...","to":"...","amount":5}}
+//
+// Arguments without a name are keyed by their position, "arg0", "arg1", etc. The argument values
+// use the same format as `ABIType.Decode()`.
+//
+// An error is returned if the log entry does not begin with the selector of this event.
+func (e *ABIEvent) Decode(logEntry []byte) (string, error) {
+	decoded, err := e.decode(logEntry)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(decoded)
+	return string(encoded), err
+}
+
+// ABIEventArray is a list of ABIEvents.
+type ABIEventArray struct {
+	values []*ABIEvent
+}
+
+func (ea *ABIEventArray) Length() int {
+	return len(ea.values)
+}
+
+func (ea *ABIEventArray) Append(value *ABIEvent) {
+	ea.values = append(ea.values, value)
+}
+
+func (ea *ABIEventArray) Get(index int) *ABIEvent {
+	return ea.values[index]
+}
+
+// ParseARC56Events returns all events declared in the "events" section of an ARC-56 app spec.
+func ParseARC56Events(arc56JSON string) (*ABIEventArray, error) {
+	var spec struct {
+		Events []arc28EventSpec `json:"events"`
+	}
+	err := json.Unmarshal([]byte(arc56JSON), &spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ARC-56 app spec: %w", err)
+	}
+	events := &ABIEventArray{}
+	for _, eventSpec := range spec.Events {
+		event, err := eventSpec.toEvent()
+		if err != nil {
+			return nil, err
+		}
+		events.Append(event)
+	}
+	return events, nil
+}
+
+// arc28TxnLogs contains the parts of a confirmed transaction which are relevant for events.
+type arc28TxnLogs struct {
+	Logs      [][]byte       `json:"logs"`
+	InnerTxns []arc28TxnLogs `json:"inner-txns"`
+}
+
+type decodedABIEventInTxn struct {
+	decodedABIEvent
+	TxnPath  []int `json:"txnPath"`
+	LogIndex int   `json:"logIndex"`
+}
+
+func decodeTxnEvents(events []*ABIEvent, txn arc28TxnLogs, path []int, result []decodedABIEventInTxn) []decodedABIEventInTxn {
+	for logIndex, logEntry := range txn.Logs {
+		for _, event := range events {
+			decoded, err := event.decode(logEntry)
+			if err != nil {
+				continue
+			}
+			result = append(result, decodedABIEventInTxn{
+				decodedABIEvent: decoded,
+				TxnPath:         append([]int{}, path...),
+				LogIndex:        logIndex,
+			})
+			break
+		}
+	}
+	for i, inner := range txn.InnerTxns {
+		result = decodeTxnEvents(events, inner, append(path, i), result)
+	}
+	return result
+}
+
+// DecodeARC28Events decodes every recognized event in the logs of a confirmed transaction and all
+// of its inner transactions.
+//
+// confirmedTxnJSON is a transaction as returned by algod's pending transaction endpoint or by the
+// indexer. Only its "logs" and "inner-txns" fields are used.
+//
+// The result is a JSON array of events in execution order. Each event has the format of
+// `ABIEvent.Decode()` with two additional fields: "txnPath", the indexes of the inner transaction
+// which emitted the event ([] for the top-level transaction, [0, 1] for the second inner transaction
+// of the first inner transaction, etc.), and "logIndex", the index of the log entry in that
+// transaction. Log entries which do not match any of the given events are skipped.
+func DecodeARC28Events(events *ABIEventArray, confirmedTxnJSON string) (string, error) {
+	var txn arc28TxnLogs
+	err := json.Unmarshal([]byte(confirmedTxnJSON), &txn)
+	if err != nil {
+		return "", fmt.Errorf("could not decode confirmed transaction: %w", err)
+	}
+	result := decodeTxnEvents(events.values, txn, []int{}, []decodedABIEventInTxn{})
+	encoded, err := json.Marshal(result)
+	return string(encoded), err
+}
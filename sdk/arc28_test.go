@@ -0,0 +1,103 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestEventLog(t *testing.T, event *ABIEvent, tupleType, tupleJSON string) []byte {
+	t.Helper()
+	abiType, err := ParseABIType(tupleType)
+	require.NoError(t, err)
+	encoded, err := abiType.Encode(tupleJSON)
+	require.NoError(t, err)
+	return append(event.Selector(), encoded...)
+}
+
+func TestABIEventFromSignature(t *testing.T) {
+	t.Parallel()
+	event, err := ABIEventFromSignature("Transfer(address,address,uint64)")
+	require.NoError(t, err)
+	require.Equal(t, "Transfer", event.Name())
+	require.Equal(t, "Transfer(address,address,uint64)", event.Signature())
+	require.Equal(t, MethodName("Transfer(address,address,uint64)"), hex.EncodeToString(event.Selector()))
+
+	logEntry := makeTestEventLog(t, event, "(address,address,uint64)", `["DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA","WWYNX3TKQYVEREVSW6QQP3SXSFOCE3SKUSEIVJ7YAGUPEACNI5UGI4DZCE",5]`)
+	decoded, err := event.Decode(logEntry)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "Transfer",
+		"signature": "Transfer(address,address,uint64)",
+		"args": {
+			"arg0": "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA",
+			"arg1": "WWYNX3TKQYVEREVSW6QQP3SXSFOCE3SKUSEIVJ7YAGUPEACNI5UGI4DZCE",
+			"arg2": 5
+		}
+	}`, decoded)
+
+	_, err = event.Decode([]byte{0x15, 0x1f, 0x7c, 0x75})
+	require.Error(t, err)
+
+	_, err = ABIEventFromSignature("Bad(pay)")
+	require.Error(t, err)
+}
+
+func TestABIEventFromJSON(t *testing.T) {
+	t.Parallel()
+	event, err := ABIEventFromJSON(`{"name":"Swap","args":[{"type":"uint64","name":"assetIn"},{"type":"uint64","name":"amountIn"},{"type":"string","name":"memo"}]}`)
+	require.NoError(t, err)
+	require.Equal(t, "Swap(uint64,uint64,string)", event.Signature())
+
+	logEntry := makeTestEventLog(t, event, "(uint64,uint64,string)", `[31566704,1000000,"hello"]`)
+	decoded, err := event.Decode(logEntry)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "Swap",
+		"signature": "Swap(uint64,uint64,string)",
+		"args": {"assetIn": 31566704, "amountIn": 1000000, "memo": "hello"}
+	}`, decoded)
+
+	_, err = ABIEventFromJSON(`{"name":"Bad","args":[{"type":"uint7"}]}`)
+	require.Error(t, err)
+}
+
+func TestDecodeARC28Events(t *testing.T) {
+	t.Parallel()
+	events, err := ParseARC56Events(`{
+		"name": "Pool",
+		"events": [
+			{"name": "Deposit", "args": [{"type": "uint64", "name": "amount"}]},
+			{"name": "Withdraw", "args": [{"type": "uint64", "name": "amount"}, {"type": "bool", "name": "closed"}]}
+		]
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, 2, events.Length())
+
+	deposit := makeTestEventLog(t, events.Get(0), "(uint64)", `[10]`)
+	withdraw := makeTestEventLog(t, events.Get(1), "(uint64,bool)", `[3,true]`)
+	returnLog := []byte{0x15, 0x1f, 0x7c, 0x75, 0, 0, 0, 0, 0, 0, 0, 1}
+
+	b64 := base64.StdEncoding.EncodeToString
+	confirmedTxn := `{
+		"confirmed-round": 100,
+		"logs": ["` + b64(deposit) + `", "` + b64(returnLog) + `"],
+		"inner-txns": [
+			{"logs": []},
+			{"inner-txns": [{"logs": ["` + b64(withdraw) + `"]}]}
+		]
+	}`
+
+	decoded, err := DecodeARC28Events(events, confirmedTxn)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"name": "Deposit", "signature": "Deposit(uint64)", "args": {"amount": 10}, "txnPath": [], "logIndex": 0},
+		{"name": "Withdraw", "signature": "Withdraw(uint64,bool)", "args": {"amount": 3, "closed": true}, "txnPath": [1, 0], "logIndex": 0}
+	]`, decoded)
+
+	decoded, err = DecodeARC28Events(events, `{"logs": []}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `[]`, decoded)
+}
@@ -0,0 +1,431 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// Types of the variables of a LogicSig template
+const (
+	TemplateTypeUint64      = "uint64"
+	TemplateTypeApplication = "application"
+	TemplateTypeAsset       = "asset"
+	TemplateTypeAddress     = "address"
+	TemplateTypeBytes       = "bytes"
+	TemplateTypeString      = "string"
+)
+
+// templateVariablePrefix is the prefix of the names of template variables
+const templateVariablePrefix = "TMPL_"
+
+// templateHashPrefix separates template hashes from other hashes
+var templateHashPrefix = []byte("arc47")
+
+type templateVariable struct {
+	name        string
+	varType     string
+	description string
+	// offset is the position of the encoded placeholder value in the template bytecode
+	offset int
+	// length is the length of the encoded placeholder value
+	length int
+}
+
+func (v *templateVariable) isUint() bool {
+	switch v.varType {
+	case TemplateTypeUint64, TemplateTypeApplication, TemplateTypeAsset:
+		return true
+	}
+	return false
+}
+
+// encode encodes a JSON value of the variable as it appears in bytecode
+func (v *templateVariable) encode(value json.RawMessage) ([]byte, error) {
+	if v.isUint() {
+		var n uint64
+		if err := json.Unmarshal(value, &n); err != nil {
+			return nil, fmt.Errorf("value of %s is not a uint64: %w", v.name, err)
+		}
+		return binary.AppendUvarint(nil, n), nil
+	}
+
+	var b []byte
+	switch v.varType {
+	case TemplateTypeAddress:
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return nil, fmt.Errorf("value of %s is not an address: %w", v.name, err)
+		}
+		addr, err := types.DecodeAddress(s)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s is not an address: %w", v.name, err)
+		}
+		b = addr[:]
+	case TemplateTypeBytes:
+		if err := json.Unmarshal(value, &b); err != nil {
+			return nil, fmt.Errorf("value of %s is not base64 encoded bytes: %w", v.name, err)
+		}
+	case TemplateTypeString:
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return nil, fmt.Errorf("value of %s is not a string: %w", v.name, err)
+		}
+		b = []byte(s)
+	}
+	return append(binary.AppendUvarint(nil, uint64(len(b))), b...), nil
+}
+
+// decode decodes the value of the variable at the start of program, and returns it as JSON along
+// with its encoded length.
+func (v *templateVariable) decode(program []byte) (json.RawMessage, int, error) {
+	r := &tealReader{program: program}
+	if v.isUint() {
+		n, err := r.readVarUint()
+		if err != nil {
+			return nil, 0, err
+		}
+		encoded, err := json.Marshal(n)
+		return encoded, r.pc, err
+	}
+
+	b, err := r.readBytes()
+	if err != nil {
+		return nil, 0, err
+	}
+	var value interface{}
+	switch v.varType {
+	case TemplateTypeAddress:
+		if len(b) != len(types.Address{}) {
+			return nil, 0, fmt.Errorf("value of %s is not an address", v.name)
+		}
+		var addr types.Address
+		copy(addr[:], b)
+		value = addr.String()
+	case TemplateTypeString:
+		value = string(b)
+	default:
+		value = b
+	}
+	encoded, err := json.Marshal(value)
+	return encoded, r.pc, err
+}
+
+// LogicSigTemplate is a compiled LogicSig program with template variables, as described in ARC-47.
+type LogicSigTemplate struct {
+	name        string
+	description string
+	bytecode    []byte
+	program     *tealProgram
+	// variables are sorted by offset
+	variables []templateVariable
+}
+
+type logicSigTemplateDescriptor struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Bytecode    []byte `json:"bytecode"`
+	Variables   []struct {
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		Description string `json:"description"`
+		Offset      int    `json:"offset"`
+	} `json:"variables"`
+}
+
+// ParseLogicSigTemplate parses the JSON descriptor of a LogicSig template:
+//
+//	{
+//	  "name": "Escrow",
+//	  "description": "Pays the receiver after a round",
+//	  "bytecode": "<base64 encoded compiled program>",
+//	  "variables": [
+//	    {"name": "TMPL_RECEIVER", "type": "address", "description": "The receiver", "offset": 12}
+//	  ]
+//	}
+//
+// The offset of a variable is the position in the bytecode of the placeholder value compiled in its
+// place: a varuint for uint64, application and asset variables, and a length prefixed byte string
+// for address, bytes and string variables.
+func ParseLogicSigTemplate(descriptorJSON string) (*LogicSigTemplate, error) {
+	var descriptor logicSigTemplateDescriptor
+	if err := json.Unmarshal([]byte(descriptorJSON), &descriptor); err != nil {
+		return nil, fmt.Errorf("could not decode template descriptor: %w", err)
+	}
+	program, err := NewTEALDisassembler().decode(descriptor.Bytecode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template bytecode: %w", err)
+	}
+
+	template := &LogicSigTemplate{
+		name:        descriptor.Name,
+		description: descriptor.Description,
+		bytecode:    descriptor.Bytecode,
+		program:     program,
+		variables:   make([]templateVariable, len(descriptor.Variables)),
+	}
+	names := make(map[string]bool, len(descriptor.Variables))
+	for i, variable := range descriptor.Variables {
+		if !strings.HasPrefix(variable.Name, templateVariablePrefix) {
+			return nil, fmt.Errorf("template variable '%s' does not start with %s", variable.Name, templateVariablePrefix)
+		}
+		if names[variable.Name] {
+			return nil, fmt.Errorf("duplicate template variable %s", variable.Name)
+		}
+		names[variable.Name] = true
+		switch variable.Type {
+		case TemplateTypeUint64, TemplateTypeApplication, TemplateTypeAsset, TemplateTypeAddress, TemplateTypeBytes, TemplateTypeString:
+		default:
+			return nil, fmt.Errorf("unknown type '%s' of template variable %s", variable.Type, variable.Name)
+		}
+		template.variables[i] = templateVariable{
+			name:        variable.Name,
+			varType:     variable.Type,
+			description: variable.Description,
+			offset:      variable.Offset,
+		}
+	}
+	sort.Slice(template.variables, func(i, j int) bool {
+		return template.variables[i].offset < template.variables[j].offset
+	})
+
+	end := 0
+	for i := range template.variables {
+		variable := &template.variables[i]
+		if variable.offset < end || variable.offset >= len(template.bytecode) {
+			return nil, fmt.Errorf("invalid offset %d of template variable %s", variable.offset, variable.name)
+		}
+		// the placeholder must be an immediate of a single instruction
+		index, ok := program.instructionAt(variable.offset)
+		if ok || index == 0 {
+			return nil, fmt.Errorf("template variable %s is not an immediate value", variable.name)
+		}
+		inst := &program.instructions[index-1]
+		r := &tealReader{program: template.bytecode[:inst.offset+inst.size], pc: variable.offset}
+		if variable.isUint() {
+			_, err = r.readVarUint()
+		} else {
+			_, err = r.readBytes()
+		}
+		if err != nil || len(inst.targets) > 0 {
+			return nil, fmt.Errorf("template variable %s is not an immediate value", variable.name)
+		}
+		variable.length = r.pc - variable.offset
+		end = r.pc
+	}
+	return template, nil
+}
+
+// Name returns the name of the template.
+func (t *LogicSigTemplate) Name() string {
+	return t.name
+}
+
+// Description returns the description of the template.
+func (t *LogicSigTemplate) Description() string {
+	return t.description
+}
+
+// Bytecode returns the compiled template, with placeholder values in place of its variables.
+func (t *LogicSigTemplate) Bytecode() []byte {
+	return t.bytecode
+}
+
+// VariableNames returns the names of the variables of the template, in the order they appear in
+// the bytecode.
+func (t *LogicSigTemplate) VariableNames() *StringArray {
+	names := make([]string, len(t.variables))
+	for i, variable := range t.variables {
+		names[i] = variable.name
+	}
+	return &StringArray{names}
+}
+
+func (t *LogicSigTemplate) variable(name string) (*templateVariable, error) {
+	for i := range t.variables {
+		if t.variables[i].name == name {
+			return &t.variables[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown template variable %s", name)
+}
+
+// VariableType returns the type of a variable of the template.
+func (t *LogicSigTemplate) VariableType(name string) (string, error) {
+	variable, err := t.variable(name)
+	if err != nil {
+		return "", err
+	}
+	return variable.varType, nil
+}
+
+// VariableDescription returns the description of a variable of the template.
+func (t *LogicSigTemplate) VariableDescription(name string) (string, error) {
+	variable, err := t.variable(name)
+	if err != nil {
+		return "", err
+	}
+	return variable.description, nil
+}
+
+// Hash returns the hash identifying the template. It is the SHA-512/256 hash of the bytecode of
+// the template outside of its variables, along with the names and types of the variables, so every
+// program made from the template has the same hash whatever the values of its variables.
+func (t *LogicSigTemplate) Hash() []byte {
+	appendField := func(b []byte, field []byte) []byte {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(field)))
+		return append(b, field...)
+	}
+	toHash := append([]byte(nil), templateHashPrefix...)
+	start := 0
+	for _, variable := range t.variables {
+		toHash = appendField(toHash, t.bytecode[start:variable.offset])
+		toHash = appendField(toHash, []byte(variable.name))
+		toHash = appendField(toHash, []byte(variable.varType))
+		start = variable.offset + variable.length
+	}
+	toHash = appendField(toHash, t.bytecode[start:])
+	hash := sha512.Sum512_256(toHash)
+	return hash[:]
+}
+
+// Substitute replaces the variables of the template with values, and returns the resulting
+// program. The values are given as a JSON object mapping every variable name to its value: a number
+// for uint64, application and asset variables, an address string for address variables, base64
+// for bytes variables and a string for string variables, for example:
+//
+//	{"TMPL_RECEIVER": "<address>", "TMPL_AMOUNT": 1000000}
+//
+// Branches over values whose encoded length changes are updated to their new targets.
+func (t *LogicSigTemplate) Substitute(valuesJSON string) ([]byte, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(valuesJSON), &values); err != nil {
+		return nil, fmt.Errorf("could not decode template values: %w", err)
+	}
+	for name := range values {
+		if _, err := t.variable(name); err != nil {
+			return nil, err
+		}
+	}
+
+	encoded := make([][]byte, len(t.variables))
+	for i := range t.variables {
+		value, ok := values[t.variables[i].name]
+		if !ok {
+			return nil, fmt.Errorf("missing value of template variable %s", t.variables[i].name)
+		}
+		var err error
+		encoded[i], err = t.variables[i].encode(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.substitute(encoded)
+}
+
+// substitute replaces the variables of the template with their encoded values.
+func (t *LogicSigTemplate) substitute(encoded [][]byte) ([]byte, error) {
+	var program []byte
+	start := 0
+	for i, variable := range t.variables {
+		program = append(program, t.bytecode[start:variable.offset]...)
+		program = append(program, encoded[i]...)
+		start = variable.offset + variable.length
+	}
+	program = append(program, t.bytecode[start:]...)
+
+	// newOffset maps offsets of the template, outside of variables, to offsets of the program
+	newOffset := func(offset int) int {
+		shifted := offset
+		for i, variable := range t.variables {
+			if variable.offset >= offset {
+				break
+			}
+			shifted += len(encoded[i]) - variable.length
+		}
+		return shifted
+	}
+	for i := range t.program.instructions {
+		inst := &t.program.instructions[i]
+		if len(inst.targets) == 0 {
+			continue
+		}
+		position := inst.offset + 1
+		if inst.name() == "switch" || inst.name() == "match" {
+			// skip the count of labels
+			position++
+		}
+		end := newOffset(inst.offset + inst.size)
+		for _, target := range inst.targets {
+			relative := newOffset(target) - end
+			if relative < math.MinInt16 || relative > math.MaxInt16 {
+				return nil, fmt.Errorf("branch of %s at offset %d is too long after substitution", inst.name(), inst.offset)
+			}
+			binary.BigEndian.PutUint16(program[newOffset(position):], uint16(int16(relative)))
+			position += 2
+		}
+	}
+	return program, nil
+}
+
+// MakeLogicSigAccountEscrow substitutes the variables of the template with values, and creates an
+// escrow LogicSigAccount from the resulting program. See Substitute for the format of the values.
+func (t *LogicSigTemplate) MakeLogicSigAccountEscrow(valuesJSON string, args *BytesArray) (*LogicSigAccount, error) {
+	program, err := t.Substitute(valuesJSON)
+	if err != nil {
+		return nil, err
+	}
+	return MakeLogicSigAccountEscrow(program, args)
+}
+
+// VerifyProgram checks that a program was made from the template, and returns the values of its
+// variables as a JSON object, in the format accepted by Substitute. The program may also be given
+// as returned by LogicSigProgramForSigning.
+func (t *LogicSigTemplate) VerifyProgram(program []byte) (string, error) {
+	program = bytes.TrimPrefix(program, []byte("Program"))
+	values := make(map[string]json.RawMessage, len(t.variables))
+	encoded := make([][]byte, len(t.variables))
+	position := 0
+	start := 0
+	for i := range t.variables {
+		variable := &t.variables[i]
+		position += variable.offset - start
+		if position >= len(program) {
+			return "", fmt.Errorf("program was not made from template '%s'", t.name)
+		}
+		value, length, err := variable.decode(program[position:])
+		if err != nil {
+			return "", fmt.Errorf("program was not made from template '%s': %w", t.name, err)
+		}
+		values[variable.name] = value
+		encoded[i] = program[position : position+length]
+		position += length
+		start = variable.offset + variable.length
+	}
+
+	// the program must be exactly the template with the values found in it
+	expected, err := t.substitute(encoded)
+	if err != nil || !bytes.Equal(expected, program) {
+		return "", fmt.Errorf("program was not made from template '%s'", t.name)
+	}
+	result, err := json.Marshal(values)
+	return string(result), err
+}
+
+// VerifyDelegationRequest checks a request to delegate a LogicSig program before it is signed: the
+// template must have the expected hash, usually from a list of trusted templates, and the program
+// must have been made from the template. It returns the values of the variables of the program.
+// See VerifyProgram.
+func (t *LogicSigTemplate) VerifyDelegationRequest(program []byte, templateHash []byte) (string, error) {
+	if !bytes.Equal(t.Hash(), templateHash) {
+		return "", fmt.Errorf("template '%s' does not match the expected hash", t.name)
+	}
+	return t.VerifyProgram(program)
+}
@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+var testTemplateBytecode = []byte{
+	0x08,             // #pragma version 8
+	0x20, 0x01, 0x00, // intcblock TMPL_AMOUNT
+	0x26, 0x01, 0x00, // bytecblock TMPL_RECEIVER
+	0x31, 0x07, 0x28, 0x12, 0x44, // label1: txn Receiver; bytec_0; ==; assert
+	0x31, 0x08, 0x22, 0x0e, // txn Amount; intc_0; <=
+	0x41, 0x00, 0x03, // bz label2
+	0x80, 0x00, 0x48, // pushbytes TMPL_NOTE; pop
+	0x81, 0x01, // label2: pushint 1
+	0x42, 0xff, 0xec, // b label1
+}
+
+func makeTestTemplateDescriptor(variables string) string {
+	return fmt.Sprintf(`{"name":"Limit","description":"Pays up to an amount","bytecode":"%s","variables":[%s]}`,
+		base64.StdEncoding.EncodeToString(testTemplateBytecode), variables)
+}
+
+const testTemplateVariables = `{"name":"TMPL_RECEIVER","type":"address","description":"The receiver","offset":6},` +
+	`{"name":"TMPL_AMOUNT","type":"uint64","offset":3},` +
+	`{"name":"TMPL_NOTE","type":"string","offset":20}`
+
+func TestLogicSigTemplateSubstitute(t *testing.T) {
+	t.Parallel()
+	template, err := ParseLogicSigTemplate(makeTestTemplateDescriptor(testTemplateVariables))
+	require.NoError(t, err)
+	require.Equal(t, "Limit", template.Name())
+	require.Equal(t, "Pays up to an amount", template.Description())
+	require.Equal(t, testTemplateBytecode, template.Bytecode())
+	require.Equal(t, []string{"TMPL_AMOUNT", "TMPL_RECEIVER", "TMPL_NOTE"}, template.VariableNames().Extract())
+	varType, err := template.VariableType("TMPL_RECEIVER")
+	require.NoError(t, err)
+	require.Equal(t, TemplateTypeAddress, varType)
+	description, err := template.VariableDescription("TMPL_RECEIVER")
+	require.NoError(t, err)
+	require.Equal(t, "The receiver", description)
+	_, err = template.VariableType("TMPL_OTHER")
+	require.Error(t, err)
+
+	receiver := types.Address{1, 2, 3}
+	values := fmt.Sprintf(`{"TMPL_RECEIVER":"%s","TMPL_AMOUNT":1000000,"TMPL_NOTE":"hello"}`, receiver)
+	program, err := template.Substitute(values)
+	require.NoError(t, err)
+
+	// the values change the length of the program, so the branches are moved
+	text, err := DisassembleTEAL(program)
+	require.NoError(t, err)
+	expected := strings.Join([]string{
+		"#pragma version 8",
+		"intcblock 1000000",
+		"bytecblock 0x" + hex.EncodeToString(receiver[:]) + " // addr " + receiver.String(),
+		"label1:",
+		"txn Receiver",
+		"bytec_0 // addr " + receiver.String(),
+		"==",
+		"assert",
+		"txn Amount",
+		"intc_0 // 1000000",
+		"<=",
+		"bz label2",
+		"pushbytes 0x68656c6c6f // \"hello\"",
+		"pop",
+		"label2:",
+		"pushint 1",
+		"b label1",
+	}, "\n") + "\n"
+	require.Equal(t, expected, text)
+
+	lsigAccount, err := template.MakeLogicSigAccountEscrow(values, nil)
+	require.NoError(t, err)
+	expectedAccount, err := MakeLogicSigAccountEscrow(program, nil)
+	require.NoError(t, err)
+	require.Equal(t, expectedAccount.ToJSON(), lsigAccount.ToJSON())
+
+	_, err = template.Substitute(`{"TMPL_AMOUNT":1,"TMPL_NOTE":""}`)
+	require.ErrorContains(t, err, "missing value of template variable TMPL_RECEIVER")
+	_, err = template.Substitute(`{"TMPL_RECEIVER":"not an address","TMPL_AMOUNT":1,"TMPL_NOTE":""}`)
+	require.Error(t, err)
+	_, err = template.Substitute(fmt.Sprintf(`{"TMPL_RECEIVER":"%s","TMPL_AMOUNT":-1,"TMPL_NOTE":""}`, receiver))
+	require.Error(t, err)
+	_, err = template.Substitute(fmt.Sprintf(`{"TMPL_RECEIVER":"%s","TMPL_AMOUNT":1,"TMPL_NOTE":"","TMPL_X":1}`, receiver))
+	require.ErrorContains(t, err, "unknown template variable TMPL_X")
+}
+
+func TestLogicSigTemplateVerify(t *testing.T) {
+	t.Parallel()
+	template, err := ParseLogicSigTemplate(makeTestTemplateDescriptor(testTemplateVariables))
+	require.NoError(t, err)
+	receiver := types.Address{4, 5, 6}
+	program, err := template.Substitute(fmt.Sprintf(`{"TMPL_RECEIVER":"%s","TMPL_AMOUNT":300,"TMPL_NOTE":"memo"}`, receiver))
+	require.NoError(t, err)
+
+	found, err := template.VerifyDelegationRequest(LogicSigProgramForSigning(program), template.Hash())
+	require.NoError(t, err)
+	var values map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(found), &values))
+	require.Equal(t, map[string]interface{}{
+		"TMPL_RECEIVER": receiver.String(),
+		"TMPL_AMOUNT":   300.0,
+		"TMPL_NOTE":     "memo",
+	}, values)
+
+	// the values found in a program make the same program
+	again, err := template.Substitute(found)
+	require.NoError(t, err)
+	require.Equal(t, program, again)
+
+	// the hash does not depend on the values of the variables, but on everything else
+	other, err := ParseLogicSigTemplate(makeTestTemplateDescriptor(strings.Replace(testTemplateVariables, "TMPL_NOTE", "TMPL_MEMO", 1)))
+	require.NoError(t, err)
+	require.Len(t, template.Hash(), 32)
+	require.NotEqual(t, template.Hash(), other.Hash())
+	_, err = template.VerifyDelegationRequest(program, other.Hash())
+	require.ErrorContains(t, err, "does not match the expected hash")
+
+	changed := append([]byte(nil), program...)
+	changed[len(changed)-4] = 0x00 // pushint 0
+	_, err = template.VerifyProgram(changed)
+	require.ErrorContains(t, err, "program was not made from template")
+	_, err = template.VerifyProgram(program[:10])
+	require.Error(t, err)
+	_, err = template.VerifyProgram(testTemplateBytecode)
+	require.ErrorContains(t, err, "is not an address")
+}
+
+func TestParseLogicSigTemplateInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		variables string
+		err       string
+	}{
+		{"missing prefix", `{"name":"AMOUNT","type":"uint64","offset":3}`, "does not start with TMPL_"},
+		{"duplicate", `{"name":"TMPL_A","type":"uint64","offset":3},{"name":"TMPL_A","type":"bytes","offset":6}`, "duplicate template variable TMPL_A"},
+		{"unknown type", `{"name":"TMPL_A","type":"uint32","offset":3}`, "unknown type 'uint32'"},
+		{"outside the bytecode", `{"name":"TMPL_A","type":"uint64","offset":100}`, "invalid offset 100"},
+		{"overlapping", `{"name":"TMPL_A","type":"bytes","offset":5},{"name":"TMPL_B","type":"bytes","offset":6}`, "invalid offset 6"},
+		{"instruction", `{"name":"TMPL_A","type":"uint64","offset":7}`, "is not an immediate value"},
+		{"branch", `{"name":"TMPL_A","type":"uint64","offset":17}`, "is not an immediate value"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseLogicSigTemplate(makeTestTemplateDescriptor(test.variables))
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+
+	_, err := ParseLogicSigTemplate(`{"bytecode":"AP8="}`)
+	require.ErrorContains(t, err, "invalid template bytecode")
+}
@@ -9,6 +9,7 @@ import (
 )
 
 // MakeARC59SendTxn creates the payment, asset transfer and app call transactions for sending an asset with the ARC59 protocol.
+// The lease of options is set on the first transaction of the group and the rekey address on the last one.
 func MakeARC59SendTxn(
 	sender,
 	receiver,
@@ -22,6 +23,7 @@ func MakeARC59SendTxn(
 	suggestedParams *SuggestedParams,
 	is_arc59_opted_in bool,
 	extraAlgoAmount *Uint64,
+	options *TransactionOptions,
 ) (assignedTxns *BytesArray, err error) {
 	appCallSuggestedParams := *suggestedParams
 	suggestedParamsForArc59OptIn := *suggestedParams
@@ -47,6 +49,7 @@ func MakeARC59SendTxn(
 	}
 
 	bytesArrayTxns := BytesArray{values: [][]byte{}}
+	group := groupOptions{options: options}
 
 	// 1) Payment TX
 
@@ -57,6 +60,7 @@ func MakeARC59SendTxn(
 		nil,
 		"",
 		suggestedParams,
+		group.next(false),
 	)
 	if paymentTxnError != nil {
 		err = paymentTxnError
@@ -89,6 +93,7 @@ func MakeARC59SendTxn(
 			&suggestedParamsForArc59OptIn,
 			sender,
 			nil,
+			group.next(false),
 		)
 		if arc59OptInCallTxnError != nil {
 			err = arc59OptInCallTxnError
@@ -107,6 +112,7 @@ func MakeARC59SendTxn(
 		nil,
 		suggestedParams,
 		assetID,
+		group.next(false),
 	)
 	if assetTxnError != nil {
 		err = assetTxnError
@@ -146,6 +152,7 @@ func MakeARC59SendTxn(
 		&appCallSuggestedParams,
 		sender,
 		nil,
+		group.next(true),
 	)
 	if appCallTxnError != nil {
 		err = appCallTxnError
@@ -175,6 +182,7 @@ func MakeAndSignARC59SendTxn(
 	is_arc59_opted_in bool,
 	extraAlgoAmount *Uint64,
 	sk []byte,
+	options *TransactionOptions,
 ) (signedTxns *BytesArray, err error) {
 	groupTxns, groupTxnError := MakeARC59SendTxn(
 		sender,
@@ -189,6 +197,7 @@ func MakeAndSignARC59SendTxn(
 		suggestedParams,
 		is_arc59_opted_in,
 		extraAlgoAmount,
+		options,
 	)
 	if groupTxnError != nil {
 		err = groupTxnError
@@ -211,6 +220,7 @@ func MakeAndSignARC59SendTxn(
 }
 
 // MakeARC59ClaimTxn creates the app call transaction, and opt in transaction if needed, to claim the asset from the ARC59 protocol.
+// The lease of options is set on the first transaction of the group and the rekey address on the last one.
 func MakeARC59ClaimTxn(
 	receiver,
 	inboxAccountAddress string,
@@ -219,6 +229,7 @@ func MakeARC59ClaimTxn(
 	suggestedParams *SuggestedParams,
 	isOptedInToAsset,
 	isClaimingAlgo bool,
+	options *TransactionOptions,
 ) (assignedTxns *BytesArray, err error) {
 	zeroFeeParams := *suggestedParams
 	zeroFeeParams.FlatFee = true
@@ -246,6 +257,7 @@ func MakeARC59ClaimTxn(
 	decodedReceiver, _ := DecodeAddress(receiver)
 
 	bytesArrayTxns := BytesArray{values: [][]byte{}}
+	group := groupOptions{options: options}
 
 	// 1) claim tx
 	if isClaimingAlgo {
@@ -270,6 +282,7 @@ func MakeARC59ClaimTxn(
 			&zeroFeeParams,
 			receiver,
 			nil,
+			group.next(false),
 		)
 		if claimAlgoAppCallTxnError != nil {
 			err = claimAlgoAppCallTxnError
@@ -290,6 +303,7 @@ func MakeARC59ClaimTxn(
 			nil,
 			&zeroFeeParams,
 			assetID,
+			group.next(false),
 		)
 		if assetTxnError != nil {
 			err = assetTxnError
@@ -321,6 +335,7 @@ func MakeARC59ClaimTxn(
 		&appCallSuggestedParams,
 		receiver,
 		nil,
+		group.next(true),
 	)
 	if appCallTxnError != nil {
 		err = appCallTxnError
@@ -343,6 +358,7 @@ func MakeAndSignARC59ClaimTxn(
 	isOptedInToAsset,
 	isClaimingAlgo bool,
 	sk []byte,
+	options *TransactionOptions,
 ) (signedTxns *BytesArray, err error) {
 	groupTxns, groupTxnError := MakeARC59ClaimTxn(
 		receiver,
@@ -352,6 +368,7 @@ func MakeAndSignARC59ClaimTxn(
 		suggestedParams,
 		isOptedInToAsset,
 		isClaimingAlgo,
+		options,
 	)
 	if groupTxnError != nil {
 		err = groupTxnError
@@ -374,6 +391,7 @@ func MakeAndSignARC59ClaimTxn(
 }
 
 // MakeARC59RejectTxn creates the app call transaction to reject the asset from the ARC59 protocol.
+// The lease of options is set on the first transaction of the group and the rekey address on the last one.
 func MakeARC59RejectTxn(
 	receiver,
 	inboxAccountAddress,
@@ -382,6 +400,7 @@ func MakeARC59RejectTxn(
 	assetID int64,
 	suggestedParams *SuggestedParams,
 	isClaimingAlgo bool,
+	options *TransactionOptions,
 ) (assignedTxns *BytesArray, err error) {
 	appCallSuggestedParams := *suggestedParams
 	appCallSuggestedParams.FlatFee = true
@@ -408,6 +427,12 @@ func MakeARC59RejectTxn(
 	claimAlgoTxnSuggestedParams.FlatFee = true
 	claimAlgoTxnSuggestedParams.Fee = 0
 
+	group := groupOptions{options: options}
+	var claimAlgoOptions *TransactionOptions
+	if isClaimingAlgo {
+		claimAlgoOptions = group.next(false)
+	}
+
 	methodNameHex := MethodName("arc59_claimAlgo()void")
 	methodNameBytes, err := hex.DecodeString(methodNameHex)
 	methodNameAppArgs := [][]byte{methodNameBytes}
@@ -429,6 +454,7 @@ func MakeARC59RejectTxn(
 		&claimAlgoTxnSuggestedParams,
 		receiver,
 		nil,
+		claimAlgoOptions,
 	)
 	if claimAlgoAppCallTxnError != nil {
 		err = claimAlgoAppCallTxnError
@@ -464,6 +490,7 @@ func MakeARC59RejectTxn(
 		&appCallSuggestedParams,
 		receiver,
 		nil,
+		group.next(true),
 	)
 
 	bytesArrayTxns := BytesArray{values: [][]byte{}}
@@ -487,6 +514,7 @@ func MakeAndSignARC59RejectTxn(
 	suggestedParams *SuggestedParams,
 	isClaimingAlgo bool,
 	sk []byte,
+	options *TransactionOptions,
 ) (signedTxns *BytesArray, err error) {
 	groupTxns, groupTxnError := MakeARC59RejectTxn(
 		receiver,
@@ -496,6 +524,7 @@ func MakeAndSignARC59RejectTxn(
 		assetID,
 		suggestedParams,
 		isClaimingAlgo,
+		options,
 	)
 	if groupTxnError != nil {
 		err = groupTxnError
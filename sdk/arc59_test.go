@@ -45,6 +45,7 @@ func TestMakeAndSignARC59SendTxn(t *testing.T) {
 		false,
 		&algoAmount,
 		sk,
+		nil,
 	)
 	require.NoError(t, err)
 	txns := txnsByteArray.Extract()
@@ -85,6 +86,7 @@ func TestMakeAndSignARC59SendTxn(t *testing.T) {
 		true,
 		&algoAmount,
 		sk,
+		nil,
 	)
 	require.NoError(t, err)
 	txns = txnsByteArray.Extract()
@@ -136,6 +138,7 @@ func TestMakeAndSignARC59ClaimTxn(t *testing.T) {
 		true,
 		false,
 		sk,
+		nil,
 	)
 	require.NoError(t, err)
 	txs := generated_txs.Extract()
@@ -158,6 +161,7 @@ func TestMakeAndSignARC59ClaimTxn(t *testing.T) {
 		false,
 		false,
 		sk,
+		nil,
 	)
 	require.NoError(t, err)
 	txs_2 := generated_txns_2.Extract()
@@ -185,6 +189,7 @@ func TestMakeAndSignARC59ClaimTxn(t *testing.T) {
 		false,
 		true,
 		sk,
+		nil,
 	)
 	require.NoError(t, err)
 	txs_3 := generated_txns_3.Extract()
@@ -234,6 +239,7 @@ func TestMakeAndSignARC59RejectTxn(t *testing.T) {
 		&suggested_params,
 		false,
 		sk,
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -0,0 +1,302 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// arc59BatchReceiver is a receiver of an ARC59SendBatch.
+type arc59BatchReceiver struct {
+	address types.Address
+	amount  Uint64
+	info    ARC59SendInfo
+	// algoAmount is the amount paid to the router for this receiver: the minimum balance of the
+	// inbox and the ALGO the receiver needs to claim.
+	algoAmount uint64
+	// extraAlgoAmount is the ALGO the receiver needs to claim.
+	extraAlgoAmount uint64
+}
+
+// txnCount returns the number of transactions which send the asset to the receiver.
+func (r arc59BatchReceiver) txnCount() int {
+	if r.info.ReceiverOptedIn {
+		return 1
+	}
+	return 2
+}
+
+// ARC59SendBatch sends one asset to many receivers through the ARC-59 router, packing the sends
+// into as few transaction groups as possible.
+type ARC59SendBatch struct {
+	sender    string
+	appID     int64
+	assetID   int64
+	receivers []arc59BatchReceiver
+}
+
+// NewARC59SendBatch creates an empty batch which sends the asset assetID from sender through the
+// ARC-59 router application appID.
+func NewARC59SendBatch(sender string, appID, assetID int64) (*ARC59SendBatch, error) {
+	if _, err := types.DecodeAddress(sender); err != nil {
+		return nil, err
+	}
+	if appID < 0 || assetID < 0 {
+		return nil, errNegativeArgument
+	}
+	return &ARC59SendBatch{sender: sender, appID: appID, assetID: assetID}, nil
+}
+
+// AddReceiver adds a receiver of amount units of the asset to the batch. info is the state of the
+// receiver and the router, as returned by GetARC59SendInfo.
+//
+// Receivers which are already opted in to the asset are sent the asset directly, without going
+// through the router. A receiver can only be added once, since its info does not account for
+// other sends to it in the same batch.
+func (b *ARC59SendBatch) AddReceiver(receiver string, amount *Uint64, info *ARC59SendInfo) error {
+	if info == nil {
+		return errors.New("missing ARC-59 send info")
+	}
+	address, err := types.DecodeAddress(receiver)
+	if err != nil {
+		return err
+	}
+	for _, existing := range b.receivers {
+		if existing.address == address {
+			return fmt.Errorf("receiver %s was already added to the batch", receiver)
+		}
+	}
+	if _, err = amount.Extract(); err != nil {
+		return fmt.Errorf("Could not decode transaction amount: %v", err)
+	}
+	if info.InnerTxCount < 0 {
+		return errNegativeArgument
+	}
+	if info.InboxAddress != "" {
+		if _, err = types.DecodeAddress(info.InboxAddress); err != nil {
+			return fmt.Errorf("invalid inbox address: %w", err)
+		}
+	}
+
+	entry := arc59BatchReceiver{address: address, amount: *amount, info: *info}
+	if !info.ReceiverOptedIn {
+		mbr, err := info.MinimumBalanceRequirement.Extract()
+		if err != nil {
+			return fmt.Errorf("Could not decode minimum balance requirement: %v", err)
+		}
+		entry.extraAlgoAmount, err = info.ReceiverAlgoNeededForClaim.Extract()
+		if err != nil {
+			return fmt.Errorf("Could not decode ALGO needed for claim: %v", err)
+		}
+		entry.algoAmount = mbr + entry.extraAlgoAmount
+		if entry.algoAmount < mbr {
+			return errors.New("ALGO amount for the receiver overflows")
+		}
+	}
+	b.receivers = append(b.receivers, entry)
+	return nil
+}
+
+// Length returns the number of receivers in the batch.
+func (b *ARC59SendBatch) Length() int {
+	return len(b.receivers)
+}
+
+// arc59BatchGroup is the receivers of one transaction group of a batch.
+type arc59BatchGroup struct {
+	receivers  []arc59BatchReceiver
+	optIn      bool
+	algoAmount uint64
+}
+
+// with returns a copy of the group which also sends the asset to receiver, and opts the router in
+// to the asset if optIn is true.
+func (g arc59BatchGroup) with(receiver arc59BatchReceiver, optIn bool) arc59BatchGroup {
+	receivers := make([]arc59BatchReceiver, len(g.receivers), len(g.receivers)+1)
+	copy(receivers, g.receivers)
+	return arc59BatchGroup{
+		receivers:  append(receivers, receiver),
+		optIn:      g.optIn || optIn,
+		algoAmount: g.algoAmount + receiver.algoAmount,
+	}
+}
+
+// size returns the number of transactions of the group.
+func (g arc59BatchGroup) size() int {
+	size := 0
+	if g.algoAmount > 0 {
+		size++
+	}
+	if g.optIn {
+		size++
+	}
+	for _, receiver := range g.receivers {
+		size += receiver.txnCount()
+	}
+	return size
+}
+
+// plan splits the receivers of the batch into groups of at most types.MaxTxGroupSize transactions,
+// keeping the order of the receivers. The router is opted in to the asset by the first group which
+// sends through it, so later groups share that opt-in.
+func (b *ARC59SendBatch) plan() []arc59BatchGroup {
+	routerOptedIn := true
+	for _, receiver := range b.receivers {
+		if !receiver.info.ReceiverOptedIn && !receiver.info.RouterOptedIn {
+			routerOptedIn = false
+		}
+	}
+
+	var groups []arc59BatchGroup
+	var current arc59BatchGroup
+	for _, receiver := range b.receivers {
+		optIn := !receiver.info.ReceiverOptedIn && !routerOptedIn
+		next := current.with(receiver, optIn)
+		if next.size() > types.MaxTxGroupSize {
+			groups = append(groups, current)
+			next = arc59BatchGroup{}.with(receiver, optIn)
+		}
+		current = next
+		routerOptedIn = routerOptedIn || optIn
+	}
+	if len(current.receivers) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// arc59AppCallParams returns suggestedParams with the flat fee of an app call which pays for
+// itself and innerTxCount inner transactions, the way MakeARC59SendTxn computes it.
+func arc59AppCallParams(suggestedParams *SuggestedParams, innerTxCount int64) SuggestedParams {
+	params := *suggestedParams
+	params.FlatFee = true
+	if params.Fee == 0 {
+		params.Fee = (innerTxCount + 1) * 1000
+	} else {
+		params.Fee *= innerTxCount + 1
+	}
+	return params
+}
+
+// BuildGroups creates the transaction groups which send the asset to every receiver of the batch,
+// in the order the receivers were added. Every transaction is signed by the sender.
+//
+// Each group holds at most 16 transactions: a single payment to the router for the minimum balance
+// and claim funding of all its receivers, the router opt-in if the router is not opted in to the
+// asset yet, and then an asset transfer followed by an arc59_sendAsset call for each receiver.
+// Every arc59_sendAsset call references only its receiver, inbox, asset and box, so it stays within
+// the reference limits of an app call.
+func (b *ARC59SendBatch) BuildGroups(suggestedParams *SuggestedParams) (*TransactionGroupArray, error) {
+	if len(b.receivers) == 0 {
+		return nil, errors.New("batch has no receivers")
+	}
+
+	appAddress := crypto.GetApplicationAddress(uint64(b.appID)).String()
+	groups := make([]*TransactionSignerArray, 0)
+	for _, group := range b.plan() {
+		txns := BytesArray{values: [][]byte{}}
+
+		// 1) Payment TX
+		if group.algoAmount > 0 {
+			paymentAmount := MakeUint64(group.algoAmount)
+			paymentTxn, err := MakePaymentTxn(b.sender, appAddress, &paymentAmount, nil, "", suggestedParams, nil)
+			if err != nil {
+				return nil, err
+			}
+			txns.Append(paymentTxn)
+		}
+
+		// 2) ARC59 Opt in TX
+		if group.optIn {
+			appArguments, err := MakeAppArgumentsByteArrayWithAsset("arc59_optRouterIn(uint64)void", b.assetID)
+			if err != nil {
+				return nil, err
+			}
+			optInParams := arc59AppCallParams(suggestedParams, 1)
+			optInTxn, err := MakeApplicationNoOpTx(
+				b.appID,
+				&appArguments,
+				&StringArray{values: []string{appAddress}},
+				&Int64Array{values: []int64{}}, // empty array
+				&Int64Array{values: []int64{b.assetID}},
+				&AppBoxRefArray{value: []types.AppBoxReference{}}, // empty ref
+				&optInParams,
+				b.sender,
+				nil,
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			txns.Append(optInTxn)
+		}
+
+		for _, receiver := range group.receivers {
+			amount := receiver.amount
+			if receiver.info.ReceiverOptedIn {
+				assetTxn, err := MakeAssetTransferTxn(b.sender, receiver.address.String(), "", &amount, nil, suggestedParams, b.assetID, nil)
+				if err != nil {
+					return nil, err
+				}
+				txns.Append(assetTxn)
+				continue
+			}
+
+			// 3) Axfer
+			assetTxn, err := MakeAssetTransferTxn(b.sender, appAddress, "", &amount, nil, suggestedParams, b.assetID, nil)
+			if err != nil {
+				return nil, err
+			}
+			txns.Append(assetTxn)
+
+			// 4) sendAsset app call
+			appArguments, err := MakeAppArgumentsByteArrayWithAddressAndAmount(
+				"arc59_sendAsset(axfer,address,uint64)address",
+				receiver.address,
+				receiver.extraAlgoAmount,
+			)
+			if err != nil {
+				return nil, err
+			}
+			foreignAccounts := []string{receiver.address.String()}
+			if receiver.info.InboxAddress != "" {
+				foreignAccounts = append(foreignAccounts, receiver.info.InboxAddress)
+			}
+			boxRefArray := MakeAppBoxRefArray(uint64(b.appID), receiver.address)
+			appCallParams := arc59AppCallParams(suggestedParams, receiver.info.InnerTxCount)
+			if receiver.extraAlgoAmount > 0 {
+				appCallParams.Fee += 1000
+			}
+			appCallTxn, err := MakeApplicationNoOpTx(
+				b.appID,
+				&appArguments,
+				&StringArray{values: foreignAccounts},
+				&Int64Array{values: []int64{}}, // empty array
+				&Int64Array{values: []int64{b.assetID}},
+				&boxRefArray,
+				&appCallParams,
+				b.sender,
+				nil,
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			txns.Append(appCallTxn)
+		}
+
+		assignedTxns, err := AssignGroupID(&txns)
+		if err != nil {
+			return nil, err
+		}
+		signerItems := make([]TransactionSignerItem, assignedTxns.Length())
+		for i := range signerItems {
+			signerItems[i] = TransactionSignerItem{signer: b.sender, transaction: assignedTxns.Get(i)}
+		}
+		groups = append(groups, &TransactionSignerArray{signerItems: signerItems, transactions: assignedTxns})
+	}
+
+	return &TransactionGroupArray{groups: groups}, nil
+}
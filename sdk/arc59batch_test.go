@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeTestGroup(t *testing.T, group *TransactionSignerArray) []types.Transaction {
+	t.Helper()
+	txns, err := decodeTxns(&BytesArray{values: group.ExtractAssignedFlattenTxns()})
+	require.NoError(t, err)
+	for _, txn := range txns {
+		require.Equal(t, txns[0].Group, txn.Group)
+	}
+	return txns
+}
+
+func TestARC59SendBatch(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	appID := int64(643020148)
+	assetID := int64(31566704)
+	appAddress := crypto.GetApplicationAddress(uint64(appID))
+
+	batch, err := NewARC59SendBatch(sender, appID, assetID)
+	require.NoError(t, err)
+
+	// 9 receivers with a new inbox, and 1 receiver which is already opted in
+	for i := 0; i < 9; i++ {
+		amount := MakeUint64(uint64(100 + i))
+		info := &ARC59SendInfo{
+			InnerTxCount:               5,
+			MinimumBalanceRequirement:  &Uint64{Lower: 228100},
+			ReceiverAlgoNeededForClaim: &Uint64{},
+		}
+		if i == 0 {
+			info.ReceiverAlgoNeededForClaim = &Uint64{Lower: 201000}
+		}
+		require.NoError(t, batch.AddReceiver(types.Address{2, byte(i)}.String(), &amount, info))
+	}
+	optedIn := MakeUint64(7)
+	require.NoError(t, batch.AddReceiver(types.Address{3}.String(), &optedIn, &ARC59SendInfo{InnerTxCount: 1, ReceiverOptedIn: true}))
+	require.Equal(t, 10, batch.Length())
+
+	groups, err := batch.BuildGroups(makeTestARC59Params())
+	require.NoError(t, err)
+	require.Equal(t, 2, groups.Length())
+
+	// the first group has the payment, the router opt in and 7 sends
+	first := decodeTestGroup(t, groups.Get(0))
+	require.Len(t, first, 16)
+	require.Equal(t, types.PaymentTx, first[0].Type)
+	require.Equal(t, appAddress, first[0].Receiver)
+	require.Equal(t, types.MicroAlgos(7*228100+201000), first[0].Amount)
+	require.Equal(t, types.ApplicationCallTx, first[1].Type)
+	require.Equal(t, methodSelector(t, "arc59_optRouterIn(uint64)void"), first[1].ApplicationArgs[0])
+	require.Equal(t, types.MicroAlgos(2000), first[1].Fee)
+	for i := 0; i < 7; i++ {
+		axfer, appCall := first[2+2*i], first[3+2*i]
+		require.Equal(t, types.AssetTransferTx, axfer.Type)
+		require.Equal(t, appAddress, axfer.AssetReceiver)
+		require.Equal(t, uint64(100+i), axfer.AssetAmount)
+		require.Equal(t, methodSelector(t, "arc59_sendAsset(axfer,address,uint64)address"), appCall.ApplicationArgs[0])
+		require.Equal(t, []types.Address{{2, byte(i)}}, appCall.Accounts)
+		require.Equal(t, []types.AssetIndex{types.AssetIndex(assetID)}, appCall.ForeignAssets)
+		if i == 0 {
+			require.Equal(t, EncodeUIntAsBytes(201000), appCall.ApplicationArgs[2])
+			require.Equal(t, types.MicroAlgos(7000), appCall.Fee)
+		} else {
+			require.Equal(t, types.MicroAlgos(6000), appCall.Fee)
+		}
+	}
+
+	// the second group shares the router opt in of the first one
+	second := decodeTestGroup(t, groups.Get(1))
+	require.Len(t, second, 6)
+	require.Equal(t, types.MicroAlgos(2*228100), second[0].Amount)
+	require.Equal(t, types.AssetTransferTx, second[1].Type)
+	require.Equal(t, types.ApplicationCallTx, second[2].Type)
+	require.Equal(t, types.Address{3}, second[5].AssetReceiver)
+	require.Equal(t, uint64(7), second[5].AssetAmount)
+	require.NotEqual(t, first[0].Group, second[0].Group)
+
+	for i := 0; i < groups.Length(); i++ {
+		group := groups.Get(i)
+		for j := 0; j < group.Length(); j++ {
+			require.Equal(t, sender, group.GetSigner(j))
+			require.Equal(t, group.GetTxn(j), group.GetTxnFromSigner(j))
+		}
+	}
+}
+
+func TestARC59SendBatchOptedInRouter(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	batch, err := NewARC59SendBatch(sender, 1, 2)
+	require.NoError(t, err)
+
+	amount := MakeUint64(1)
+	info := &ARC59SendInfo{
+		InnerTxCount:               1,
+		MinimumBalanceRequirement:  &Uint64{},
+		RouterOptedIn:              true,
+		ReceiverAlgoNeededForClaim: &Uint64{},
+		InboxAddress:               types.Address{4}.String(),
+	}
+	require.NoError(t, batch.AddReceiver(types.Address{2}.String(), &amount, info))
+
+	// no payment and no opt in are needed
+	groups, err := batch.BuildGroups(makeTestARC59Params())
+	require.NoError(t, err)
+	require.Equal(t, 1, groups.Length())
+	txns := decodeTestGroup(t, groups.Get(0))
+	require.Len(t, txns, 2)
+	require.Equal(t, []types.Address{{2}, {4}}, txns[1].Accounts)
+
+	require.ErrorContains(t, batch.AddReceiver(types.Address{2}.String(), &amount, info), "already added")
+	require.Error(t, batch.AddReceiver("invalid", &amount, info))
+	require.Error(t, batch.AddReceiver(types.Address{5}.String(), &amount, nil))
+	require.Error(t, batch.AddReceiver(types.Address{5}.String(), &Uint64{Upper: -1}, info))
+
+	empty, err := NewARC59SendBatch(sender, 1, 2)
+	require.NoError(t, err)
+	_, err = empty.BuildGroups(makeTestARC59Params())
+	require.ErrorContains(t, err, "no receivers")
+	_, err = NewARC59SendBatch("invalid", 1, 2)
+	require.Error(t, err)
+}
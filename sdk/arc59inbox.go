@@ -0,0 +1,475 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// assetOptInMinBalance is the increase of an account's minimum balance for each asset it holds.
+const assetOptInMinBalance = 100000
+
+// AlgodAccountReader reads account information from an algod node. Applications implement it over
+// their own networking stack.
+type AlgodAccountReader interface {
+	// AccountInformation returns the JSON response of the algod endpoint /v2/accounts/{address}.
+	AccountInformation(address string) (string, error)
+}
+
+// ARC59Inbox is the state of a receiver's ARC-59 inbox account: the assets waiting to be claimed
+// and the ALGO which can be claimed with arc59_claimAlgo.
+type ARC59Inbox struct {
+	address       string
+	claimableAlgo uint64
+	holdings      []models.AssetHolding
+}
+
+// ParseARC59Inbox creates an ARC59Inbox from the account information of the inbox account, as
+// returned in JSON by the algod endpoint /v2/accounts/{address}.
+func ParseARC59Inbox(accountInformationJSON string) (*ARC59Inbox, error) {
+	var account models.Account
+	err := json.Unmarshal([]byte(accountInformationJSON), &account)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode account information: %w", err)
+	}
+	if _, err = types.DecodeAddress(account.Address); err != nil {
+		return nil, fmt.Errorf("invalid inbox address: %w", err)
+	}
+
+	inbox := &ARC59Inbox{address: account.Address}
+	if account.Amount > account.MinBalance {
+		inbox.claimableAlgo = account.Amount - account.MinBalance
+	}
+	for _, holding := range account.Assets {
+		if holding.Deleted {
+			continue
+		}
+		inbox.holdings = append(inbox.holdings, holding)
+	}
+	sort.Slice(inbox.holdings, func(i, j int) bool {
+		return inbox.holdings[i].AssetId < inbox.holdings[j].AssetId
+	})
+	return inbox, nil
+}
+
+// GetARC59Inbox reads the state of the inbox account inboxAddress with reader.
+func GetARC59Inbox(reader AlgodAccountReader, inboxAddress string) (*ARC59Inbox, error) {
+	accountInformation, err := reader.AccountInformation(inboxAddress)
+	if err != nil {
+		return nil, err
+	}
+	inbox, err := ParseARC59Inbox(accountInformation)
+	if err != nil {
+		return nil, err
+	}
+	if inbox.address != inboxAddress {
+		return nil, fmt.Errorf("account information is for %s, not %s", inbox.address, inboxAddress)
+	}
+	return inbox, nil
+}
+
+// Address returns the address of the inbox account.
+func (inbox *ARC59Inbox) Address() string {
+	return inbox.address
+}
+
+// ClaimableAlgo returns the amount of microAlgos above the minimum balance of the inbox account.
+func (inbox *ARC59Inbox) ClaimableAlgo() *Uint64 {
+	claimable := MakeUint64(inbox.claimableAlgo)
+	return &claimable
+}
+
+// AssetIDs returns the IDs of the assets held by the inbox account, in increasing order.
+func (inbox *ARC59Inbox) AssetIDs() *Int64Array {
+	ids := make([]int64, len(inbox.holdings))
+	for i, holding := range inbox.holdings {
+		ids[i] = int64(holding.AssetId)
+	}
+	return &Int64Array{values: ids}
+}
+
+// holding returns the inbox account's holding of the asset assetID.
+func (inbox *ARC59Inbox) holding(assetID int64) (models.AssetHolding, error) {
+	for _, holding := range inbox.holdings {
+		if assetID >= 0 && holding.AssetId == uint64(assetID) {
+			return holding, nil
+		}
+	}
+	return models.AssetHolding{}, fmt.Errorf("asset %d is not in the inbox", assetID)
+}
+
+// AssetAmount returns the amount of the asset assetID held by the inbox account.
+func (inbox *ARC59Inbox) AssetAmount(assetID int64) (*Uint64, error) {
+	holding, err := inbox.holding(assetID)
+	if err != nil {
+		return nil, err
+	}
+	amount := MakeUint64(holding.Amount)
+	return &amount, nil
+}
+
+// IsAssetFrozen returns true if the inbox account's holding of the asset assetID is frozen, in
+// which case it can be neither claimed nor rejected.
+func (inbox *ARC59Inbox) IsAssetFrozen(assetID int64) (bool, error) {
+	holding, err := inbox.holding(assetID)
+	if err != nil {
+		return false, err
+	}
+	return holding.IsFrozen, nil
+}
+
+// arc59InboxAction is a claim or a rejection of one asset of an inbox.
+type arc59InboxAction struct {
+	holding models.AssetHolding
+	reject  bool
+	// optIn is true if the receiver must opt in to the asset to claim it.
+	optIn bool
+	// creator is the address the asset is returned to when it is rejected.
+	creator string
+}
+
+// feeUnits returns the number of minimum fees which pay for the action's transactions and their
+// inner transactions.
+func (a arc59InboxAction) feeUnits() int64 {
+	// the app call, and the inner asset transfer and payment of the inbox
+	units := int64(3)
+	if a.optIn {
+		units++
+	}
+	return units
+}
+
+// txnCount returns the number of transactions of the action.
+func (a arc59InboxAction) txnCount() int {
+	if a.optIn {
+		return 2
+	}
+	return 1
+}
+
+// ARC59InboxBatch claims and rejects several assets of a receiver's ARC-59 inbox at once, packing
+// the app calls into as few transaction groups as possible.
+type ARC59InboxBatch struct {
+	receiver string
+	appID    int64
+	inbox    *ARC59Inbox
+	actions  []arc59InboxAction
+}
+
+// NewARC59InboxBatch creates an empty batch of claims and rejections from the inbox of receiver in
+// the ARC-59 router application appID.
+func NewARC59InboxBatch(receiver string, appID int64, inbox *ARC59Inbox) (*ARC59InboxBatch, error) {
+	if _, err := types.DecodeAddress(receiver); err != nil {
+		return nil, err
+	}
+	if appID < 0 {
+		return nil, errNegativeArgument
+	}
+	if inbox == nil {
+		return nil, errors.New("missing ARC-59 inbox")
+	}
+	return &ARC59InboxBatch{receiver: receiver, appID: appID, inbox: inbox}, nil
+}
+
+func (b *ARC59InboxBatch) add(assetID int64, action arc59InboxAction) error {
+	holding, err := b.inbox.holding(assetID)
+	if err != nil {
+		return err
+	}
+	if holding.IsFrozen {
+		return fmt.Errorf("asset %d is frozen in the inbox", assetID)
+	}
+	for _, existing := range b.actions {
+		if existing.holding.AssetId == holding.AssetId {
+			return fmt.Errorf("asset %d was already added to the batch", assetID)
+		}
+	}
+	action.holding = holding
+	b.actions = append(b.actions, action)
+	return nil
+}
+
+// Claim adds a claim of the asset assetID to the batch. If receiverOptedIn is false, the receiver
+// is opted in to the asset before claiming it.
+func (b *ARC59InboxBatch) Claim(assetID int64, receiverOptedIn bool) error {
+	return b.add(assetID, arc59InboxAction{optIn: !receiverOptedIn})
+}
+
+// ClaimAll adds a claim of every asset of the inbox which is not frozen and not in the batch yet.
+// receiverOptedInAssets are the IDs of the assets the receiver is already opted in to.
+func (b *ARC59InboxBatch) ClaimAll(receiverOptedInAssets *Int64Array) error {
+	optedIn := make(map[int64]bool)
+	if receiverOptedInAssets != nil {
+		for _, assetID := range receiverOptedInAssets.Extract() {
+			optedIn[assetID] = true
+		}
+	}
+	added := make(map[uint64]bool)
+	for _, action := range b.actions {
+		added[action.holding.AssetId] = true
+	}
+	for _, holding := range b.inbox.holdings {
+		if holding.IsFrozen || added[holding.AssetId] {
+			continue
+		}
+		assetID := int64(holding.AssetId)
+		if err := b.Claim(assetID, optedIn[assetID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reject adds a rejection of the asset assetID to the batch. The asset is returned to its creator.
+func (b *ARC59InboxBatch) Reject(assetID int64, creator string) error {
+	if _, err := types.DecodeAddress(creator); err != nil {
+		return err
+	}
+	return b.add(assetID, arc59InboxAction{reject: true, creator: creator})
+}
+
+// Length returns the number of claims and rejections in the batch.
+func (b *ARC59InboxBatch) Length() int {
+	return len(b.actions)
+}
+
+// claimsAlgo returns true if the batch claims the ALGO of the inbox.
+func (b *ARC59InboxBatch) claimsAlgo() bool {
+	return b.inbox.claimableAlgo > 0
+}
+
+// plan splits the actions of the batch into groups of at most types.MaxTxGroupSize transactions,
+// keeping their order. The first group also claims the ALGO of the inbox, so that the receiver can
+// afford the opt-ins of the claims.
+func (b *ARC59InboxBatch) plan() [][]arc59InboxAction {
+	var groups [][]arc59InboxAction
+	var current []arc59InboxAction
+	size := 0
+	if b.claimsAlgo() {
+		size = 1
+	}
+	for _, action := range b.actions {
+		if size+action.txnCount() > types.MaxTxGroupSize {
+			groups = append(groups, current)
+			current = nil
+			size = 0
+		}
+		current = append(current, action)
+		size += action.txnCount()
+	}
+	if len(current) > 0 || len(groups) == 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// BuildGroups creates the transaction groups which claim the ALGO of the inbox and claim or reject
+// every asset of the batch, in the order they were added. Every transaction is signed by the
+// receiver.
+//
+// Fees are pooled: the last app call of each group pays the minimum fee of every transaction and
+// inner transaction of its group, and the other transactions have no fee. A non-zero Fee of
+// suggestedParams is used as the minimum fee, as in MakeARC59ClaimTxn.
+func (b *ARC59InboxBatch) BuildGroups(suggestedParams *SuggestedParams) (*TransactionGroupArray, error) {
+	if len(b.actions) == 0 && !b.claimsAlgo() {
+		return nil, errors.New("batch has nothing to claim or reject")
+	}
+
+	minFee := suggestedParams.Fee
+	if minFee == 0 {
+		minFee = 1000
+	}
+	zeroFeeParams := *suggestedParams
+	zeroFeeParams.FlatFee = true
+	zeroFeeParams.Fee = 0
+
+	decodedReceiver, err := DecodeAddress(b.receiver)
+	if err != nil {
+		return nil, err
+	}
+	boxRefArray := MakeAppBoxRefArray(uint64(b.appID), decodedReceiver)
+	inboxAccounts := []string{b.inbox.address}
+
+	groups := make([]*TransactionSignerArray, 0)
+	for i, actions := range b.plan() {
+		claimAlgo := i == 0 && b.claimsAlgo()
+
+		// the last app call of the group pays the fees of the whole group
+		var feeUnits int64
+		if claimAlgo {
+			// the app call and the inner payment of the inbox
+			feeUnits += 2
+		}
+		for _, action := range actions {
+			feeUnits += action.feeUnits()
+		}
+		feePayerParams := zeroFeeParams
+		feePayerParams.Fee = feeUnits * minFee
+		appCallParams := func(last bool) *SuggestedParams {
+			if last {
+				return &feePayerParams
+			}
+			return &zeroFeeParams
+		}
+
+		txns := BytesArray{values: [][]byte{}}
+
+		// 1) claim ALGO
+		if claimAlgo {
+			methodNameBytes, err := hex.DecodeString(MethodName("arc59_claimAlgo()void"))
+			if err != nil {
+				return nil, err
+			}
+			claimAlgoBoxRefArray := AppBoxRefArray{value: []types.AppBoxReference{{Name: decodedReceiver[:]}}}
+			claimAlgoTxn, err := MakeApplicationNoOpTx(
+				b.appID,
+				&BytesArray{values: [][]byte{methodNameBytes}},
+				&StringArray{values: inboxAccounts},
+				&Int64Array{values: []int64{}}, // empty array
+				&Int64Array{values: []int64{}}, // empty array
+				&claimAlgoBoxRefArray,
+				appCallParams(len(actions) == 0),
+				b.receiver,
+				nil,
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			txns.Append(claimAlgoTxn)
+		}
+
+		for j, action := range actions {
+			assetID := int64(action.holding.AssetId)
+
+			// 2) opt in if necessary
+			if action.optIn {
+				optInAmount := MakeUint64(0)
+				optInTxn, err := MakeAssetTransferTxn(b.receiver, b.receiver, "", &optInAmount, nil, &zeroFeeParams, assetID, nil)
+				if err != nil {
+					return nil, err
+				}
+				txns.Append(optInTxn)
+			}
+
+			// 3) claim or reject call
+			method := "arc59_claim(uint64)void"
+			accounts := inboxAccounts
+			if action.reject {
+				method = "arc59_reject(uint64)void"
+				accounts = []string{b.inbox.address, action.creator}
+			}
+			appArguments, err := MakeAppArgumentsByteArrayWithAsset(method, assetID)
+			if err != nil {
+				return nil, err
+			}
+			appCallTxn, err := MakeApplicationNoOpTx(
+				b.appID,
+				&appArguments,
+				&StringArray{values: accounts},
+				&Int64Array{values: []int64{}}, // empty array
+				&Int64Array{values: []int64{assetID}},
+				&boxRefArray,
+				appCallParams(j == len(actions)-1),
+				b.receiver,
+				nil,
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			txns.Append(appCallTxn)
+		}
+
+		assignedTxns, err := AssignGroupID(&txns)
+		if err != nil {
+			return nil, err
+		}
+		signerItems := make([]TransactionSignerItem, assignedTxns.Length())
+		for j := range signerItems {
+			signerItems[j] = TransactionSignerItem{signer: b.receiver, transaction: assignedTxns.Get(j)}
+		}
+		groups = append(groups, &TransactionSignerArray{signerItems: signerItems, transactions: assignedTxns})
+	}
+
+	return &TransactionGroupArray{groups: groups}, nil
+}
+
+// arc59InboxAssetEffect is the effect of claiming or rejecting one asset of an inbox, as reported
+// by ARC59InboxBatch.EffectsJSON.
+type arc59InboxAssetEffect struct {
+	AssetID uint64 `json:"assetId"`
+	Action  string `json:"action"`
+	Amount  uint64 `json:"amount"`
+	OptIn   bool   `json:"optIn"`
+	// ReceiverMinBalanceIncrease is the increase of the receiver's minimum balance for opting in.
+	ReceiverMinBalanceIncrease uint64 `json:"receiverMinBalanceIncrease"`
+	// InboxMinBalanceReleased is the minimum balance of the inbox's holding, which is sent to the
+	// receiver when the holding is closed.
+	InboxMinBalanceReleased uint64 `json:"inboxMinBalanceReleased"`
+	Fee                     uint64 `json:"fee"`
+}
+
+type arc59InboxEffects struct {
+	Assets      []arc59InboxAssetEffect `json:"assets"`
+	ClaimedAlgo uint64                  `json:"claimedAlgo"`
+	TotalFee    uint64                  `json:"totalFee"`
+	// NetAlgoChange is the change of the receiver's balance minus the change of its minimum
+	// balance, which is the change of the ALGO the receiver can spend.
+	NetAlgoChange int64 `json:"netAlgoChange"`
+}
+
+// EffectsJSON describes what the groups made by BuildGroups with suggestedParams do to the
+// receiver's ALGO, so that the cost of claiming can be shown before signing. The result is a JSON
+// object of the form
+//
+//	{"assets":[{"assetId":1,"action":"claim","amount":5,"optIn":true,
+//	            "receiverMinBalanceIncrease":100000,"inboxMinBalanceReleased":100000,"fee":4000}],
+//	 "claimedAlgo":20000,"totalFee":6000,"netAlgoChange":14000}
+//
+// where amounts are in microAlgos and netAlgoChange is the change of the receiver's spendable
+// ALGO, which may be negative.
+func (b *ARC59InboxBatch) EffectsJSON(suggestedParams *SuggestedParams) (string, error) {
+	minFee := suggestedParams.Fee
+	if minFee == 0 {
+		minFee = 1000
+	}
+
+	effects := arc59InboxEffects{Assets: []arc59InboxAssetEffect{}}
+	if b.claimsAlgo() {
+		effects.ClaimedAlgo = b.inbox.claimableAlgo
+		effects.TotalFee = uint64(2 * minFee)
+	}
+	net := int64(effects.ClaimedAlgo)
+	for _, action := range b.actions {
+		effect := arc59InboxAssetEffect{
+			AssetID:                 action.holding.AssetId,
+			Action:                  "claim",
+			Amount:                  action.holding.Amount,
+			OptIn:                   action.optIn,
+			InboxMinBalanceReleased: assetOptInMinBalance,
+			Fee:                     uint64(action.feeUnits() * minFee),
+		}
+		if action.reject {
+			effect.Action = "reject"
+		}
+		if action.optIn {
+			effect.ReceiverMinBalanceIncrease = assetOptInMinBalance
+		}
+		effects.Assets = append(effects.Assets, effect)
+		effects.TotalFee += effect.Fee
+		net += int64(effect.InboxMinBalanceReleased) - int64(effect.ReceiverMinBalanceIncrease)
+	}
+	effects.NetAlgoChange = net - int64(effects.TotalFee)
+
+	encoded, err := json.Marshal(effects)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,172 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testAccountReader map[string]string
+
+func (r testAccountReader) AccountInformation(address string) (string, error) {
+	info, ok := r[address]
+	if !ok {
+		return "", errors.New("account not found")
+	}
+	return info, nil
+}
+
+func makeTestInboxJSON(address string, amount, minBalance uint64, assets string) string {
+	return fmt.Sprintf(`{"address":"%s","amount":%d,"min-balance":%d,"assets":[%s],"status":"Offline"}`,
+		address, amount, minBalance, assets)
+}
+
+func TestParseARC59Inbox(t *testing.T) {
+	t.Parallel()
+	inboxAddress := types.Address{9}.String()
+	reader := testAccountReader{inboxAddress: makeTestInboxJSON(inboxAddress, 420000, 400000,
+		`{"asset-id":20,"amount":5,"is-frozen":false},{"asset-id":10,"amount":0,"is-frozen":true},{"asset-id":30,"amount":1,"deleted":true}`)}
+
+	inbox, err := GetARC59Inbox(reader, inboxAddress)
+	require.NoError(t, err)
+	require.Equal(t, inboxAddress, inbox.Address())
+	require.Equal(t, MakeUint64(20000), *inbox.ClaimableAlgo())
+	require.Equal(t, []int64{10, 20}, inbox.AssetIDs().Extract())
+	amount, err := inbox.AssetAmount(20)
+	require.NoError(t, err)
+	require.Equal(t, MakeUint64(5), *amount)
+	frozen, err := inbox.IsAssetFrozen(10)
+	require.NoError(t, err)
+	require.True(t, frozen)
+	_, err = inbox.AssetAmount(30)
+	require.ErrorContains(t, err, "asset 30 is not in the inbox")
+
+	_, err = GetARC59Inbox(reader, types.Address{8}.String())
+	require.ErrorContains(t, err, "account not found")
+	reader[types.Address{8}.String()] = reader[inboxAddress]
+	_, err = GetARC59Inbox(reader, types.Address{8}.String())
+	require.ErrorContains(t, err, "account information is for")
+	_, err = ParseARC59Inbox(`{"address":"invalid"}`)
+	require.Error(t, err)
+
+	// accounts at their minimum balance have no ALGO to claim
+	inbox, err = ParseARC59Inbox(makeTestInboxJSON(inboxAddress, 100000, 200000, ""))
+	require.NoError(t, err)
+	require.Equal(t, MakeUint64(0), *inbox.ClaimableAlgo())
+	require.Equal(t, 0, inbox.AssetIDs().Length())
+}
+
+func TestARC59InboxBatch(t *testing.T) {
+	t.Parallel()
+	receiver := types.Address{1}
+	creator := types.Address{2}.String()
+	inboxAddress := types.Address{9}.String()
+	appID := int64(643020148)
+
+	var assets []string
+	for i := 1; i <= 12; i++ {
+		assets = append(assets, fmt.Sprintf(`{"asset-id":%d,"amount":%d,"is-frozen":%t}`, i, 10*i, i == 12))
+	}
+	inbox, err := ParseARC59Inbox(makeTestInboxJSON(inboxAddress, 1420000, 1400000, strings.Join(assets, ",")))
+	require.NoError(t, err)
+
+	batch, err := NewARC59InboxBatch(receiver.String(), appID, inbox)
+	require.NoError(t, err)
+	require.NoError(t, batch.Reject(3, creator))
+	require.NoError(t, batch.ClaimAll(&Int64Array{values: []int64{1, 2}}))
+	require.Equal(t, 11, batch.Length())
+	require.ErrorContains(t, batch.Claim(3, true), "already added")
+	require.ErrorContains(t, batch.Claim(12, true), "frozen")
+	require.ErrorContains(t, batch.Claim(13, true), "not in the inbox")
+
+	groups, err := batch.BuildGroups(makeTestARC59Params())
+	require.NoError(t, err)
+	require.Equal(t, 2, groups.Length())
+
+	// claimAlgo, reject 3, claim 1 and 2, then opt in and claim 4 to 9
+	first := decodeTestGroup(t, groups.Get(0))
+	require.Len(t, first, 16)
+	require.Equal(t, [][]byte{methodSelector(t, "arc59_claimAlgo()void")}, first[0].ApplicationArgs)
+	require.Equal(t, methodSelector(t, "arc59_reject(uint64)void"), first[1].ApplicationArgs[0])
+	require.Equal(t, []types.Address{mustDecodeAddress(t, inboxAddress), mustDecodeAddress(t, creator)}, first[1].Accounts)
+	require.Equal(t, EncodeIntAsBytes(1), first[2].ApplicationArgs[1])
+	require.Equal(t, EncodeIntAsBytes(2), first[3].ApplicationArgs[1])
+	for i := 0; i < 6; i++ {
+		optIn, claim := first[4+2*i], first[5+2*i]
+		require.Equal(t, types.AssetTransferTx, optIn.Type)
+		require.Equal(t, receiver, optIn.AssetReceiver)
+		require.Equal(t, types.AssetIndex(4+i), optIn.XferAsset)
+		require.Equal(t, methodSelector(t, "arc59_claim(uint64)void"), claim.ApplicationArgs[0])
+		require.Equal(t, []types.Address{mustDecodeAddress(t, inboxAddress)}, claim.Accounts)
+		require.Equal(t, []types.AssetIndex{types.AssetIndex(4 + i)}, claim.ForeignAssets)
+	}
+
+	// the fees are pooled on the last app call of each group
+	for _, txn := range first[:15] {
+		require.Equal(t, types.MicroAlgos(0), txn.Fee)
+	}
+	require.Equal(t, types.MicroAlgos((2+3*3+6*4)*1000), first[15].Fee)
+
+	second := decodeTestGroup(t, groups.Get(1))
+	require.Len(t, second, 4)
+	require.Equal(t, types.MicroAlgos(2*4*1000), second[3].Fee)
+	require.Equal(t, EncodeIntAsBytes(11), second[3].ApplicationArgs[1])
+
+	var effects arc59InboxEffects
+	encoded, err := batch.EffectsJSON(makeTestARC59Params())
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(encoded), &effects))
+	require.Len(t, effects.Assets, 11)
+	require.Equal(t, arc59InboxAssetEffect{
+		AssetID:                 3,
+		Action:                  "reject",
+		Amount:                  30,
+		InboxMinBalanceReleased: 100000,
+		Fee:                     3000,
+	}, effects.Assets[0])
+	require.Equal(t, arc59InboxAssetEffect{
+		AssetID:                    4,
+		Action:                     "claim",
+		Amount:                     40,
+		OptIn:                      true,
+		ReceiverMinBalanceIncrease: 100000,
+		InboxMinBalanceReleased:    100000,
+		Fee:                        4000,
+	}, effects.Assets[3])
+	require.Equal(t, uint64(20000), effects.ClaimedAlgo)
+	require.Equal(t, uint64(43000), effects.TotalFee)
+	require.Equal(t, int64(20000+3*100000-43000), effects.NetAlgoChange)
+}
+
+func TestARC59InboxBatchClaimAlgoOnly(t *testing.T) {
+	t.Parallel()
+	receiver := types.Address{1}.String()
+	inbox, err := ParseARC59Inbox(makeTestInboxJSON(types.Address{9}.String(), 300000, 100000, ""))
+	require.NoError(t, err)
+	batch, err := NewARC59InboxBatch(receiver, 1, inbox)
+	require.NoError(t, err)
+
+	params := makeTestARC59Params()
+	params.Fee = 2000
+	groups, err := batch.BuildGroups(params)
+	require.NoError(t, err)
+	require.Equal(t, 1, groups.Length())
+	txns := decodeTestGroup(t, groups.Get(0))
+	require.Len(t, txns, 1)
+	require.Equal(t, types.MicroAlgos(4000), txns[0].Fee)
+	require.Equal(t, receiver, groups.Get(0).GetSigner(0))
+
+	empty, err := ParseARC59Inbox(makeTestInboxJSON(types.Address{9}.String(), 100000, 100000, ""))
+	require.NoError(t, err)
+	batch, err = NewARC59InboxBatch(receiver, 1, empty)
+	require.NoError(t, err)
+	_, err = batch.BuildGroups(params)
+	require.ErrorContains(t, err, "nothing to claim or reject")
+	_, err = NewARC59InboxBatch(receiver, 1, nil)
+	require.Error(t, err)
+}
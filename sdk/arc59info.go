@@ -0,0 +1,260 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+const (
+	arc59GetSendAssetInfoMethod = "arc59_getSendAssetInfo(address,uint64)(uint64,uint64,bool,bool,uint64)"
+	arc59GetInboxMethod         = "arc59_getInbox(address)address"
+)
+
+// AlgodSimulator simulates transaction groups with an algod node. Applications implement it over
+// their own networking stack, and tests can implement it with recorded responses.
+type AlgodSimulator interface {
+	// Simulate posts the msgpack encoded simulate request to the algod endpoint
+	// /v2/transactions/simulate?format=msgpack and returns the msgpack encoded response.
+	Simulate(encodedRequest []byte) ([]byte, error)
+}
+
+// ARC59SendInfo is the information the ARC-59 router application returns about sending an asset to
+// a receiver. Its fields are the arguments of MakeARC59SendTxn which depend on the state of the
+// receiver and the router.
+type ARC59SendInfo struct {
+	// InnerTxCount is the number of inner transactions the arc59_sendAsset call makes.
+	InnerTxCount int64
+	// MinimumBalanceRequirement is the amount of ALGO the sender must pay to the router to fund the
+	// receiver's inbox.
+	MinimumBalanceRequirement *Uint64
+	// RouterOptedIn is true if the router is already opted in to the asset.
+	RouterOptedIn bool
+	// ReceiverOptedIn is true if the receiver is already opted in to the asset, in which case the
+	// asset can be sent to the receiver directly.
+	ReceiverOptedIn bool
+	// ReceiverAlgoNeededForClaim is the amount of ALGO the receiver lacks to claim the asset from
+	// their inbox. It is passed as the extraAlgoAmount of MakeARC59SendTxn.
+	ReceiverAlgoNeededForClaim *Uint64
+	// InboxAddress is the address of the receiver's inbox, or an empty string if the receiver does
+	// not have an inbox yet.
+	InboxAddress string
+}
+
+// MakeARC59SendInfoSimulateRequest creates the msgpack encoded simulate request which reads the
+// information needed to send the asset assetID to receiver through the ARC-59 router application
+// appID. The read-only calls are sent from sender, which must be able to pay their fees.
+//
+// Submit the request with an AlgodSimulator and decode the response with
+// DecodeARC59SendInfoSimulateResponse, or use GetARC59SendInfo to do both.
+func MakeARC59SendInfoSimulateRequest(sender, receiver string, appID, assetID int64, suggestedParams *SuggestedParams) ([]byte, error) {
+	decodedReceiver, err := DecodeAddress(receiver)
+	if err != nil {
+		return nil, err
+	}
+	if appID < 0 || assetID < 0 {
+		return nil, errNegativeArgument
+	}
+
+	sendAssetInfoSelector, err := hex.DecodeString(MethodName(arc59GetSendAssetInfoMethod))
+	if err != nil {
+		return nil, err
+	}
+	sendAssetInfoArgs := BytesArray{values: [][]byte{sendAssetInfoSelector, decodedReceiver[:], EncodeIntAsBytes(assetID)}}
+
+	getInboxSelector, err := hex.DecodeString(MethodName(arc59GetInboxMethod))
+	if err != nil {
+		return nil, err
+	}
+	getInboxArgs := BytesArray{values: [][]byte{getInboxSelector, decodedReceiver[:]}}
+
+	boxRefArray := MakeAppBoxRefArray(uint64(appID), decodedReceiver)
+	sendAssetInfoTxn, err := MakeApplicationNoOpTx(
+		appID,
+		&sendAssetInfoArgs,
+		&StringArray{values: []string{receiver}},
+		&Int64Array{values: []int64{}}, // empty array
+		&Int64Array{values: []int64{assetID}},
+		&boxRefArray,
+		suggestedParams,
+		sender,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	getInboxTxn, err := MakeApplicationNoOpTx(
+		appID,
+		&getInboxArgs,
+		&StringArray{values: []string{}},
+		&Int64Array{values: []int64{}}, // empty array
+		&Int64Array{values: []int64{}}, // empty array
+		&boxRefArray,
+		suggestedParams,
+		sender,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := AssignGroupID(&BytesArray{values: [][]byte{sendAssetInfoTxn, getInboxTxn}})
+	if err != nil {
+		return nil, err
+	}
+	txns, err := decodeTxns(group)
+	if err != nil {
+		return nil, err
+	}
+
+	request := models.SimulateRequest{
+		AllowEmptySignatures:  true,
+		AllowUnnamedResources: true,
+		TxnGroups: []models.SimulateRequestTransactionGroup{
+			{Txns: []types.SignedTxn{{Txn: txns[0]}, {Txn: txns[1]}}},
+		},
+	}
+	return msgpack.Encode(&request), nil
+}
+
+// arc59ReturnValue decodes the ABI return value of a call to the ARC-59 router from the simulated
+// result of the call.
+func arc59ReturnValue(result models.SimulateTransactionResult, typeString string) (interface{}, error) {
+	logs := result.TxnResult.Logs
+	if len(logs) == 0 || !bytes.HasPrefix(logs[len(logs)-1], abiReturnPrefix) {
+		return nil, errors.New("log entry does not contain an ABI return value")
+	}
+	returnType, err := abi.TypeOf(typeString)
+	if err != nil {
+		return nil, err
+	}
+	return returnType.Decode(logs[len(logs)-1][len(abiReturnPrefix):])
+}
+
+// DecodeARC59SendInfoSimulateResponse decodes the msgpack encoded response to a simulate request
+// made by MakeARC59SendInfoSimulateRequest.
+func DecodeARC59SendInfoSimulateResponse(encodedResponse []byte) (*ARC59SendInfo, error) {
+	var response models.SimulateResponse
+	err := msgpack.Decode(encodedResponse, &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.TxnGroups) != 1 {
+		return nil, fmt.Errorf("expected 1 simulated transaction group, got %d", len(response.TxnGroups))
+	}
+	group := response.TxnGroups[0]
+	if group.FailureMessage != "" {
+		return nil, fmt.Errorf("simulation failed: %s", group.FailureMessage)
+	}
+	if len(group.TxnResults) != 2 {
+		return nil, fmt.Errorf("expected 2 simulated transactions, got %d", len(group.TxnResults))
+	}
+
+	decoded, err := arc59ReturnValue(group.TxnResults[0], "(uint64,uint64,bool,bool,uint64)")
+	if err != nil {
+		return nil, fmt.Errorf("could not decode send asset info: %w", err)
+	}
+	values := decoded.([]interface{})
+	innerTxCount := values[0].(uint64)
+	if innerTxCount > math.MaxInt64 {
+		return nil, fmt.Errorf("invalid inner transaction count %d", innerTxCount)
+	}
+	mbr := MakeUint64(values[1].(uint64))
+	algoNeeded := MakeUint64(values[4].(uint64))
+
+	decoded, err = arc59ReturnValue(group.TxnResults[1], "address")
+	if err != nil {
+		return nil, fmt.Errorf("could not decode inbox address: %w", err)
+	}
+	var inbox types.Address
+	copy(inbox[:], decoded.([]byte))
+	inboxAddress := ""
+	if !inbox.IsZero() {
+		inboxAddress = inbox.String()
+	}
+
+	return &ARC59SendInfo{
+		InnerTxCount:               int64(innerTxCount),
+		MinimumBalanceRequirement:  &mbr,
+		RouterOptedIn:              values[2].(bool),
+		ReceiverOptedIn:            values[3].(bool),
+		ReceiverAlgoNeededForClaim: &algoNeeded,
+		InboxAddress:               inboxAddress,
+	}, nil
+}
+
+// GetARC59SendInfo reads the information needed to send the asset assetID to receiver through the
+// ARC-59 router application appID by simulating read-only calls with simulator. See
+// MakeARC59SendInfoSimulateRequest for the meaning of the parameters.
+func GetARC59SendInfo(simulator AlgodSimulator, sender, receiver string, appID, assetID int64, suggestedParams *SuggestedParams) (*ARC59SendInfo, error) {
+	request, err := MakeARC59SendInfoSimulateRequest(sender, receiver, appID, assetID, suggestedParams)
+	if err != nil {
+		return nil, err
+	}
+	response, err := simulator.Simulate(request)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeARC59SendInfoSimulateResponse(response)
+}
+
+// MakeARC59SendTxnWithInfo creates the transactions for sending an asset with the ARC-59 protocol,
+// like MakeARC59SendTxn, taking the state of the receiver and the router from info.
+func MakeARC59SendTxnWithInfo(sender, receiver string, amount *Uint64, appID, assetID int64, suggestedParams *SuggestedParams, info *ARC59SendInfo, options *TransactionOptions) (*BytesArray, error) {
+	if info == nil {
+		return nil, errors.New("missing ARC-59 send info")
+	}
+	if appID < 0 {
+		return nil, errNegativeArgument
+	}
+	appAddress := crypto.GetApplicationAddress(uint64(appID))
+	return MakeARC59SendTxn(
+		sender,
+		receiver,
+		appAddress.String(),
+		info.InboxAddress,
+		amount,
+		info.MinimumBalanceRequirement,
+		info.InnerTxCount,
+		appID,
+		assetID,
+		suggestedParams,
+		info.RouterOptedIn,
+		info.ReceiverAlgoNeededForClaim,
+		options,
+	)
+}
+
+// EstimateARC59SendCost returns the total amount of microAlgos the sender spends to send an asset
+// with the ARC-59 protocol: the fees of every transaction of the group made by
+// MakeARC59SendTxnWithInfo, plus the minimum balance and claim funding paid to the router.
+func EstimateARC59SendCost(sender, receiver string, amount *Uint64, appID, assetID int64, suggestedParams *SuggestedParams, info *ARC59SendInfo, options *TransactionOptions) (*Uint64, error) {
+	group, err := MakeARC59SendTxnWithInfo(sender, receiver, amount, appID, assetID, suggestedParams, info, options)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := decodeTxns(group)
+	if err != nil {
+		return nil, err
+	}
+
+	var total uint64
+	for _, tx := range txns {
+		total += uint64(tx.Fee)
+		if tx.Type == types.PaymentTx {
+			total += uint64(tx.Amount)
+		}
+	}
+	cost := MakeUint64(total)
+	return &cost, nil
+}
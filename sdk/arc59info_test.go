@@ -0,0 +1,173 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testARC59Router struct {
+	t               *testing.T
+	inbox           types.Address
+	sendAssetInfo   []interface{}
+	failureMessage  string
+	err             error
+	lastRequestTxns []types.SignedTxn
+}
+
+func (r *testARC59Router) Simulate(encodedRequest []byte) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var request models.SimulateRequest
+	require.NoError(r.t, msgpack.Decode(encodedRequest, &request))
+	require.True(r.t, request.AllowEmptySignatures)
+	require.True(r.t, request.AllowUnnamedResources)
+	require.Len(r.t, request.TxnGroups, 1)
+	r.lastRequestTxns = request.TxnGroups[0].Txns
+
+	returnLog := func(typeString string, value interface{}) [][]byte {
+		returnType, err := abi.TypeOf(typeString)
+		require.NoError(r.t, err)
+		encoded, err := returnType.Encode(value)
+		require.NoError(r.t, err)
+		return [][]byte{[]byte("other log"), append(append([]byte(nil), abiReturnPrefix...), encoded...)}
+	}
+
+	var results []models.SimulateTransactionResult
+	if r.sendAssetInfo != nil {
+		results = append(results,
+			models.SimulateTransactionResult{TxnResult: models.PendingTransactionResponse{Logs: returnLog("(uint64,uint64,bool,bool,uint64)", r.sendAssetInfo)}},
+			models.SimulateTransactionResult{TxnResult: models.PendingTransactionResponse{Logs: returnLog("address", r.inbox[:])}},
+		)
+	}
+	response := models.SimulateResponse{
+		TxnGroups: []models.SimulateTransactionGroupResult{{
+			FailureMessage: r.failureMessage,
+			TxnResults:     results,
+		}},
+	}
+	return msgpack.Encode(&response), nil
+}
+
+func makeTestARC59Params() *SuggestedParams {
+	return &SuggestedParams{
+		Fee:             0,
+		FirstRoundValid: 2,
+		LastRoundValid:  1002,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     []byte("testnet-v1.0-genesis-hash-000000"),
+	}
+}
+
+func TestGetARC59SendInfo(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	receiver := types.Address{2}
+	inbox := types.Address{3}
+	router := &testARC59Router{
+		t:             t,
+		inbox:         inbox,
+		sendAssetInfo: []interface{}{uint64(3), uint64(228100), false, false, uint64(201000)},
+	}
+
+	info, err := GetARC59SendInfo(router, sender, receiver.String(), 643020148, 31566704, makeTestARC59Params())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), info.InnerTxCount)
+	require.Equal(t, MakeUint64(228100), *info.MinimumBalanceRequirement)
+	require.False(t, info.RouterOptedIn)
+	require.False(t, info.ReceiverOptedIn)
+	require.Equal(t, MakeUint64(201000), *info.ReceiverAlgoNeededForClaim)
+	require.Equal(t, inbox.String(), info.InboxAddress)
+
+	// the read-only calls are a group of two app calls from the sender
+	txns := router.lastRequestTxns
+	require.Len(t, txns, 2)
+	require.Equal(t, txns[0].Txn.Group, txns[1].Txn.Group)
+	require.NotEqual(t, types.Digest{}, txns[0].Txn.Group)
+	for _, stx := range txns {
+		require.Equal(t, sender, stx.Txn.Sender.String())
+		require.Equal(t, types.AppIndex(643020148), stx.Txn.ApplicationID)
+	}
+	require.Equal(t, [][]byte{methodSelector(t, arc59GetSendAssetInfoMethod), receiver[:], EncodeIntAsBytes(31566704)}, txns[0].Txn.ApplicationArgs)
+	require.Equal(t, [][]byte{methodSelector(t, arc59GetInboxMethod), receiver[:]}, txns[1].Txn.ApplicationArgs)
+
+	// receivers without an inbox
+	router.inbox = types.Address{}
+	router.sendAssetInfo = []interface{}{uint64(1), uint64(0), true, true, uint64(0)}
+	info, err = GetARC59SendInfo(router, sender, receiver.String(), 643020148, 31566704, makeTestARC59Params())
+	require.NoError(t, err)
+	require.Empty(t, info.InboxAddress)
+	require.True(t, info.RouterOptedIn)
+	require.True(t, info.ReceiverOptedIn)
+
+	router.failureMessage = "logic eval error"
+	_, err = GetARC59SendInfo(router, sender, receiver.String(), 643020148, 31566704, makeTestARC59Params())
+	require.ErrorContains(t, err, "simulation failed: logic eval error")
+
+	router.failureMessage = ""
+	router.sendAssetInfo = nil
+	_, err = GetARC59SendInfo(router, sender, receiver.String(), 643020148, 31566704, makeTestARC59Params())
+	require.ErrorContains(t, err, "expected 2 simulated transactions")
+
+	router.err = errors.New("network unavailable")
+	_, err = GetARC59SendInfo(router, sender, receiver.String(), 643020148, 31566704, makeTestARC59Params())
+	require.ErrorContains(t, err, "network unavailable")
+
+	_, err = GetARC59SendInfo(router, sender, "invalid", 643020148, 31566704, makeTestARC59Params())
+	require.Error(t, err)
+}
+
+func TestEstimateARC59SendCost(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	receiver := types.Address{2}.String()
+	amount := MakeUint64(10)
+	info := &ARC59SendInfo{
+		InnerTxCount:               3,
+		MinimumBalanceRequirement:  &Uint64{Lower: 228100},
+		RouterOptedIn:              false,
+		ReceiverAlgoNeededForClaim: &Uint64{Lower: 201000},
+		InboxAddress:               types.Address{3}.String(),
+	}
+
+	group, err := MakeARC59SendTxnWithInfo(sender, receiver, &amount, 643020148, 31566704, makeTestARC59Params(), info, nil)
+	require.NoError(t, err)
+	txns, err := decodeTxns(group)
+	require.NoError(t, err)
+	require.Len(t, txns, 4)
+	require.Equal(t, crypto.GetApplicationAddress(643020148), txns[0].Receiver)
+	require.Equal(t, types.MicroAlgos(429100), txns[0].Amount)
+	require.Equal(t, []types.Address{types.Address{2}, types.Address{3}}, txns[3].Accounts)
+
+	// payment 1000 + router opt in 2000 + asset transfer 1000 + send call (3+1)*1000 + 1000 for
+	// the ALGO sent to the receiver, and the payment to the router
+	cost, err := EstimateARC59SendCost(sender, receiver, &amount, 643020148, 31566704, makeTestARC59Params(), info, nil)
+	require.NoError(t, err)
+	require.Equal(t, MakeUint64(9000+429100), *cost)
+
+	info.RouterOptedIn = true
+	info.MinimumBalanceRequirement = &Uint64{}
+	info.ReceiverAlgoNeededForClaim = &Uint64{}
+	info.InnerTxCount = 1
+	cost, err = EstimateARC59SendCost(sender, receiver, &amount, 643020148, 31566704, makeTestARC59Params(), info, nil)
+	require.NoError(t, err)
+	require.Equal(t, MakeUint64(4000), *cost)
+
+	_, err = EstimateARC59SendCost(sender, receiver, &amount, 643020148, 31566704, makeTestARC59Params(), nil, nil)
+	require.Error(t, err)
+}
+
+func methodSelector(t *testing.T, signature string) []byte {
+	t.Helper()
+	selector, err := hex.DecodeString(MethodName(signature))
+	require.NoError(t, err)
+	return selector
+}
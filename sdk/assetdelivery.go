@@ -0,0 +1,422 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// accountMinBalance is the minimum balance of an account which holds nothing but ALGO.
+const accountMinBalance = 100000
+
+// Strategies of an AssetDeliveryPlan.
+const (
+	// AssetDeliveryDirect transfers the asset to a receiver which is already opted in to it.
+	AssetDeliveryDirect = "direct"
+	// AssetDeliveryFundedOptIn funds the receiver if needed, opts it in to the asset and transfers
+	// the asset, in one group which the receiver signs as well.
+	AssetDeliveryFundedOptIn = "funded-opt-in"
+	// AssetDeliveryARC59 sends the asset to the receiver's ARC-59 inbox.
+	AssetDeliveryARC59 = "arc59"
+)
+
+// Reasons why a strategy is not valid, as reported by AssetDeliveryPlan.ExplanationJSON.
+const (
+	deliveryReasonReceiverNotOptedIn = "receiver-not-opted-in"
+	deliveryReasonReceiverOptedIn    = "receiver-opted-in"
+	deliveryReasonReceiverCannotSign = "receiver-cannot-sign"
+	deliveryReasonARC59NotConfigured = "arc59-not-configured"
+	deliveryReasonSenderInsufficient = "sender-insufficient-balance"
+)
+
+// AssetDeliveryPlanner picks the cheapest way to deliver an asset from a sender to a receiver,
+// given the balances of both accounts and whether the receiver is opted in to the asset.
+type AssetDeliveryPlanner struct {
+	sender   string
+	receiver string
+	assetID  int64
+	amount   Uint64
+	note     []byte
+	options  *TransactionOptions
+
+	senderKnown      bool
+	senderBalance    uint64
+	senderMinBalance uint64
+
+	receiverKnown      bool
+	receiverBalance    uint64
+	receiverMinBalance uint64
+	receiverOptedIn    bool
+	receiverCanSign    bool
+
+	arc59AppID int64
+	arc59Info  *ARC59SendInfo
+}
+
+// NewAssetDeliveryPlanner creates a planner for delivering amount units of the asset assetID from
+// sender to receiver. SetReceiverState must be called before Plan.
+func NewAssetDeliveryPlanner(sender, receiver string, assetID int64, amount *Uint64) (*AssetDeliveryPlanner, error) {
+	if _, err := types.DecodeAddress(sender); err != nil {
+		return nil, err
+	}
+	if _, err := types.DecodeAddress(receiver); err != nil {
+		return nil, err
+	}
+	if assetID < 0 {
+		return nil, errNegativeArgument
+	}
+	if _, err := amount.Extract(); err != nil {
+		return nil, fmt.Errorf("Could not decode transaction amount: %v", err)
+	}
+	return &AssetDeliveryPlanner{sender: sender, receiver: receiver, assetID: assetID, amount: *amount}, nil
+}
+
+// SetNote sets the note of the transaction which transfers the asset.
+func (p *AssetDeliveryPlanner) SetNote(note []byte) {
+	p.note = append([]byte(nil), note...)
+}
+
+// SetTransactionOptions sets the lease and rekey address of the transactions of the sender. The
+// lease goes on the first transaction of the sender and the rekey on the last one.
+func (p *AssetDeliveryPlanner) SetTransactionOptions(options *TransactionOptions) {
+	p.options = options
+}
+
+// SetSenderBalance sets the ALGO balance and minimum balance of the sender. Strategies the sender
+// cannot afford are not chosen. If the sender's balance is not set, it is not checked.
+func (p *AssetDeliveryPlanner) SetSenderBalance(balance, minBalance *Uint64) error {
+	var err error
+	if p.senderBalance, err = balance.Extract(); err != nil {
+		return fmt.Errorf("Could not decode sender balance: %v", err)
+	}
+	if p.senderMinBalance, err = minBalance.Extract(); err != nil {
+		return fmt.Errorf("Could not decode sender minimum balance: %v", err)
+	}
+	p.senderKnown = true
+	return nil
+}
+
+// SetReceiverState sets the ALGO balance and minimum balance of the receiver, and whether it is
+// opted in to the asset. Use a balance of 0 for receivers which do not exist yet.
+func (p *AssetDeliveryPlanner) SetReceiverState(balance, minBalance *Uint64, optedIn bool) error {
+	var err error
+	if p.receiverBalance, err = balance.Extract(); err != nil {
+		return fmt.Errorf("Could not decode receiver balance: %v", err)
+	}
+	if p.receiverMinBalance, err = minBalance.Extract(); err != nil {
+		return fmt.Errorf("Could not decode receiver minimum balance: %v", err)
+	}
+	p.receiverOptedIn = optedIn
+	p.receiverKnown = true
+	return nil
+}
+
+// SetReceiverCanSign sets whether the receiver can sign the group which delivers the asset, which
+// is required to opt the receiver in to the asset. This is the case when the sender and receiver
+// are accounts of the same user.
+func (p *AssetDeliveryPlanner) SetReceiverCanSign(canSign bool) {
+	p.receiverCanSign = canSign
+}
+
+// SetARC59 allows delivering the asset through the ARC-59 router application appID. info is the
+// state of the receiver and the router, as returned by GetARC59SendInfo.
+func (p *AssetDeliveryPlanner) SetARC59(appID int64, info *ARC59SendInfo) error {
+	if appID < 0 {
+		return errNegativeArgument
+	}
+	if info == nil {
+		return errors.New("missing ARC-59 send info")
+	}
+	p.arc59AppID = appID
+	p.arc59Info = info
+	return nil
+}
+
+// assetDeliveryCandidate is the evaluation of one strategy, as reported by
+// AssetDeliveryPlan.ExplanationJSON.
+type assetDeliveryCandidate struct {
+	Strategy string `json:"strategy"`
+	Valid    bool   `json:"valid"`
+	Reason   string `json:"reason,omitempty"`
+	// Cost is the amount of microAlgos the sender spends, including fees.
+	Cost uint64 `json:"cost,omitempty"`
+	// ReceiverFunding is the amount of microAlgos paid to the receiver so it can opt in.
+	ReceiverFunding uint64 `json:"receiverFunding,omitempty"`
+
+	build func() (*TransactionSignerArray, error)
+}
+
+type assetDeliveryExplanation struct {
+	Strategy   string                   `json:"strategy"`
+	Cost       uint64                   `json:"cost"`
+	Candidates []assetDeliveryCandidate `json:"candidates"`
+}
+
+// AssetDeliveryPlan is the strategy chosen by an AssetDeliveryPlanner, with its transactions.
+type AssetDeliveryPlan struct {
+	transactions *TransactionSignerArray
+	explanation  assetDeliveryExplanation
+}
+
+// Strategy returns AssetDeliveryDirect, AssetDeliveryFundedOptIn or AssetDeliveryARC59.
+func (plan *AssetDeliveryPlan) Strategy() string {
+	return plan.explanation.Strategy
+}
+
+// Transactions returns the group which delivers the asset, with the address which must sign each
+// transaction.
+func (plan *AssetDeliveryPlan) Transactions() *TransactionSignerArray {
+	return plan.transactions
+}
+
+// Cost returns the amount of microAlgos the sender spends to deliver the asset, including fees and
+// any ALGO paid to the receiver or the ARC-59 router.
+func (plan *AssetDeliveryPlan) Cost() *Uint64 {
+	cost := MakeUint64(plan.explanation.Cost)
+	return &cost
+}
+
+// ExplanationJSON describes why the strategy was chosen. The result is a JSON object of the form
+//
+//	{"strategy":"funded-opt-in","cost":103000,"candidates":[
+//	  {"strategy":"direct","valid":false,"reason":"receiver-not-opted-in"},
+//	  {"strategy":"funded-opt-in","valid":true,"cost":103000,"receiverFunding":101000},
+//	  {"strategy":"arc59","valid":true,"cost":238100}]}
+//
+// The reason of an invalid candidate is one of "receiver-not-opted-in", "receiver-opted-in",
+// "receiver-cannot-sign", "arc59-not-configured" or "sender-insufficient-balance". The cheapest
+// valid candidate is chosen, preferring earlier candidates when costs are equal.
+func (plan *AssetDeliveryPlan) ExplanationJSON() string {
+	encoded, _ := json.Marshal(plan.explanation)
+	return string(encoded)
+}
+
+// estimateFee returns the fee of a transaction made by build with suggestedParams. A flat fee is
+// known without making the transaction.
+func estimateFee(suggestedParams *SuggestedParams, build func() ([]byte, error)) (uint64, error) {
+	if suggestedParams.FlatFee {
+		if suggestedParams.Fee < 0 {
+			return 0, errNegativeArgument
+		}
+		return uint64(suggestedParams.Fee), nil
+	}
+	encoded, err := build()
+	if err != nil {
+		return 0, err
+	}
+	var tx types.Transaction
+	err = msgpack.Decode(encoded, &tx)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(tx.Fee), nil
+}
+
+// makeSignerGroup assigns a group ID to txns, where txns[i] is signed by signers[i].
+func makeSignerGroup(txns [][]byte, signers []string) (*TransactionSignerArray, error) {
+	assignedTxns, err := AssignGroupID(&BytesArray{values: txns})
+	if err != nil {
+		return nil, err
+	}
+	signerItems := make([]TransactionSignerItem, assignedTxns.Length())
+	for i := range signerItems {
+		signerItems[i] = TransactionSignerItem{signer: signers[i], transaction: assignedTxns.Get(i)}
+	}
+	return &TransactionSignerArray{signerItems: signerItems, transactions: assignedTxns}, nil
+}
+
+func (p *AssetDeliveryPlanner) makeAssetTransfer(suggestedParams *SuggestedParams, options *TransactionOptions) ([]byte, error) {
+	amount := p.amount
+	return MakeAssetTransferTxn(p.sender, p.receiver, "", &amount, p.note, suggestedParams, p.assetID, options)
+}
+
+func (p *AssetDeliveryPlanner) makeOptIn(suggestedParams *SuggestedParams) ([]byte, error) {
+	optInAmount := MakeUint64(0)
+	return MakeAssetTransferTxn(p.receiver, p.receiver, "", &optInAmount, nil, suggestedParams, p.assetID, nil)
+}
+
+func (p *AssetDeliveryPlanner) direct(suggestedParams *SuggestedParams) (assetDeliveryCandidate, error) {
+	candidate := assetDeliveryCandidate{Strategy: AssetDeliveryDirect}
+	if !p.receiverOptedIn {
+		candidate.Reason = deliveryReasonReceiverNotOptedIn
+		return candidate, nil
+	}
+
+	fee, err := estimateFee(suggestedParams, func() ([]byte, error) { return p.makeAssetTransfer(suggestedParams, p.options) })
+	if err != nil {
+		return candidate, err
+	}
+	candidate.Valid = true
+	candidate.Cost = fee
+	candidate.build = func() (*TransactionSignerArray, error) {
+		assetTxn, err := p.makeAssetTransfer(suggestedParams, p.options)
+		if err != nil {
+			return nil, err
+		}
+		return makeSignerGroup([][]byte{assetTxn}, []string{p.sender})
+	}
+	return candidate, nil
+}
+
+func (p *AssetDeliveryPlanner) fundedOptIn(suggestedParams *SuggestedParams) (assetDeliveryCandidate, error) {
+	candidate := assetDeliveryCandidate{Strategy: AssetDeliveryFundedOptIn}
+	if p.receiverOptedIn {
+		candidate.Reason = deliveryReasonReceiverOptedIn
+		return candidate, nil
+	}
+	if !p.receiverCanSign {
+		candidate.Reason = deliveryReasonReceiverCannotSign
+		return candidate, nil
+	}
+
+	optInFee, err := estimateFee(suggestedParams, func() ([]byte, error) { return p.makeOptIn(suggestedParams) })
+	if err != nil {
+		return candidate, err
+	}
+
+	// the receiver must keep its minimum balance after paying for the opt in
+	minBalance := p.receiverMinBalance
+	if minBalance < accountMinBalance {
+		minBalance = accountMinBalance
+	}
+	required := minBalance + assetOptInMinBalance + optInFee
+	if p.receiverBalance < required {
+		candidate.ReceiverFunding = required - p.receiverBalance
+	}
+
+	group := groupOptions{options: p.options}
+	var paymentTxn func() ([]byte, error)
+	var paymentFee uint64
+	if candidate.ReceiverFunding > 0 {
+		funding := MakeUint64(candidate.ReceiverFunding)
+		paymentOptions := group.next(false)
+		paymentTxn = func() ([]byte, error) {
+			return MakePaymentTxn(p.sender, p.receiver, &funding, nil, "", suggestedParams, paymentOptions)
+		}
+		paymentFee, err = estimateFee(suggestedParams, paymentTxn)
+		if err != nil {
+			return candidate, err
+		}
+	}
+	assetOptions := group.next(true)
+	assetFee, err := estimateFee(suggestedParams, func() ([]byte, error) { return p.makeAssetTransfer(suggestedParams, assetOptions) })
+	if err != nil {
+		return candidate, err
+	}
+
+	candidate.Valid = true
+	candidate.Cost = candidate.ReceiverFunding + paymentFee + assetFee
+
+	candidate.build = func() (*TransactionSignerArray, error) {
+		var txns [][]byte
+		var signers []string
+		if paymentTxn != nil {
+			payment, err := paymentTxn()
+			if err != nil {
+				return nil, err
+			}
+			txns = append(txns, payment)
+			signers = append(signers, p.sender)
+		}
+		optIn, err := p.makeOptIn(suggestedParams)
+		if err != nil {
+			return nil, err
+		}
+		assetTxn, err := p.makeAssetTransfer(suggestedParams, assetOptions)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, optIn, assetTxn)
+		signers = append(signers, p.receiver, p.sender)
+		return makeSignerGroup(txns, signers)
+	}
+	return candidate, nil
+}
+
+func (p *AssetDeliveryPlanner) arc59(suggestedParams *SuggestedParams) (assetDeliveryCandidate, error) {
+	candidate := assetDeliveryCandidate{Strategy: AssetDeliveryARC59}
+	if p.receiverOptedIn {
+		candidate.Reason = deliveryReasonReceiverOptedIn
+		return candidate, nil
+	}
+	if p.arc59Info == nil {
+		candidate.Reason = deliveryReasonARC59NotConfigured
+		return candidate, nil
+	}
+
+	amount := p.amount
+	cost, err := EstimateARC59SendCost(p.sender, p.receiver, &amount, p.arc59AppID, p.assetID, suggestedParams, p.arc59Info, p.options)
+	if err != nil {
+		return candidate, err
+	}
+	candidate.Valid = true
+	candidate.Cost, _ = cost.Extract()
+	candidate.build = func() (*TransactionSignerArray, error) {
+		group, err := MakeARC59SendTxnWithInfo(p.sender, p.receiver, &amount, p.arc59AppID, p.assetID, suggestedParams, p.arc59Info, p.options)
+		if err != nil {
+			return nil, err
+		}
+		signerItems := make([]TransactionSignerItem, group.Length())
+		for i := range signerItems {
+			signerItems[i] = TransactionSignerItem{signer: p.sender, transaction: group.Get(i)}
+		}
+		return &TransactionSignerArray{signerItems: signerItems, transactions: group}, nil
+	}
+	return candidate, nil
+}
+
+// Plan evaluates every strategy with suggestedParams and returns the cheapest valid one.
+func (p *AssetDeliveryPlanner) Plan(suggestedParams *SuggestedParams) (*AssetDeliveryPlan, error) {
+	if !p.receiverKnown {
+		return nil, errors.New("receiver state is not set")
+	}
+
+	var candidates []assetDeliveryCandidate
+	for _, evaluate := range []func(*SuggestedParams) (assetDeliveryCandidate, error){p.direct, p.fundedOptIn, p.arc59} {
+		candidate, err := evaluate(suggestedParams)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	chosen := -1
+	senderInsufficient := false
+	for i := range candidates {
+		candidate := &candidates[i]
+		if !candidate.Valid {
+			continue
+		}
+		if p.senderKnown && (p.senderBalance < p.senderMinBalance || candidate.Cost > p.senderBalance-p.senderMinBalance) {
+			candidate.Valid = false
+			candidate.Reason = deliveryReasonSenderInsufficient
+			senderInsufficient = true
+			continue
+		}
+		if chosen == -1 || candidate.Cost < candidates[chosen].Cost {
+			chosen = i
+		}
+	}
+	if chosen == -1 {
+		if senderInsufficient {
+			return nil, errors.New("sender does not have enough algo to deliver the asset")
+		}
+		return nil, errors.New("the receiver is not opted in to the asset and cannot sign an opt in, and ARC-59 is not configured")
+	}
+
+	transactions, err := candidates[chosen].build()
+	if err != nil {
+		return nil, err
+	}
+	return &AssetDeliveryPlan{
+		transactions: transactions,
+		explanation: assetDeliveryExplanation{
+			Strategy:   candidates[chosen].Strategy,
+			Cost:       candidates[chosen].Cost,
+			Candidates: candidates,
+		},
+	}, nil
+}
@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestDeliveryPlanner(t *testing.T, receiverBalance, receiverMinBalance uint64, optedIn bool) *AssetDeliveryPlanner {
+	t.Helper()
+	amount := MakeUint64(500)
+	planner, err := NewAssetDeliveryPlanner(types.Address{1}.String(), types.Address{2}.String(), 12345, &amount)
+	require.NoError(t, err)
+	balance := MakeUint64(receiverBalance)
+	minBalance := MakeUint64(receiverMinBalance)
+	require.NoError(t, planner.SetReceiverState(&balance, &minBalance, optedIn))
+	return planner
+}
+
+func makeTestDeliveryParams() *SuggestedParams {
+	params := makeTestARC59Params()
+	params.Fee = 1000
+	params.FlatFee = true
+	return params
+}
+
+func decodeTestExplanation(t *testing.T, plan *AssetDeliveryPlan) assetDeliveryExplanation {
+	t.Helper()
+	var explanation assetDeliveryExplanation
+	require.NoError(t, json.Unmarshal([]byte(plan.ExplanationJSON()), &explanation))
+	return explanation
+}
+
+func TestAssetDeliveryPlannerDirect(t *testing.T) {
+	t.Parallel()
+	planner := makeTestDeliveryPlanner(t, 0, 0, true)
+	planner.SetNote([]byte("gift"))
+	plan, err := planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Equal(t, AssetDeliveryDirect, plan.Strategy())
+	require.Equal(t, MakeUint64(1000), *plan.Cost())
+
+	txns := decodeTestGroup(t, plan.Transactions())
+	require.Len(t, txns, 1)
+	require.Equal(t, types.Address{2}, txns[0].AssetReceiver)
+	require.Equal(t, uint64(500), txns[0].AssetAmount)
+	require.Equal(t, []byte("gift"), txns[0].Note)
+	require.Equal(t, types.Address{1}.String(), plan.Transactions().GetSigner(0))
+
+	explanation := decodeTestExplanation(t, plan)
+	require.Equal(t, []assetDeliveryCandidate{
+		{Strategy: AssetDeliveryDirect, Valid: true, Cost: 1000},
+		{Strategy: AssetDeliveryFundedOptIn, Reason: deliveryReasonReceiverOptedIn},
+		{Strategy: AssetDeliveryARC59, Reason: deliveryReasonReceiverOptedIn},
+	}, explanation.Candidates)
+}
+
+func TestAssetDeliveryPlannerFundedOptIn(t *testing.T) {
+	t.Parallel()
+	// the receiver has 50000 above its minimum balance, so it lacks 51000 to opt in
+	planner := makeTestDeliveryPlanner(t, 350000, 300000, false)
+	planner.SetReceiverCanSign(true)
+	plan, err := planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Equal(t, AssetDeliveryFundedOptIn, plan.Strategy())
+	require.Equal(t, MakeUint64(51000+2000), *plan.Cost())
+
+	group := plan.Transactions()
+	txns := decodeTestGroup(t, group)
+	require.Len(t, txns, 3)
+	require.Equal(t, types.PaymentTx, txns[0].Type)
+	require.Equal(t, types.MicroAlgos(51000), txns[0].Amount)
+	require.Equal(t, types.Address{2}, txns[1].Sender)
+	require.Equal(t, types.Address{2}, txns[1].AssetReceiver)
+	require.Equal(t, types.Address{1}, txns[2].Sender)
+	require.Equal(t, []string{types.Address{1}.String(), types.Address{2}.String(), types.Address{1}.String()},
+		[]string{group.GetSigner(0), group.GetSigner(1), group.GetSigner(2)})
+
+	// receivers with enough ALGO are not funded
+	planner = makeTestDeliveryPlanner(t, 500000, 300000, false)
+	planner.SetReceiverCanSign(true)
+	plan, err = planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Len(t, decodeTestGroup(t, plan.Transactions()), 2)
+	require.Equal(t, MakeUint64(1000), *plan.Cost())
+
+	// receivers which do not exist need the minimum balance of an account as well
+	planner = makeTestDeliveryPlanner(t, 0, 0, false)
+	planner.SetReceiverCanSign(true)
+	plan, err = planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(201000), decodeTestGroup(t, plan.Transactions())[0].Amount)
+}
+
+func TestAssetDeliveryPlannerARC59(t *testing.T) {
+	t.Parallel()
+	info := &ARC59SendInfo{
+		InnerTxCount:               1,
+		MinimumBalanceRequirement:  &Uint64{},
+		RouterOptedIn:              true,
+		ReceiverAlgoNeededForClaim: &Uint64{},
+	}
+
+	// the receiver cannot sign, so only ARC-59 is possible
+	planner := makeTestDeliveryPlanner(t, 0, 0, false)
+	require.NoError(t, planner.SetARC59(643020148, info))
+	plan, err := planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Equal(t, AssetDeliveryARC59, plan.Strategy())
+	require.Equal(t, MakeUint64(4000), *plan.Cost())
+	require.Len(t, decodeTestGroup(t, plan.Transactions()), 3)
+	explanation := decodeTestExplanation(t, plan)
+	require.Equal(t, deliveryReasonReceiverCannotSign, explanation.Candidates[1].Reason)
+
+	// ARC-59 is cheaper than funding a receiver which does not exist
+	planner.SetReceiverCanSign(true)
+	plan, err = planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Equal(t, AssetDeliveryARC59, plan.Strategy())
+	explanation = decodeTestExplanation(t, plan)
+	require.Equal(t, assetDeliveryCandidate{Strategy: AssetDeliveryFundedOptIn, Valid: true, Cost: 203000, ReceiverFunding: 201000}, explanation.Candidates[1])
+
+	// a sender which cannot afford ARC-59 can still fund a cheaper opt in
+	info.MinimumBalanceRequirement = &Uint64{Lower: 300000}
+	planner = makeTestDeliveryPlanner(t, 450000, 300000, false)
+	planner.SetReceiverCanSign(true)
+	require.NoError(t, planner.SetARC59(643020148, info))
+	senderBalance := MakeUint64(200000)
+	senderMinBalance := MakeUint64(100000)
+	require.NoError(t, planner.SetSenderBalance(&senderBalance, &senderMinBalance))
+	plan, err = planner.Plan(makeTestDeliveryParams())
+	require.NoError(t, err)
+	require.Equal(t, AssetDeliveryFundedOptIn, plan.Strategy())
+	explanation = decodeTestExplanation(t, plan)
+	require.Equal(t, deliveryReasonSenderInsufficient, explanation.Candidates[2].Reason)
+}
+
+func TestAssetDeliveryPlannerInvalid(t *testing.T) {
+	t.Parallel()
+	planner := makeTestDeliveryPlanner(t, 0, 0, false)
+	_, err := planner.Plan(makeTestDeliveryParams())
+	require.ErrorContains(t, err, "cannot sign an opt in")
+
+	senderBalance := MakeUint64(100000)
+	senderMinBalance := MakeUint64(100000)
+	planner = makeTestDeliveryPlanner(t, 0, 0, true)
+	require.NoError(t, planner.SetSenderBalance(&senderBalance, &senderMinBalance))
+	_, err = planner.Plan(makeTestDeliveryParams())
+	require.ErrorContains(t, err, "sender does not have enough algo")
+
+	amount := MakeUint64(1)
+	planner, err = NewAssetDeliveryPlanner(types.Address{1}.String(), types.Address{2}.String(), 1, &amount)
+	require.NoError(t, err)
+	_, err = planner.Plan(makeTestDeliveryParams())
+	require.ErrorContains(t, err, "receiver state is not set")
+	_, err = NewAssetDeliveryPlanner("invalid", types.Address{2}.String(), 1, &amount)
+	require.Error(t, err)
+	_, err = NewAssetDeliveryPlanner(types.Address{1}.String(), types.Address{2}.String(), 1, &Uint64{Lower: -1})
+	require.Error(t, err)
+	require.Error(t, planner.SetReceiverState(&Uint64{Upper: -1}, &amount, false))
+}
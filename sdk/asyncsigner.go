@@ -0,0 +1,316 @@
+package sdk
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// Progress events of a sign request, reported to AsyncSignerHandler.OnSignEvent.
+const (
+	// SignEventSigned is reported when a signature is submitted for a transaction of the request.
+	SignEventSigned = "signed"
+	// SignEventCompleted is reported when every transaction of the request is signed.
+	SignEventCompleted = "completed"
+	// SignEventRejected is reported when the request is rejected with Reject.
+	SignEventRejected = "rejected"
+	// SignEventTimedOut is reported when the request is not answered within the signer's timeout.
+	SignEventTimedOut = "timed-out"
+	// SignEventCancelled is reported when the request is cancelled with Cancel or CancelAll.
+	SignEventCancelled = "cancelled"
+)
+
+// AsyncSignerHandler is implemented by applications to sign transactions with a device or service
+// which answers later, such as a hardware wallet over Bluetooth.
+type AsyncSignerHandler interface {
+	// OnSignRequest is called when transactions must be signed. It must not block: the application
+	// answers the request later with AsyncSigner.SubmitSignature or AsyncSigner.Reject.
+	OnSignRequest(request *SignRequest)
+	// OnSignEvent reports the progress of a request, with the number of transactions signed so far
+	// and the number of transactions to sign. The events are the SignEvent constants.
+	OnSignEvent(requestID string, event string, signed int, total int)
+}
+
+// SignRequest is a request to sign some transactions of a group with the account of an AsyncSigner.
+type SignRequest struct {
+	id          string
+	address     types.Address
+	group       [][]byte
+	indexes     []int64
+	txns        [][]byte
+	bytesToSign [][]byte
+}
+
+// ID returns the identifier of the request, used to answer it.
+func (r *SignRequest) ID() string {
+	return r.id
+}
+
+// Address returns the address of the account which must sign the transactions.
+func (r *SignRequest) Address() string {
+	return r.address.String()
+}
+
+// Group returns the whole transaction group, for showing the transactions to sign in context.
+func (r *SignRequest) Group() *BytesArray {
+	return &BytesArray{r.group}
+}
+
+// Indexes returns the indexes in the group of the transactions to sign.
+func (r *SignRequest) Indexes() *Int64Array {
+	return &Int64Array{r.indexes}
+}
+
+// Transactions returns the encoded transactions to sign, in the order of Indexes.
+func (r *SignRequest) Transactions() *BytesArray {
+	return &BytesArray{r.txns}
+}
+
+// BytesToSign returns the bytes to sign for each transaction, in the order of Indexes, as returned
+// by RawTransactionBytesToSign.
+func (r *SignRequest) BytesToSign() *BytesArray {
+	return &BytesArray{r.bytesToSign}
+}
+
+// pendingSignRequest is a request waiting for its signatures.
+type pendingSignRequest struct {
+	request *SignRequest
+	txns    []types.Transaction
+	sigs    []*types.Signature
+	signed  int
+	done    chan struct{}
+	err     error
+}
+
+// AsyncSigner is a TransactionSigner for an account whose signatures are provided asynchronously by
+// the application. SignTransactions sends a SignRequest to the handler and blocks until the
+// request is answered, rejected, cancelled or times out, so an AtomicTransactionComposer using it
+// must gather its signatures outside of the UI thread.
+//
+// Signatures are verified against the account as they are submitted. Transactions whose sender is
+// not the account, because the sender was rekeyed to it, are signed with their auth address set.
+type AsyncSigner struct {
+	address types.Address
+	handler AsyncSignerHandler
+	timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingSignRequest
+}
+
+// NewAsyncSigner creates an AsyncSigner for the account address. Requests time out if they are
+// not answered within timeoutMillis milliseconds, or never if timeoutMillis is 0.
+func NewAsyncSigner(address string, handler AsyncSignerHandler, timeoutMillis int64) (*AsyncSigner, error) {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		return nil, errors.New("handler must not be nil")
+	}
+	if timeoutMillis < 0 {
+		return nil, errNegativeArgument
+	}
+	return &AsyncSigner{
+		address: addr,
+		handler: handler,
+		timeout: time.Duration(timeoutMillis) * time.Millisecond,
+		pending: make(map[string]*pendingSignRequest),
+	}, nil
+}
+
+func newSignRequestID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// SignTransactions signs the transactions in txGroup at the indexes specified in indexesToSign,
+// blocking until the application answers the sign request.
+func (s *AsyncSigner) SignTransactions(txGroup *BytesArray, indexesToSign *Int64Array) (*BytesArray, error) {
+	group, err := decodeTxns(txGroup)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newSignRequestID()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := indexesToSign.Extract()
+	pending := &pendingSignRequest{
+		request: &SignRequest{
+			id:          id,
+			address:     s.address,
+			group:       txGroup.Extract(),
+			indexes:     append([]int64(nil), indexes...),
+			txns:        make([][]byte, len(indexes)),
+			bytesToSign: make([][]byte, len(indexes)),
+		},
+		txns: make([]types.Transaction, len(indexes)),
+		sigs: make([]*types.Signature, len(indexes)),
+		done: make(chan struct{}),
+	}
+	seen := make(map[int64]bool, len(indexes))
+	for i, index := range indexes {
+		if index < 0 || index >= int64(len(group)) {
+			return nil, fmt.Errorf("index %d is out of range for a group of %d transactions", index, len(group))
+		}
+		// SubmitSignature finds the transaction by its index, so every index must be unique
+		if seen[index] {
+			return nil, fmt.Errorf("index %d is requested more than once", index)
+		}
+		seen[index] = true
+		pending.txns[i] = group[index]
+		pending.request.txns[i] = msgpack.Encode(&group[index])
+		pending.request.bytesToSign[i], err = RawTransactionBytesToSign(pending.request.txns[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(indexes) == 0 {
+		return &BytesArray{}, nil
+	}
+
+	s.mu.Lock()
+	s.pending[id] = pending
+	s.mu.Unlock()
+	s.handler.OnSignRequest(pending.request)
+
+	var timeout <-chan time.Time
+	if s.timeout > 0 {
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-pending.done:
+	case <-timeout:
+		s.finish(id, errSignRequestTimedOut, SignEventTimedOut)
+		<-pending.done
+	}
+	if pending.err != nil {
+		return nil, pending.err
+	}
+
+	stxs := make([][]byte, len(pending.txns))
+	for i, txn := range pending.txns {
+		stx := types.SignedTxn{Sig: *pending.sigs[i], Txn: txn}
+		if txn.Sender != s.address {
+			stx.AuthAddr = s.address
+		}
+		stxs[i] = msgpack.Encode(&stx)
+	}
+	return &BytesArray{stxs}, nil
+}
+
+// finish ends a pending request with err, which is nil if it was signed, and reports event. It
+// does nothing if the request has already finished.
+func (s *AsyncSigner) finish(requestID string, err error, event string) bool {
+	s.mu.Lock()
+	pending, ok := s.pending[requestID]
+	var signed int
+	if ok {
+		delete(s.pending, requestID)
+		pending.err = err
+		signed = pending.signed
+		close(pending.done)
+	}
+	s.mu.Unlock()
+	if ok {
+		s.handler.OnSignEvent(requestID, event, signed, len(pending.txns))
+	}
+	return ok
+}
+
+func unknownSignRequest(requestID string) error {
+	return fmt.Errorf("sign request %s is unknown or already finished", requestID)
+}
+
+// SubmitSignature answers the request requestID with the signature of the transaction at index in
+// the group. The request completes once every transaction is signed.
+func (s *AsyncSigner) SubmitSignature(requestID string, index int64, signature []byte) error {
+	var sig types.Signature
+	if len(signature) != len(sig) {
+		return fmt.Errorf("incorrect signature length expected %d, got %d", len(sig), len(signature))
+	}
+	copy(sig[:], signature)
+
+	s.mu.Lock()
+	pending, ok := s.pending[requestID]
+	if !ok {
+		s.mu.Unlock()
+		return unknownSignRequest(requestID)
+	}
+	position := -1
+	for i, requested := range pending.request.indexes {
+		if requested == index {
+			position = i
+			break
+		}
+	}
+	if position == -1 {
+		s.mu.Unlock()
+		return fmt.Errorf("transaction %d is not part of sign request %s", index, requestID)
+	}
+	if !verifyTransactionSignature(s.address[:], pending.txns[position], sig) {
+		s.mu.Unlock()
+		return fmt.Errorf("signature does not verify for transaction %d with %s", index, s.address)
+	}
+	if pending.sigs[position] == nil {
+		pending.sigs[position] = &sig
+		pending.signed++
+	}
+	signed, total := pending.signed, len(pending.txns)
+	s.mu.Unlock()
+
+	s.handler.OnSignEvent(requestID, SignEventSigned, signed, total)
+	if signed == total {
+		s.finish(requestID, nil, SignEventCompleted)
+	}
+	return nil
+}
+
+// Reject answers the request requestID with a rejection, such as the user declining on the
+// device. SignTransactions returns an error with reason.
+func (s *AsyncSigner) Reject(requestID string, reason string) error {
+	if !s.finish(requestID, fmt.Errorf("sign request %s was rejected: %s", requestID, reason), SignEventRejected) {
+		return unknownSignRequest(requestID)
+	}
+	return nil
+}
+
+// Cancel cancels the request requestID, for example when the user leaves the signing screen.
+func (s *AsyncSigner) Cancel(requestID string) error {
+	if !s.finish(requestID, errSignRequestCancelled, SignEventCancelled) {
+		return unknownSignRequest(requestID)
+	}
+	return nil
+}
+
+// CancelAll cancels every pending request, for example when the device disconnects.
+func (s *AsyncSigner) CancelAll() {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.pending))
+	for id := range s.pending {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+	for _, id := range ids {
+		s.finish(id, errSignRequestCancelled, SignEventCancelled)
+	}
+}
+
+// Equals returns true if other is the same AsyncSigner.
+func (s *AsyncSigner) Equals(other TransactionSigner) bool {
+	casted, ok := other.(*AsyncSigner)
+	return ok && casted == s
+}
@@ -0,0 +1,138 @@
+package sdk
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// testAsyncSignerHandler hands sign requests to the test and records the events of the requests.
+type testAsyncSignerHandler struct {
+	requests chan *SignRequest
+
+	mu     sync.Mutex
+	events []string
+}
+
+func newTestAsyncSignerHandler() *testAsyncSignerHandler {
+	return &testAsyncSignerHandler{requests: make(chan *SignRequest, 1)}
+}
+
+func (h *testAsyncSignerHandler) OnSignRequest(request *SignRequest) {
+	h.requests <- request
+}
+
+func (h *testAsyncSignerHandler) OnSignEvent(requestID string, event string, signed int, total int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, fmt.Sprintf("%s %d/%d", event, signed, total))
+}
+
+func (h *testAsyncSignerHandler) recordedEvents() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+// signAsync runs SignTransactions in the background and returns the channel of its result.
+func signAsync(signer *AsyncSigner, group *BytesArray, indexes ...int64) chan error {
+	result := make(chan error, 1)
+	go func() {
+		_, err := signer.SignTransactions(group, &Int64Array{indexes})
+		result <- err
+	}()
+	return result
+}
+
+func TestAsyncSigner(t *testing.T) {
+	t.Parallel()
+	device := crypto.GenerateAccount()
+	rekeyed := crypto.GenerateAccount()
+	handler := newTestAsyncSignerHandler()
+	// a finite timeout fails the test instead of hanging it if the request is not answered
+	signer, err := NewAsyncSigner(device.Address.String(), handler, 10000)
+	require.NoError(t, err)
+
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, device.Address, rekeyed.Address, 1), signer))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, rekeyed.Address, device.Address, 2), signer))
+
+	// the application answers the request while the composer waits for its signatures, and reports
+	// the requests it received to the test
+	answered := make(chan *SignRequest, 1)
+	answerErr := make(chan error, 1)
+	go func() {
+		request := <-handler.requests
+		answered <- request
+		for i, index := range request.Indexes().Extract() {
+			if err := signer.SubmitSignature(request.ID(), index, ed25519.Sign(rekeyed.PrivateKey, request.BytesToSign().Get(i))); err == nil {
+				answerErr <- fmt.Errorf("signature of the wrong key was accepted for transaction %d", index)
+				return
+			}
+			if err := signer.SubmitSignature(request.ID(), index, ed25519.Sign(device.PrivateKey, request.BytesToSign().Get(i))); err != nil {
+				answerErr <- err
+				return
+			}
+		}
+		answerErr <- nil
+	}()
+	stxs, err := atc.GatherSignatures()
+	require.NoError(t, err)
+	require.NoError(t, <-answerErr)
+	request := <-answered
+	require.Equal(t, device.Address.String(), request.Address())
+	require.Equal(t, []int64{0, 1}, request.Indexes().Extract())
+	require.Equal(t, 2, request.Group().Length())
+	for i := range request.Indexes().Extract() {
+		expected, err := RawTransactionBytesToSign(request.Transactions().Get(i))
+		require.NoError(t, err)
+		require.Equal(t, expected, request.BytesToSign().Get(i))
+	}
+	require.ErrorContains(t, signer.Cancel(request.ID()), "unknown or already finished")
+	require.True(t, decodeTestSignedTxn(t, stxs.Get(0)).AuthAddr.IsZero())
+	require.Equal(t, device.Address, decodeTestSignedTxn(t, stxs.Get(1)).AuthAddr)
+	require.Equal(t, []string{"signed 1/2", "signed 2/2", "completed 2/2"}, handler.recordedEvents())
+	require.True(t, signer.Equals(signer))
+}
+
+func TestAsyncSignerUnanswered(t *testing.T) {
+	t.Parallel()
+	device := crypto.GenerateAccount()
+	group := &BytesArray{values: [][]byte{makeTestComposerPayment(t, device.Address, device.Address, 1)}}
+
+	handler := newTestAsyncSignerHandler()
+	signer, err := NewAsyncSigner(device.Address.String(), handler, 0)
+	require.NoError(t, err)
+	result := signAsync(signer, group, 0)
+	request := <-handler.requests
+	require.ErrorContains(t, signer.SubmitSignature(request.ID(), 1, make([]byte, 64)), "not part of sign request")
+	require.NoError(t, signer.Reject(request.ID(), "declined on device"))
+	require.ErrorContains(t, <-result, "rejected: declined on device")
+
+	result = signAsync(signer, group, 0)
+	<-handler.requests
+	signer.CancelAll()
+	require.ErrorIs(t, <-result, errSignRequestCancelled)
+	require.Equal(t, []string{"rejected 0/1", "cancelled 0/1"}, handler.recordedEvents())
+
+	handler = newTestAsyncSignerHandler()
+	signer, err = NewAsyncSigner(device.Address.String(), handler, 10)
+	require.NoError(t, err)
+	result = signAsync(signer, group, 0)
+	request = <-handler.requests
+	require.ErrorIs(t, <-result, errSignRequestTimedOut)
+	require.Error(t, signer.Reject(request.ID(), "too late"))
+	require.Equal(t, []string{"timed-out 0/1"}, handler.recordedEvents())
+
+	_, err = signer.SignTransactions(&BytesArray{values: [][]byte{group.Get(0), group.Get(0)}}, &Int64Array{[]int64{1, 1}})
+	require.ErrorContains(t, err, "more than once")
+
+	_, err = NewAsyncSigner(device.Address.String(), nil, 0)
+	require.Error(t, err)
+	_, err = NewAsyncSigner(device.Address.String(), handler, -1)
+	require.ErrorIs(t, err, errNegativeArgument)
+}
@@ -0,0 +1,516 @@
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/json"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// composerStateVersion is the version of the exported AtomicTransactionComposer state document.
+const composerStateVersion = 1
+
+// Kinds of signers recorded in an exported AtomicTransactionComposer state document. Transactions
+// added without a signer have the kind signerKindNone, and can be bound to a signer by their sender.
+const (
+	signerKindNone     = "none"
+	signerKindAddress  = "address"
+	signerKindMultisig = "multisig"
+	signerKindLogicSig = "logicsig"
+	signerKindExternal = "external"
+)
+
+// composerStateSigner describes the signer of a transaction without any of its secrets. Address is
+// the address which the signer is re-bound by on import.
+type composerStateSigner struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Kind     string                  `codec:"kind"`
+	Address  types.Address           `codec:"addr"`
+	Multisig *multisigPreimage       `codec:"msig"`
+	LogicSig *crypto.LogicSigAccount `codec:"lsig"`
+}
+
+type composerStateTxn struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Txn    types.Transaction   `codec:"txn"`
+	Method string              `codec:"method"`
+	Signer composerStateSigner `codec:"signer"`
+	Signed []byte              `codec:"stxn"`
+}
+
+type composerState struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Version int                `codec:"v"`
+	Status  int                `codec:"status"`
+	Txns    []composerStateTxn `codec:"txns"`
+}
+
+// presignedSigner returns a signature which was gathered before, instead of signing again. It is
+// only equal to itself, so that every presigned transaction is signed on its own.
+type presignedSigner struct {
+	stxn     []byte
+	txID     string
+	fallback transaction.TransactionSigner
+}
+
+func (s *presignedSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	stxns := make([][]byte, len(indexesToSign))
+	for i, index := range indexesToSign {
+		if crypto.TransactionIDString(txGroup[index]) != s.txID {
+			return nil, fmt.Errorf("signed transaction does not match transaction %d", index)
+		}
+		stxns[i] = s.stxn
+	}
+	return stxns, nil
+}
+
+func (s *presignedSigner) Equals(other transaction.TransactionSigner) bool {
+	casted, ok := other.(*presignedSigner)
+	return ok && casted == s
+}
+
+// unboundSigner stands in for a signer which was not re-bound on import. Signing with it fails.
+type unboundSigner struct {
+	state composerStateSigner
+}
+
+func (s unboundSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	return nil, fmt.Errorf("no signer is bound to %s", s.state.Address)
+}
+
+func (s unboundSigner) Equals(other transaction.TransactionSigner) bool {
+	casted, ok := other.(unboundSigner)
+	return ok && casted.state.Kind == s.state.Kind && casted.state.Address == s.state.Address
+}
+
+// describeSigner returns the description of a signer, and the signature it holds, if any.
+func describeSigner(signer transaction.TransactionSigner, txn types.Transaction) (composerStateSigner, []byte, error) {
+	switch s := signer.(type) {
+	case externalToInternalSigner:
+		if s.externalSigner == nil {
+			return composerStateSigner{Kind: signerKindNone, Address: txn.Sender}, nil, nil
+		}
+		if internal, ok := s.externalSigner.(internalToExternalSigner); ok {
+			return describeSigner(internal.internalSigner, txn)
+		}
+	case *presignedSigner:
+		state, _, err := describeSigner(s.fallback, txn)
+		return state, s.stxn, err
+	case unboundSigner:
+		return s.state, nil, nil
+	case transaction.BasicAccountTransactionSigner:
+		return composerStateSigner{Kind: signerKindAddress, Address: s.Account.Address}, nil, nil
+	case transaction.MultiSigAccountTransactionSigner:
+		addr, err := s.Msig.Address()
+		if err != nil {
+			return composerStateSigner{}, nil, err
+		}
+		msig := makeMultisigPreimage(s.Msig)
+		return composerStateSigner{Kind: signerKindMultisig, Address: addr, Multisig: &msig}, nil, nil
+	case transaction.LogicSigAccountTransactionSigner:
+		addr, err := s.LogicSigAccount.Address()
+		if err != nil {
+			return composerStateSigner{}, nil, err
+		}
+		lsig := s.LogicSigAccount
+		return composerStateSigner{Kind: signerKindLogicSig, Address: addr, LogicSig: &lsig}, nil, nil
+	}
+	return composerStateSigner{Kind: signerKindExternal, Address: txn.Sender}, nil, nil
+}
+
+func (c *AtomicTransactionComposer) exportState() (composerState, error) {
+	state := composerState{
+		Version: composerStateVersion,
+		Status:  c.value.GetStatus(),
+	}
+	if c.value.Count() == 0 {
+		return state, nil
+	}
+
+	var txnsWithSigners []transaction.TransactionWithSigner
+	var err error
+	if state.Status == transaction.BUILDING {
+		// Build a copy to get the transactions without changing the status of this composer
+		clone := c.value.Clone()
+		txnsWithSigners, err = clone.BuildGroup()
+		for i := range txnsWithSigners {
+			txnsWithSigners[i].Txn.Group = types.Digest{}
+		}
+	} else {
+		txnsWithSigners, err = c.value.BuildGroup()
+	}
+	if err != nil {
+		return composerState{}, err
+	}
+
+	var stxns [][]byte
+	if state.Status >= transaction.SIGNED {
+		stxns, err = c.value.GatherSignatures()
+		if err != nil {
+			return composerState{}, err
+		}
+	}
+
+	state.Txns = make([]composerStateTxn, len(txnsWithSigners))
+	for i, txnWithSigner := range txnsWithSigners {
+		signerState, stxn, err := describeSigner(txnWithSigner.Signer, txnWithSigner.Txn)
+		if err != nil {
+			return composerState{}, fmt.Errorf("could not describe signer of transaction %d: %w", i, err)
+		}
+		if stxns != nil {
+			stxn = stxns[i]
+		}
+		state.Txns[i] = composerStateTxn{
+			Txn:    txnWithSigner.Txn,
+			Method: c.GetMethodJSON(i),
+			Signer: signerState,
+			Signed: stxn,
+		}
+	}
+	return state, nil
+}
+
+// Export serializes the state of this composer into a msgpack document, which can be persisted or
+// sent to another device and restored with `ImportAtomicTransactionComposer()`.
+//
+// The document contains the transactions of the group, the method JSON of each method call, the
+// status of the composer, and the signatures gathered so far. Signers are recorded by kind and
+// address only: multisig definitions and logic signatures are included, but private keys and
+// external signers are not, and must be re-bound on import.
+//
+// A composer which was submitted is imported as SIGNED, since whether its group was committed is
+// only known to the network.
+func (c *AtomicTransactionComposer) Export() ([]byte, error) {
+	state, err := c.exportState()
+	if err != nil {
+		return nil, err
+	}
+	return msgpack.Encode(&state), nil
+}
+
+// ExportJSON serializes the state of this composer into a JSON document. See `Export()`.
+func (c *AtomicTransactionComposer) ExportJSON() (string, error) {
+	state, err := c.exportState()
+	if err != nil {
+		return "", err
+	}
+	return string(json.Encode(&state)), nil
+}
+
+// verifySignedTransaction returns an error unless stx holds exactly one of a signature, a multisig
+// signature, or a logic signature, which is valid for the account authorizing the transaction: its
+// AuthAddr if set, otherwise its sender.
+func verifySignedTransaction(stx types.SignedTxn) error {
+	authorizer := stx.Txn.Sender
+	if !stx.AuthAddr.IsZero() {
+		authorizer = stx.AuthAddr
+	}
+	hasSig := stx.Sig != types.Signature{}
+	hasMsig := !stx.Msig.Blank()
+	hasLsig := !stx.Lsig.Blank()
+	count := 0
+	for _, has := range []bool{hasSig, hasMsig, hasLsig} {
+		if has {
+			count++
+		}
+	}
+	if count != 1 {
+		return errors.New("signed transaction must have exactly one of a signature, a multisig signature or a logic signature")
+	}
+
+	switch {
+	case hasSig:
+		if !verifyTransactionSignature(authorizer[:], stx.Txn, stx.Sig) {
+			return fmt.Errorf("signature is not valid for %s", authorizer)
+		}
+	case hasMsig:
+		bytesToSign := bytes.Join([][]byte{txidPrefix, msgpack.Encode(stx.Txn)}, nil)
+		if !crypto.VerifyMultisig(authorizer, bytesToSign, stx.Msig) {
+			return fmt.Errorf("multisig signature is not valid for %s", authorizer)
+		}
+	case hasLsig:
+		lsigHasSig, lsigHasMsig, lsigHasLMsig, _ := stx.Lsig.SignatureCount()
+		// a logic signature delegated by a single key is verified against the authorizer
+		lsigAddr := authorizer
+		if !lsigHasSig {
+			lsigAddr = crypto.LogicSigAddress(stx.Lsig)
+			if lsigHasMsig || lsigHasLMsig {
+				msig := stx.Lsig.Msig
+				if lsigHasLMsig {
+					msig = stx.Lsig.LMsig
+				}
+				account, err := crypto.MultisigAccountFromSig(msig)
+				if err != nil {
+					return err
+				}
+				lsigAddr, err = account.Address()
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if lsigAddr != authorizer || !crypto.VerifyLogicSig(stx.Lsig, authorizer) {
+			return fmt.Errorf("logic signature is not valid for %s", authorizer)
+		}
+	}
+	return nil
+}
+
+// checkSignedTransaction returns an error unless the signature of stx is valid, see
+// verifySignedTransaction, and is made by signer if the address of signer is known.
+func checkSignedTransaction(stx types.SignedTxn, signer transaction.TransactionSigner) error {
+	err := verifySignedTransaction(stx)
+	if err != nil {
+		return err
+	}
+	expected, _, err := describeSigner(signer, stx.Txn)
+	if err != nil {
+		return err
+	}
+	switch expected.Kind {
+	case signerKindAddress, signerKindMultisig, signerKindLogicSig:
+		authorizer := stx.Txn.Sender
+		if !stx.AuthAddr.IsZero() {
+			authorizer = stx.AuthAddr
+		}
+		if authorizer != expected.Address {
+			return fmt.Errorf("transaction is authorized by %s, not by its signer %s", authorizer, expected.Address)
+		}
+	}
+	return nil
+}
+
+// AttachSignedTransaction records a signature for the transaction at the given index, obtained
+// outside of the composer, for example from a co-signer. The transaction will not be signed again by
+// `GatherSignatures()`, and the signature is included by `Export()`.
+//
+// The signature must be valid for the AuthAddr of the signed transaction, or its sender if it has
+// none. If the composer's signer for the transaction is an account, multisig or logic signature,
+// the authorizing address must also be the address of that signer. Whether the authorizing address
+// is the current auth address of the sender on chain is not checked.
+//
+// The composer's status must be BUILT, so that the signed transaction includes the group ID.
+func (c *AtomicTransactionComposer) AttachSignedTransaction(index int, encodedSignedTx []byte) error {
+	if c.value.GetStatus() != transaction.BUILT {
+		return errors.New("status must be BUILT in order to attach signed transactions")
+	}
+	txnsWithSigners, err := c.value.BuildGroup()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(txnsWithSigners) {
+		return fmt.Errorf("transaction index %d out of range", index)
+	}
+	var stx types.SignedTxn
+	err = msgpack.Decode(encodedSignedTx, &stx)
+	if err != nil {
+		return err
+	}
+	txID := crypto.TransactionIDString(txnsWithSigners[index].Txn)
+	if crypto.TransactionIDString(stx.Txn) != txID {
+		return fmt.Errorf("signed transaction does not match transaction %d", index)
+	}
+
+	fallback := txnsWithSigners[index].Signer
+	if presigned, ok := fallback.(*presignedSigner); ok {
+		fallback = presigned.fallback
+	}
+	err = checkSignedTransaction(stx, fallback)
+	if err != nil {
+		return fmt.Errorf("signed transaction %d: %w", index, err)
+	}
+	txnsWithSigners[index].Signer = &presignedSigner{stxn: encodedSignedTx, txID: txID, fallback: fallback}
+
+	// The signers of a built group cannot be changed, so the group is built again
+	var rebuilt transaction.AtomicTransactionComposer
+	for _, txnWithSigner := range txnsWithSigners {
+		txnWithSigner.Txn.Group = types.Digest{}
+		err = rebuilt.AddTransaction(txnWithSigner)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = rebuilt.BuildGroup()
+	if err != nil {
+		return err
+	}
+	c.value = rebuilt
+	return nil
+}
+
+// HasSignature returns true if a signature was gathered or attached for the transaction at the
+// given index.
+func (c *AtomicTransactionComposer) HasSignature(index int) bool {
+	if c.value.GetStatus() >= transaction.SIGNED {
+		return index >= 0 && index < c.value.Count()
+	}
+	if c.value.GetStatus() != transaction.BUILT {
+		return false
+	}
+	txnsWithSigners, err := c.value.BuildGroup()
+	if err != nil || index < 0 || index >= len(txnsWithSigners) {
+		return false
+	}
+	_, ok := txnsWithSigners[index].Signer.(*presignedSigner)
+	return ok
+}
+
+// SignerBindings maps addresses to the signers used for them when importing an
+// AtomicTransactionComposer.
+type SignerBindings struct {
+	signers map[types.Address]TransactionSigner
+}
+
+// NewSignerBindings creates an empty SignerBindings object.
+func NewSignerBindings() *SignerBindings {
+	return &SignerBindings{signers: make(map[types.Address]TransactionSigner)}
+}
+
+// Bind sets the signer for transactions signed by the given address. For multisig and logic
+// signature signers, the address is the address of the multisig or logic signature account. For
+// external signers, and transactions which were added without a signer, it is the sender.
+func (b *SignerBindings) Bind(address string, signer TransactionSigner) error {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	b.signers[addr] = signer
+	return nil
+}
+
+func (b *SignerBindings) lookup(addr types.Address) (TransactionSigner, bool) {
+	if b == nil {
+		return nil, false
+	}
+	signer, ok := b.signers[addr]
+	return signer, ok
+}
+
+func importSigner(entry composerStateTxn, bindings *SignerBindings) (transaction.TransactionSigner, error) {
+	var signer transaction.TransactionSigner
+	if bound, ok := bindings.lookup(entry.Signer.Address); ok {
+		signer = externalToInternalSigner{bound}
+	} else {
+		switch entry.Signer.Kind {
+		case signerKindLogicSig:
+			if entry.Signer.LogicSig == nil {
+				return nil, errors.New("logic signature signer has no logic signature")
+			}
+			signer = transaction.LogicSigAccountTransactionSigner{LogicSigAccount: *entry.Signer.LogicSig}
+		case signerKindNone, signerKindAddress, signerKindMultisig, signerKindExternal:
+			signer = unboundSigner{entry.Signer}
+		default:
+			return nil, fmt.Errorf("unknown signer kind: '%s'", entry.Signer.Kind)
+		}
+	}
+
+	if entry.Signed == nil {
+		return signer, nil
+	}
+	var stx types.SignedTxn
+	err := msgpack.Decode(entry.Signed, &stx)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode signed transaction: %w", err)
+	}
+	txID := crypto.TransactionIDString(entry.Txn)
+	if crypto.TransactionIDString(stx.Txn) != txID {
+		return nil, errors.New("signed transaction does not match its transaction")
+	}
+	// the document may come from a co-signer, so its signatures are checked like attached ones
+	err = checkSignedTransaction(stx, signer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+	return &presignedSigner{stxn: entry.Signed, txID: txID, fallback: signer}, nil
+}
+
+func importComposerState(state composerState, bindings *SignerBindings) (*AtomicTransactionComposer, error) {
+	if state.Version != composerStateVersion {
+		return nil, fmt.Errorf("unsupported composer state version: %d", state.Version)
+	}
+	if state.Status < transaction.BUILDING || state.Status > transaction.COMMITTED {
+		return nil, fmt.Errorf("unsupported composer status: %d", state.Status)
+	}
+	if state.Status > transaction.SIGNED {
+		state.Status = transaction.SIGNED
+	}
+
+	c := NewAtomicTransactionComposer()
+	for i, entry := range state.Txns {
+		signer, err := importSigner(entry, bindings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer for transaction %d: %w", i, err)
+		}
+		txn := entry.Txn
+		txn.Group = types.Digest{}
+		err = c.value.AddTransaction(transaction.TransactionWithSigner{Txn: txn, Signer: signer})
+		if err != nil {
+			return nil, err
+		}
+		c.methods = append(c.methods, entry.Method)
+	}
+	if state.Status == transaction.BUILDING {
+		return c, nil
+	}
+
+	txnsWithSigners, err := c.value.BuildGroup()
+	if err != nil {
+		return nil, err
+	}
+	for i, txnWithSigner := range txnsWithSigners {
+		if txnWithSigner.Txn.Group != state.Txns[i].Txn.Group {
+			return nil, fmt.Errorf("group ID of transaction %d does not match", i)
+		}
+	}
+	if state.Status == transaction.SIGNED {
+		for i, entry := range state.Txns {
+			if entry.Signed == nil {
+				return nil, fmt.Errorf("signed composer has no signature for transaction %d", i)
+			}
+		}
+		_, err = c.value.GatherSignatures()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// ImportAtomicTransactionComposer restores a composer from a msgpack document created by
+// `AtomicTransactionComposer.Export()`. The restored composer has the same status, transactions,
+// method JSON, and signatures as the exported one.
+//
+// Signers are re-bound by address using bindings, which may be nil. Logic signature signers are
+// restored from the document if no signer is bound to their address. Transactions without a bound
+// signer may still be imported, but gathering their signatures will fail unless a signature is
+// attached with `AttachSignedTransaction()`.
+//
+// Signatures in the document are verified as by `AttachSignedTransaction()`, and the import fails
+// if any of them is invalid.
+func ImportAtomicTransactionComposer(encodedState []byte, bindings *SignerBindings) (*AtomicTransactionComposer, error) {
+	var state composerState
+	err := msgpack.Decode(encodedState, &state)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode composer state: %w", err)
+	}
+	return importComposerState(state, bindings)
+}
+
+// ImportAtomicTransactionComposerJSON restores a composer from a JSON document created by
+// `AtomicTransactionComposer.ExportJSON()`. See `ImportAtomicTransactionComposer()`.
+func ImportAtomicTransactionComposerJSON(stateJSON string, bindings *SignerBindings) (*AtomicTransactionComposer, error) {
+	var state composerState
+	err := json.Decode([]byte(stateJSON), &state)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode composer state: %w", err)
+	}
+	return importComposerState(state, bindings)
+}
@@ -0,0 +1,290 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestComposerPayment(t *testing.T, sender, receiver types.Address, amount uint64) []byte {
+	t.Helper()
+	params := types.SuggestedParams{
+		Fee:             1000,
+		FlatFee:         true,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     mustDecodeB64(t, "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI="),
+		FirstRoundValid: 2,
+		LastRoundValid:  1002,
+	}
+	txn, err := transaction.MakePaymentTxn(sender.String(), receiver.String(), amount, nil, "", params)
+	require.NoError(t, err)
+	return msgpack.Encode(&txn)
+}
+
+func TestAtomicTransactionComposerExportImport(t *testing.T) {
+	t.Parallel()
+	alice := crypto.GenerateAccount()
+	bob := crypto.GenerateAccount()
+	aliceSigner, err := MakeBasicAccountSigner(alice.PrivateKey)
+	require.NoError(t, err)
+	bobSigner, err := MakeBasicAccountSigner(bob.PrivateKey)
+	require.NoError(t, err)
+
+	lsig, err := crypto.MakeLogicSigAccountEscrowChecked([]byte{0x1, 0x20, 0x1, 0x1, 0x22}, nil)
+	require.NoError(t, err)
+	lsigAddr, err := lsig.Address()
+	require.NoError(t, err)
+
+	methodJSON := `{"name":"add","args":[{"type":"uint64","name":"a"}],"returns":{"type":"uint64"}}`
+	callParams, err := NewAddMethodCallParams(1234, 0, methodJSON, &StringArray{}, &Int64Array{}, &Int64Array{}, &AppBoxRefArray{},
+		&SuggestedParams{Fee: 1000, FlatFee: true, FirstRoundValid: 2, LastRoundValid: 1002, GenesisID: "testnet-v1.0", GenesisHash: mustDecodeB64(t, "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI=")},
+		nil, bob.Address.String(), bobSigner)
+	require.NoError(t, err)
+	require.NoError(t, callParams.AddMethodArgument(`5`))
+
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, alice.Address, bob.Address, 1), aliceSigner))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, lsigAddr, alice.Address, 2), MakeLogicSigAccountSigner(&LogicSigAccount{lsig})))
+	require.NoError(t, atc.AddMethodCall(callParams))
+	require.Equal(t, "", atc.GetMethodJSON(0))
+	require.Equal(t, methodJSON, atc.GetMethodJSON(2))
+
+	// export while building, without changing the status
+	exported, err := atc.Export()
+	require.NoError(t, err)
+	require.Equal(t, transaction.BUILDING, atc.GetStatus())
+
+	bindings := NewSignerBindings()
+	require.NoError(t, bindings.Bind(alice.Address.String(), aliceSigner))
+	require.NoError(t, bindings.Bind(bob.Address.String(), bobSigner))
+	imported, err := ImportAtomicTransactionComposer(exported, bindings)
+	require.NoError(t, err)
+	require.Equal(t, transaction.BUILDING, imported.GetStatus())
+	require.Equal(t, 3, imported.Count())
+	require.Equal(t, methodJSON, imported.GetMethodJSON(2))
+
+	expected, err := atc.GatherSignatures()
+	require.NoError(t, err)
+	actual, err := imported.GatherSignatures()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	// a signed composer keeps its signatures without any bound signers
+	exportedJSON, err := atc.ExportJSON()
+	require.NoError(t, err)
+	importedSigned, err := ImportAtomicTransactionComposerJSON(exportedJSON, nil)
+	require.NoError(t, err)
+	require.Equal(t, transaction.SIGNED, importedSigned.GetStatus())
+	require.True(t, importedSigned.HasSignature(1))
+	actual, err = importedSigned.GatherSignatures()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	reexported, err := importedSigned.ExportJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, exportedJSON, reexported)
+
+	// a submitted or committed composer is exported with its status, and imported as signed
+	exportedSigned, err := atc.Export()
+	require.NoError(t, err)
+	for _, status := range []transaction.AtomicTransactionComposerStatus{transaction.SUBMITTED, transaction.COMMITTED} {
+		var state composerState
+		require.NoError(t, msgpack.Decode(exportedSigned, &state))
+		state.Status = status
+		importedSubmitted, err := ImportAtomicTransactionComposer(msgpack.Encode(&state), nil)
+		require.NoError(t, err)
+		require.Equal(t, transaction.SIGNED, importedSubmitted.GetStatus())
+		actual, err = importedSubmitted.GatherSignatures()
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+		reexported, err := importedSubmitted.Export()
+		require.NoError(t, err)
+		require.Equal(t, exportedSigned, reexported)
+	}
+}
+
+func TestAtomicTransactionComposerPartialSignatures(t *testing.T) {
+	t.Parallel()
+	alice := crypto.GenerateAccount()
+	bob := crypto.GenerateAccount()
+	aliceSigner, err := MakeBasicAccountSigner(alice.PrivateKey)
+	require.NoError(t, err)
+	bobSigner, err := MakeBasicAccountSigner(bob.PrivateKey)
+	require.NoError(t, err)
+
+	// the first device only holds alice's key
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, alice.Address, bob.Address, 1), aliceSigner))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, bob.Address, alice.Address, 2), nil))
+	group, err := atc.BuildGroup()
+	require.NoError(t, err)
+
+	var aliceTxn types.Transaction
+	require.NoError(t, msgpack.Decode(group.Get(0), &aliceTxn))
+	_, aliceStxn, err := crypto.SignTransaction(alice.PrivateKey, aliceTxn)
+	require.NoError(t, err)
+
+	require.Error(t, atc.AttachSignedTransaction(1, aliceStxn))
+	require.False(t, atc.HasSignature(0))
+	require.NoError(t, atc.AttachSignedTransaction(0, aliceStxn))
+	require.True(t, atc.HasSignature(0))
+	require.False(t, atc.HasSignature(1))
+	require.Equal(t, transaction.BUILT, atc.GetStatus())
+
+	exported, err := atc.Export()
+	require.NoError(t, err)
+
+	// without a signer for bob, the group cannot be signed
+	unbound, err := ImportAtomicTransactionComposer(exported, nil)
+	require.NoError(t, err)
+	_, err = unbound.GatherSignatures()
+	require.Error(t, err)
+
+	// the second device binds bob's key
+	bindings := NewSignerBindings()
+	require.NoError(t, bindings.Bind(bob.Address.String(), bobSigner))
+	imported, err := ImportAtomicTransactionComposer(exported, bindings)
+	require.NoError(t, err)
+	require.Equal(t, transaction.BUILT, imported.GetStatus())
+	require.True(t, imported.HasSignature(0))
+
+	importedGroup, err := imported.BuildGroup()
+	require.NoError(t, err)
+	require.Equal(t, group, importedGroup)
+
+	stxns, err := imported.GatherSignatures()
+	require.NoError(t, err)
+	require.Equal(t, aliceStxn, stxns.Get(0))
+
+	var bobTxn types.Transaction
+	require.NoError(t, msgpack.Decode(group.Get(1), &bobTxn))
+	_, bobStxn, err := crypto.SignTransaction(bob.PrivateKey, bobTxn)
+	require.NoError(t, err)
+	require.Equal(t, bobStxn, stxns.Get(1))
+}
+
+func TestAttachSignedTransactionVerification(t *testing.T) {
+	t.Parallel()
+	alice := crypto.GenerateAccount()
+	bob := crypto.GenerateAccount()
+	carol := crypto.GenerateAccount()
+	aliceSigner, err := MakeBasicAccountSigner(alice.PrivateKey)
+	require.NoError(t, err)
+	lsig, err := crypto.MakeLogicSigAccountEscrowChecked([]byte{0x1, 0x20, 0x1, 0x1, 0x22}, nil)
+	require.NoError(t, err)
+	lsigAddr, err := lsig.Address()
+	require.NoError(t, err)
+	msig, err := crypto.MultisigAccountWithParams(1, 1, []types.Address{alice.Address, bob.Address})
+	require.NoError(t, err)
+	msigAddr, err := msig.Address()
+	require.NoError(t, err)
+
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, alice.Address, bob.Address, 1), aliceSigner))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, lsigAddr, alice.Address, 2), MakeLogicSigAccountSigner(&LogicSigAccount{lsig})))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, bob.Address, alice.Address, 3), nil))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, msigAddr, alice.Address, 4), nil))
+	group, err := atc.BuildGroup()
+	require.NoError(t, err)
+	txns := make([]types.Transaction, group.Length())
+	for i := range txns {
+		txns[i] = decodeTestTxn(t, group.Get(i))
+	}
+
+	// a signature must be valid for the account authorizing the transaction
+	_, aliceStxn, err := crypto.SignTransaction(alice.PrivateKey, txns[0])
+	require.NoError(t, err)
+	forged := decodeTestSignedTxn(t, aliceStxn)
+	forged.Sig[0] ^= 0xff
+	require.ErrorContains(t, atc.AttachSignedTransaction(0, msgpack.Encode(&forged)), "signature is not valid")
+	require.ErrorContains(t, atc.AttachSignedTransaction(0, msgpack.Encode(&types.SignedTxn{Txn: txns[0]})), "exactly one")
+
+	// and the authorizing account must be the composer's signer, if it is known
+	_, bobStxn, err := crypto.SignTransaction(bob.PrivateKey, txns[0])
+	require.NoError(t, err)
+	require.Equal(t, bob.Address, decodeTestSignedTxn(t, bobStxn).AuthAddr)
+	require.ErrorContains(t, atc.AttachSignedTransaction(0, bobStxn), "not by its signer")
+	require.NoError(t, atc.AttachSignedTransaction(0, aliceStxn))
+
+	_, lsigStxn, err := crypto.SignLogicSigAccountTransaction(lsig, txns[1])
+	require.NoError(t, err)
+	otherLsig, err := crypto.MakeLogicSigAccountEscrowChecked([]byte{0x1, 0x20, 0x1, 0x2, 0x22}, nil)
+	require.NoError(t, err)
+	otherLsigStxn := decodeTestSignedTxn(t, lsigStxn)
+	otherLsigStxn.Lsig = otherLsig.Lsig
+	require.ErrorContains(t, atc.AttachSignedTransaction(1, msgpack.Encode(&otherLsigStxn)), "logic signature is not valid")
+	require.NoError(t, atc.AttachSignedTransaction(1, lsigStxn))
+
+	// without a signer in the composer, a rekeyed account may be signed for by its auth address
+	_, carolStxn, err := crypto.SignTransaction(carol.PrivateKey, txns[2])
+	require.NoError(t, err)
+	require.NoError(t, atc.AttachSignedTransaction(2, carolStxn))
+
+	_, msigStxn, err := crypto.SignMultisigTransaction(alice.PrivateKey, msig, txns[3])
+	require.NoError(t, err)
+	forged = decodeTestSignedTxn(t, msigStxn)
+	forged.Msig.Subsigs[0].Sig[0] ^= 0xff
+	require.ErrorContains(t, atc.AttachSignedTransaction(3, msgpack.Encode(&forged)), "multisig signature is not valid")
+	require.NoError(t, atc.AttachSignedTransaction(3, msigStxn))
+}
+
+func TestImportAtomicTransactionComposerTamperedSignature(t *testing.T) {
+	t.Parallel()
+	alice := crypto.GenerateAccount()
+	bob := crypto.GenerateAccount()
+	aliceSigner, err := MakeBasicAccountSigner(alice.PrivateKey)
+	require.NoError(t, err)
+
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, alice.Address, bob.Address, 1), aliceSigner))
+	require.NoError(t, atc.AddTransaction(makeTestComposerPayment(t, bob.Address, alice.Address, 2), nil))
+	group, err := atc.BuildGroup()
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, group.Get(0))
+	_, aliceStxn, err := crypto.SignTransaction(alice.PrivateKey, txn)
+	require.NoError(t, err)
+	require.NoError(t, atc.AttachSignedTransaction(0, aliceStxn))
+	exported, err := atc.Export()
+	require.NoError(t, err)
+	_, err = ImportAtomicTransactionComposer(exported, nil)
+	require.NoError(t, err)
+
+	// a document from a co-signer is rejected if a signature is forged, missing or made by
+	// another account than the signer of the transaction
+	forged := decodeTestSignedTxn(t, aliceStxn)
+	forged.Sig[0] ^= 0xff
+	_, bobStxn, err := crypto.SignTransaction(bob.PrivateKey, txn)
+	require.NoError(t, err)
+	testcases := []struct {
+		signed []byte
+		err    string
+	}{
+		{msgpack.Encode(&forged), "signature is not valid"},
+		{msgpack.Encode(&types.SignedTxn{Txn: txn}), "exactly one"},
+		{bobStxn, "not by its signer"},
+	}
+	for _, testcase := range testcases {
+		var state composerState
+		require.NoError(t, msgpack.Decode(exported, &state))
+		state.Txns[0].Signed = testcase.signed
+		imported, err := ImportAtomicTransactionComposer(msgpack.Encode(&state), nil)
+		require.ErrorContains(t, err, testcase.err)
+		require.Nil(t, imported)
+	}
+}
+
+func TestImportAtomicTransactionComposerInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := ImportAtomicTransactionComposerJSON(`{"v":2}`, nil)
+	require.Error(t, err)
+	_, err = ImportAtomicTransactionComposerJSON(`{"v":1,"status":5}`, nil)
+	require.Error(t, err)
+
+	atc, err := ImportAtomicTransactionComposerJSON(`{"v":1}`, nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, atc.Count())
+}
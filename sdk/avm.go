@@ -0,0 +1,1601 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// This file implements a stateless AVM interpreter, which evaluates LogicSig programs offline. It
+// follows the semantics of go-algorand for the opcodes available to LogicSigs, except for ecdsa,
+// vrf, elliptic curve, mimc, falcon, sumhash and json opcodes.
+
+const (
+	// logicSigBudget is the cost budget of a LogicSig. It is pooled across the LogicSigs of a group.
+	logicSigBudget = 20000
+	// logicSigMaxSize is the maximum size of the program and arguments of a LogicSig
+	logicSigMaxSize = 1000
+	// maxAVMStackDepth is the maximum number of values on the stack
+	maxAVMStackDepth = 1000
+	// maxAVMBytesLength is the maximum length of a byte value
+	maxAVMBytesLength = 4096
+	// maxAVMByteMathSize is the maximum length of the inputs of byte math opcodes
+	maxAVMByteMathSize = 64
+	// maxAVMCallDepth is the maximum depth of nested subroutine calls
+	maxAVMCallDepth = 1024
+	// avmProgramPageSize is the size of the pages of ApprovalProgramPages and ClearStateProgramPages
+	avmProgramPageSize = 4096
+
+	// rekeyingEnabledVersion is the first version which can approve rekeying and app calls
+	rekeyingEnabledVersion = 2
+	// backBranchEnabledVersion is the first version with backward branches and dynamic cost
+	backBranchEnabledVersion = 4
+)
+
+// LogicSigEvalGlobals holds the global values which a LogicSig program may read. They depend on
+// the network, since there is no node to ask.
+type LogicSigEvalGlobals struct {
+	MinTxnFee             int64
+	MinBalance            int64
+	MaxTxnLife            int64
+	LogicSigVersion       int64
+	GenesisHash           []byte
+	AssetCreateMinBalance int64
+	AssetOptInMinBalance  int64
+	PayoutsEnabled        bool
+	PayoutsGoOnlineFee    int64
+	PayoutsPercent        int64
+	PayoutsMinBalance     int64
+	PayoutsMaxBalance     int64
+}
+
+// NewLogicSigEvalGlobals returns the global values of MainNet.
+func NewLogicSigEvalGlobals() *LogicSigEvalGlobals {
+	genesisHash, _ := base64.StdEncoding.DecodeString("wGHE2Pwdvd7S12BL5FaOP20EGYesN73ktiC1qzkkit8=")
+	return &LogicSigEvalGlobals{
+		MinTxnFee:             1000,
+		MinBalance:            100000,
+		MaxTxnLife:            1000,
+		LogicSigVersion:       11,
+		GenesisHash:           genesisHash,
+		AssetCreateMinBalance: 100000,
+		AssetOptInMinBalance:  100000,
+		PayoutsEnabled:        true,
+		PayoutsGoOnlineFee:    2000000,
+		PayoutsPercent:        50,
+		PayoutsMinBalance:     30000000000,
+		PayoutsMaxBalance:     70000000000000,
+	}
+}
+
+// avmValue is a value on the AVM stack or in scratch space
+type avmValue struct {
+	isBytes bool
+	uint    uint64
+	bytes   []byte
+}
+
+func avmUint(v uint64) avmValue {
+	return avmValue{uint: v}
+}
+
+func avmBool(b bool) avmValue {
+	if b {
+		return avmValue{uint: 1}
+	}
+	return avmValue{}
+}
+
+func avmBytes(b []byte) avmValue {
+	return avmValue{isBytes: true, bytes: b}
+}
+
+func (v avmValue) String() string {
+	if v.isBytes {
+		return "0x" + hex.EncodeToString(v.bytes)
+	}
+	return strconv.FormatUint(v.uint, 10)
+}
+
+// avmFrame is a subroutine call frame
+type avmFrame struct {
+	returnIndex int
+	height      int
+	// clear is set once proto declared the arguments and return values of the subroutine
+	clear   bool
+	args    int
+	returns int
+}
+
+type avmTraceStep struct {
+	PC    int      `json:"pc"`
+	Line  string   `json:"line"`
+	Cost  int      `json:"cost"`
+	Stack []string `json:"stack"`
+}
+
+// avmEvaluation is the state of the evaluation of a LogicSig program
+type avmEvaluation struct {
+	program    *tealProgram
+	programRaw []byte
+	args       [][]byte
+	txns       []types.Transaction
+	groupIndex int
+	globals    *LogicSigEvalGlobals
+
+	index   int
+	stack   []avmValue
+	scratch [256]avmValue
+	intc    []uint64
+	bytec   [][]byte
+	frames  []avmFrame
+	cost    int
+	budget  int
+	trace   []avmTraceStep
+}
+
+func (e *avmEvaluation) push(v avmValue) error {
+	if v.isBytes && len(v.bytes) > maxAVMBytesLength {
+		return fmt.Errorf("byte value of length %d exceeds the maximum of %d", len(v.bytes), maxAVMBytesLength)
+	}
+	if len(e.stack) >= maxAVMStackDepth {
+		return errors.New("stack overflow")
+	}
+	e.stack = append(e.stack, v)
+	return nil
+}
+
+func (e *avmEvaluation) pop() (avmValue, error) {
+	if len(e.stack) == 0 {
+		return avmValue{}, errors.New("stack underflow")
+	}
+	v := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return v, nil
+}
+
+func (e *avmEvaluation) popUint() (uint64, error) {
+	v, err := e.pop()
+	if err != nil {
+		return 0, err
+	}
+	if v.isBytes {
+		return 0, errors.New("expected uint64 but got []byte")
+	}
+	return v.uint, nil
+}
+
+func (e *avmEvaluation) popBytes() ([]byte, error) {
+	v, err := e.pop()
+	if err != nil {
+		return nil, err
+	}
+	if !v.isBytes {
+		return nil, errors.New("expected []byte but got uint64")
+	}
+	return v.bytes, nil
+}
+
+// popUints pops n uint64 values, and returns them in the order they were pushed.
+func (e *avmEvaluation) popUints(n int) ([]uint64, error) {
+	values := make([]uint64, n)
+	for i := n - 1; i >= 0; i-- {
+		v, err := e.popUint()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// popByteMath pops two byte math operands, and returns them in the order they were pushed.
+func (e *avmEvaluation) popByteMath() (*big.Int, *big.Int, error) {
+	b, err := e.popBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := e.popBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(a) > maxAVMByteMathSize || len(b) > maxAVMByteMathSize {
+		return nil, nil, fmt.Errorf("byte math input exceeds %d bytes", maxAVMByteMathSize)
+	}
+	return new(big.Int).SetBytes(a), new(big.Int).SetBytes(b), nil
+}
+
+// stackIndex returns the index of the stack value n positions below the top
+func (e *avmEvaluation) stackIndex(n uint64) (int, error) {
+	if n >= uint64(len(e.stack)) {
+		return 0, fmt.Errorf("stack depth %d exceeds the stack height %d", n, len(e.stack))
+	}
+	return len(e.stack) - 1 - int(n), nil
+}
+
+// jump moves the evaluation to the instruction at offset
+func (e *avmEvaluation) jump(offset int) error {
+	index, _ := e.program.instructionAt(offset)
+	if e.program.version < backBranchEnabledVersion && index <= e.index {
+		return fmt.Errorf("backward branch is not allowed in version %d", e.program.version)
+	}
+	e.index = index
+	return nil
+}
+
+// avmOpCost returns the static cost of an opcode
+func avmOpCost(name string, version uint64) int {
+	switch name {
+	case "sha256":
+		if version == 1 {
+			return 7
+		}
+		return 35
+	case "keccak256":
+		if version == 1 {
+			return 26
+		}
+		return 130
+	case "sha512_256":
+		if version == 1 {
+			return 9
+		}
+		return 45
+	case "sha3_256":
+		return 130
+	case "ed25519verify", "ed25519verify_bare":
+		return 1900
+	case "divmodw", "b/", "b*", "b%":
+		return 20
+	case "b+", "b-", "expw":
+		return 10
+	case "b|", "b&", "b^", "b~":
+		return 6
+	case "sqrt":
+		return 4
+	case "bsqrt":
+		return 40
+	}
+	return 1
+}
+
+// EvaluateLogicSig evaluates the program of a LogicSig for the transaction at index of a group,
+// entirely offline, and returns whether the program approves the transaction. The LogicSig's
+// arguments are available to the program, and globals gives the network parameters which the
+// program may read. If globals is nil, the parameters of MainNet are used.
+//
+// An error is returned if the inputs cannot be decoded. A program which fails is not an error, but a
+// result which does not approve the transaction, with the reason of the failure. So is a LogicSig
+// whose program and arguments are larger than the 1000 bytes the network accepts.
+//
+// The cost budget is the budget of the LogicSigs of the whole group, pooled for this LogicSig, so
+// a group with other LogicSigs may still exceed its budget. Application and ledger state are not
+// available offline, so programs using them fail.
+func EvaluateLogicSig(account *LogicSigAccount, txns *BytesArray, index int, globals *LogicSigEvalGlobals) (*LogicSigEvalResult, error) {
+	decodedTxns, err := decodeTxns(txns)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(decodedTxns) {
+		return nil, fmt.Errorf("transaction index %d is out of range for a group of %d", index, len(decodedTxns))
+	}
+	if globals == nil {
+		globals = NewLogicSigEvalGlobals()
+	}
+	programRaw := account.value.Lsig.Logic
+	program, err := NewTEALDisassembler().decode(programRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &avmEvaluation{
+		program:    program,
+		programRaw: programRaw,
+		args:       account.value.Lsig.Args,
+		txns:       decodedTxns,
+		groupIndex: index,
+		globals:    globals,
+		budget:     logicSigBudget * len(decodedTxns),
+	}
+	result := &LogicSigEvalResult{}
+	err = e.run()
+	if err == nil {
+		err = e.finish()
+	}
+	if err != nil {
+		result.reason = err.Error()
+	} else {
+		result.approved = true
+	}
+	result.cost = e.cost
+	result.trace = e.trace
+	result.stack = e.stack
+	return result, nil
+}
+
+// check applies the checks done before a program runs
+func (e *avmEvaluation) check() error {
+	size := len(e.programRaw)
+	for _, arg := range e.args {
+		size += len(arg)
+	}
+	if size > logicSigMaxSize {
+		return fmt.Errorf("program and arguments are %d bytes, more than the maximum of %d", size, logicSigMaxSize)
+	}
+	version := e.program.version
+	if int64(version) > e.globals.LogicSigVersion {
+		return fmt.Errorf("program version %d is not supported, the maximum is %d", version, e.globals.LogicSigVersion)
+	}
+	if version < rekeyingEnabledVersion {
+		for _, txn := range e.txns {
+			if !txn.RekeyTo.IsZero() {
+				return fmt.Errorf("program version %d cannot approve a group which rekeys", version)
+			}
+			if txn.Type == types.ApplicationCallTx {
+				return fmt.Errorf("program version %d cannot approve a group with application calls", version)
+			}
+		}
+	}
+	if version < backBranchEnabledVersion {
+		// the cost of earlier versions is the cost of every instruction of the program
+		staticCost := 0
+		for i := range e.program.instructions {
+			staticCost += avmOpCost(e.program.instructions[i].name(), version)
+		}
+		if staticCost > logicSigBudget {
+			return fmt.Errorf("static cost %d exceeds the budget of %d", staticCost, logicSigBudget)
+		}
+	}
+	return nil
+}
+
+func (e *avmEvaluation) run() error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	for e.index < len(e.program.instructions) {
+		inst := &e.program.instructions[e.index]
+		e.cost += avmOpCost(inst.name(), e.program.version)
+		done, err := e.step(inst)
+		if err == nil && e.cost > e.budget {
+			err = fmt.Errorf("dynamic cost %d exceeds the budget of %d", e.cost, e.budget)
+		}
+
+		stack := make([]string, len(e.stack))
+		for i, v := range e.stack {
+			stack[i] = v.String()
+		}
+		e.trace = append(e.trace, avmTraceStep{
+			PC:    inst.offset,
+			Line:  e.program.instructionText(inst),
+			Cost:  e.cost,
+			Stack: stack,
+		})
+		if err != nil {
+			return fmt.Errorf("pc=%d %s: %w", inst.offset, inst.name(), err)
+		}
+		if done {
+			return nil
+		}
+	}
+	if len(e.stack) != 1 {
+		return fmt.Errorf("stack finished with %d values, expected 1", len(e.stack))
+	}
+	return nil
+}
+
+// finish checks the value which the program ended with
+func (e *avmEvaluation) finish() error {
+	if len(e.stack) == 0 {
+		return errors.New("stack is empty at the end of the program")
+	}
+	top := e.stack[len(e.stack)-1]
+	if top.isBytes {
+		return errors.New("program ended with a []byte value")
+	}
+	if top.uint == 0 {
+		return errors.New("program rejected the transaction")
+	}
+	return nil
+}
+
+// step executes an instruction, and returns true if the program returned.
+func (e *avmEvaluation) step(inst *tealInstruction) (bool, error) {
+	next := e.index + 1
+	switch inst.name() {
+	case "err":
+		return false, errors.New("err opcode executed")
+	case "return":
+		v, err := e.popUint()
+		if err != nil {
+			return false, err
+		}
+		e.stack = []avmValue{avmUint(v)}
+		return true, nil
+	case "assert":
+		v, err := e.popUint()
+		if err != nil {
+			return false, err
+		}
+		if v == 0 {
+			return false, errors.New("assert failed")
+		}
+	case "b":
+		return false, e.jump(inst.targets[0])
+	case "bz", "bnz":
+		v, err := e.popUint()
+		if err != nil {
+			return false, err
+		}
+		if (v == 0) == (inst.name() == "bz") {
+			return false, e.jump(inst.targets[0])
+		}
+	case "switch":
+		v, err := e.popUint()
+		if err != nil {
+			return false, err
+		}
+		if v < uint64(len(inst.targets)) {
+			return false, e.jump(inst.targets[v])
+		}
+	case "match":
+		b, err := e.pop()
+		if err != nil {
+			return false, err
+		}
+		n := len(inst.targets)
+		if len(e.stack) < n {
+			return false, errors.New("stack underflow")
+		}
+		candidates := e.stack[len(e.stack)-n:]
+		e.stack = e.stack[:len(e.stack)-n]
+		for i, candidate := range candidates {
+			if candidate.isBytes == b.isBytes && candidate.uint == b.uint && bytes.Equal(candidate.bytes, b.bytes) {
+				return false, e.jump(inst.targets[i])
+			}
+		}
+	case "callsub":
+		if len(e.frames) >= maxAVMCallDepth {
+			return false, errors.New("call stack overflow")
+		}
+		e.frames = append(e.frames, avmFrame{returnIndex: next, height: len(e.stack)})
+		return false, e.jump(inst.targets[0])
+	case "retsub":
+		if len(e.frames) == 0 {
+			return false, errors.New("retsub with an empty call stack")
+		}
+		frame := e.frames[len(e.frames)-1]
+		e.frames = e.frames[:len(e.frames)-1]
+		if frame.clear {
+			if len(e.stack) < frame.height+frame.returns {
+				return false, fmt.Errorf("retsub expected %d return values", frame.returns)
+			}
+			start := frame.height - frame.args
+			copy(e.stack[start:], e.stack[len(e.stack)-frame.returns:])
+			e.stack = e.stack[:start+frame.returns]
+		}
+		e.index = frame.returnIndex
+		return false, nil
+	case "proto":
+		if len(e.frames) == 0 {
+			return false, errors.New("proto outside of a subroutine")
+		}
+		frame := &e.frames[len(e.frames)-1]
+		frame.clear = true
+		frame.args = int(inst.uints[0])
+		frame.returns = int(inst.uints[1])
+		if frame.height < frame.args {
+			return false, fmt.Errorf("proto expected %d arguments", frame.args)
+		}
+	case "frame_dig", "frame_bury":
+		if len(e.frames) == 0 || !e.frames[len(e.frames)-1].clear {
+			return false, fmt.Errorf("%s without proto", inst.name())
+		}
+		frame := e.frames[len(e.frames)-1]
+		offset, _ := strconv.Atoi(inst.immediates[0])
+		var v avmValue
+		if inst.name() == "frame_bury" {
+			var err error
+			if v, err = e.pop(); err != nil {
+				return false, err
+			}
+		}
+		position := frame.height + offset
+		if position < frame.height-frame.args || position >= len(e.stack) {
+			return false, fmt.Errorf("frame offset %d is out of range", offset)
+		}
+		if inst.name() == "frame_bury" {
+			e.stack[position] = v
+		} else if err := e.push(e.stack[position]); err != nil {
+			return false, err
+		}
+	default:
+		if err := e.stepValue(inst); err != nil {
+			return false, err
+		}
+	}
+	e.index = next
+	return false, nil
+}
+
+// stepValue executes an instruction which does not change the control flow
+func (e *avmEvaluation) stepValue(inst *tealInstruction) error {
+	switch inst.name() {
+	case "intcblock":
+		e.intc = e.intc[:0]
+		for _, c := range inst.constants {
+			e.intc = append(e.intc, c.uint)
+		}
+		return nil
+	case "bytecblock":
+		e.bytec = e.bytec[:0]
+		for _, c := range inst.constants {
+			e.bytec = append(e.bytec, c.bytes)
+		}
+		return nil
+	case "intc", "intc_0", "intc_1", "intc_2", "intc_3":
+		index := constantIndex(inst)
+		if index >= len(e.intc) {
+			return fmt.Errorf("intc %d is beyond the %d constants", index, len(e.intc))
+		}
+		return e.push(avmUint(e.intc[index]))
+	case "bytec", "bytec_0", "bytec_1", "bytec_2", "bytec_3":
+		index := constantIndex(inst)
+		if index >= len(e.bytec) {
+			return fmt.Errorf("bytec %d is beyond the %d constants", index, len(e.bytec))
+		}
+		return e.push(avmBytes(e.bytec[index]))
+	case "pushint", "pushbytes":
+		if inst.value.isBytes {
+			return e.push(avmBytes(inst.value.bytes))
+		}
+		return e.push(avmUint(inst.value.uint))
+	case "pushints", "pushbytess":
+		for _, c := range inst.constants {
+			v := avmUint(c.uint)
+			if c.isBytes {
+				v = avmBytes(c.bytes)
+			}
+			if err := e.push(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "arg", "arg_0", "arg_1", "arg_2", "arg_3", "args":
+		var index uint64
+		switch inst.name() {
+		case "arg":
+			index = inst.uints[0]
+		case "args":
+			var err error
+			if index, err = e.popUint(); err != nil {
+				return err
+			}
+		default:
+			index = uint64(inst.name()[4] - '0')
+		}
+		if index >= uint64(len(e.args)) {
+			return fmt.Errorf("cannot load arg[%d] of %d", index, len(e.args))
+		}
+		return e.push(avmBytes(e.args[index]))
+	case "load", "store", "loads", "stores":
+		return e.stepScratch(inst)
+	case "txn", "gtxn", "txna", "gtxna", "gtxns", "gtxnsa", "txnas", "gtxnas", "gtxnsas":
+		return e.stepTxn(inst)
+	case "global":
+		v, err := e.globalField(inst.field())
+		if err != nil {
+			return err
+		}
+		return e.push(v)
+	case "pop", "dup", "dup2", "dig", "swap", "select", "cover", "uncover", "bury", "popn", "dupn":
+		return e.stepStack(inst)
+	case "sha256", "keccak256", "sha512_256", "sha3_256", "ed25519verify", "ed25519verify_bare":
+		return e.stepCrypto(inst)
+	case "concat", "substring", "substring3", "getbit", "setbit", "getbyte", "setbyte", "extract",
+		"extract3", "extract_uint16", "extract_uint32", "extract_uint64", "replace2", "replace3",
+		"base64_decode", "bzero", "len", "itob", "btoi":
+		return e.stepBytes(inst)
+	case "b+", "b-", "b/", "b*", "b%", "b<", "b>", "b<=", "b>=", "b==", "b!=", "b|", "b&", "b^", "b~", "bsqrt":
+		return e.stepByteMath(inst)
+	case "==", "!=":
+		b, err := e.pop()
+		if err != nil {
+			return err
+		}
+		a, err := e.pop()
+		if err != nil {
+			return err
+		}
+		if a.isBytes != b.isBytes {
+			return errors.New("cannot compare uint64 to []byte")
+		}
+		equal := a.uint == b.uint && bytes.Equal(a.bytes, b.bytes)
+		return e.push(avmBool(equal == (inst.name() == "==")))
+	case "bitlen":
+		v, err := e.pop()
+		if err != nil {
+			return err
+		}
+		if v.isBytes {
+			return e.push(avmUint(uint64(new(big.Int).SetBytes(v.bytes).BitLen())))
+		}
+		return e.push(avmUint(uint64(bits.Len64(v.uint))))
+	}
+	return e.stepArithmetic(inst)
+}
+
+func (e *avmEvaluation) stepScratch(inst *tealInstruction) error {
+	switch inst.name() {
+	case "load":
+		return e.push(e.scratch[inst.uints[0]])
+	case "store":
+		v, err := e.pop()
+		if err != nil {
+			return err
+		}
+		e.scratch[inst.uints[0]] = v
+		return nil
+	case "loads":
+		index, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		if index >= uint64(len(e.scratch)) {
+			return fmt.Errorf("invalid scratch slot %d", index)
+		}
+		return e.push(e.scratch[index])
+	}
+	v, err := e.pop()
+	if err != nil {
+		return err
+	}
+	index, err := e.popUint()
+	if err != nil {
+		return err
+	}
+	if index >= uint64(len(e.scratch)) {
+		return fmt.Errorf("invalid scratch slot %d", index)
+	}
+	e.scratch[index] = v
+	return nil
+}
+
+func (e *avmEvaluation) stepTxn(inst *tealInstruction) error {
+	groupIndex := uint64(e.groupIndex)
+	immediates := inst.uints
+	switch inst.name() {
+	case "gtxn", "gtxna", "gtxnas":
+		groupIndex = immediates[0]
+		immediates = immediates[1:]
+	}
+	var arrayIndex uint64
+	hasIndex := false
+	switch inst.name() {
+	case "txna", "gtxna", "gtxnsa":
+		arrayIndex = immediates[0]
+		hasIndex = true
+	case "txnas", "gtxnas", "gtxnsas":
+		var err error
+		if arrayIndex, err = e.popUint(); err != nil {
+			return err
+		}
+		hasIndex = true
+	}
+	switch inst.name() {
+	case "gtxns", "gtxnsa", "gtxnsas":
+		var err error
+		if groupIndex, err = e.popUint(); err != nil {
+			return err
+		}
+	}
+	if groupIndex >= uint64(len(e.txns)) {
+		return fmt.Errorf("transaction index %d is beyond the group size %d", groupIndex, len(e.txns))
+	}
+	v, err := txnFieldValue(e.txns[groupIndex], int(groupIndex), inst.field(), arrayIndex, hasIndex)
+	if err != nil {
+		return err
+	}
+	return e.push(v)
+}
+
+// txnFieldValue returns the value of a transaction field. Array fields need an index.
+func txnFieldValue(txn types.Transaction, groupIndex int, field string, index uint64, hasIndex bool) (avmValue, error) {
+	var array [][]byte
+	var uintArray []uint64
+	switch field {
+	case "ApplicationArgs":
+		array = txn.ApplicationArgs
+	case "Accounts":
+		array = [][]byte{txn.Sender[:]}
+		for _, account := range txn.Accounts {
+			array = append(array, append([]byte(nil), account[:]...))
+		}
+	case "Assets":
+		uintArray = []uint64{}
+		for _, asset := range txn.ForeignAssets {
+			uintArray = append(uintArray, uint64(asset))
+		}
+	case "Applications":
+		uintArray = []uint64{uint64(txn.ApplicationID)}
+		for _, app := range txn.ForeignApps {
+			uintArray = append(uintArray, uint64(app))
+		}
+	case "ApprovalProgramPages", "ClearStateProgramPages":
+		program := txn.ApprovalProgram
+		if field == "ClearStateProgramPages" {
+			program = txn.ClearStateProgram
+		}
+		array = programPages(program)
+	}
+	if array != nil || uintArray != nil {
+		if !hasIndex {
+			return avmValue{}, fmt.Errorf("%s is an array field", field)
+		}
+		if array != nil {
+			if index >= uint64(len(array)) {
+				return avmValue{}, fmt.Errorf("invalid %s index %d", field, index)
+			}
+			return avmBytes(array[index]), nil
+		}
+		if index >= uint64(len(uintArray)) {
+			return avmValue{}, fmt.Errorf("invalid %s index %d", field, index)
+		}
+		return avmUint(uintArray[index]), nil
+	}
+	if hasIndex {
+		return avmValue{}, fmt.Errorf("%s is not an array field", field)
+	}
+
+	switch field {
+	case "Sender":
+		return avmBytes(txn.Sender[:]), nil
+	case "Fee":
+		return avmUint(uint64(txn.Fee)), nil
+	case "FirstValid":
+		return avmUint(uint64(txn.FirstValid)), nil
+	case "LastValid":
+		return avmUint(uint64(txn.LastValid)), nil
+	case "Note":
+		return avmBytes(txn.Note), nil
+	case "Lease":
+		return avmBytes(txn.Lease[:]), nil
+	case "Receiver":
+		return avmBytes(txn.Receiver[:]), nil
+	case "Amount":
+		return avmUint(uint64(txn.Amount)), nil
+	case "CloseRemainderTo":
+		return avmBytes(txn.CloseRemainderTo[:]), nil
+	case "VotePK":
+		return avmBytes(txn.VotePK[:]), nil
+	case "SelectionPK":
+		return avmBytes(txn.SelectionPK[:]), nil
+	case "VoteFirst":
+		return avmUint(uint64(txn.VoteFirst)), nil
+	case "VoteLast":
+		return avmUint(uint64(txn.VoteLast)), nil
+	case "VoteKeyDilution":
+		return avmUint(txn.VoteKeyDilution), nil
+	case "Type":
+		return avmBytes([]byte(txn.Type)), nil
+	case "TypeEnum":
+		for i, name := range []types.TxType{types.PaymentTx, types.KeyRegistrationTx, types.AssetConfigTx, types.AssetTransferTx, types.AssetFreezeTx, types.ApplicationCallTx} {
+			if txn.Type == name {
+				return avmUint(uint64(i + 1)), nil
+			}
+		}
+		return avmUint(0), nil
+	case "XferAsset":
+		return avmUint(uint64(txn.XferAsset)), nil
+	case "AssetAmount":
+		return avmUint(txn.AssetAmount), nil
+	case "AssetSender":
+		return avmBytes(txn.AssetSender[:]), nil
+	case "AssetReceiver":
+		return avmBytes(txn.AssetReceiver[:]), nil
+	case "AssetCloseTo":
+		return avmBytes(txn.AssetCloseTo[:]), nil
+	case "GroupIndex":
+		return avmUint(uint64(groupIndex)), nil
+	case "TxID":
+		return avmBytes(crypto.TransactionID(txn)), nil
+	case "ApplicationID":
+		return avmUint(uint64(txn.ApplicationID)), nil
+	case "OnCompletion":
+		return avmUint(uint64(txn.OnCompletion)), nil
+	case "NumAppArgs":
+		return avmUint(uint64(len(txn.ApplicationArgs))), nil
+	case "NumAccounts":
+		return avmUint(uint64(len(txn.Accounts))), nil
+	case "ApprovalProgram":
+		return avmBytes(txn.ApprovalProgram), nil
+	case "ClearStateProgram":
+		return avmBytes(txn.ClearStateProgram), nil
+	case "RekeyTo":
+		return avmBytes(txn.RekeyTo[:]), nil
+	case "ConfigAsset":
+		return avmUint(uint64(txn.ConfigAsset)), nil
+	case "ConfigAssetTotal":
+		return avmUint(txn.AssetParams.Total), nil
+	case "ConfigAssetDecimals":
+		return avmUint(uint64(txn.AssetParams.Decimals)), nil
+	case "ConfigAssetDefaultFrozen":
+		return avmBool(txn.AssetParams.DefaultFrozen), nil
+	case "ConfigAssetUnitName":
+		return avmBytes([]byte(txn.AssetParams.UnitName)), nil
+	case "ConfigAssetName":
+		return avmBytes([]byte(txn.AssetParams.AssetName)), nil
+	case "ConfigAssetURL":
+		return avmBytes([]byte(txn.AssetParams.URL)), nil
+	case "ConfigAssetMetadataHash":
+		return avmBytes(txn.AssetParams.MetadataHash[:]), nil
+	case "ConfigAssetManager":
+		return avmBytes(txn.AssetParams.Manager[:]), nil
+	case "ConfigAssetReserve":
+		return avmBytes(txn.AssetParams.Reserve[:]), nil
+	case "ConfigAssetFreeze":
+		return avmBytes(txn.AssetParams.Freeze[:]), nil
+	case "ConfigAssetClawback":
+		return avmBytes(txn.AssetParams.Clawback[:]), nil
+	case "FreezeAsset":
+		return avmUint(uint64(txn.FreezeAsset)), nil
+	case "FreezeAssetAccount":
+		return avmBytes(txn.FreezeAccount[:]), nil
+	case "FreezeAssetFrozen":
+		return avmBool(txn.AssetFrozen), nil
+	case "NumAssets":
+		return avmUint(uint64(len(txn.ForeignAssets))), nil
+	case "NumApplications":
+		return avmUint(uint64(len(txn.ForeignApps))), nil
+	case "GlobalNumUint":
+		return avmUint(txn.GlobalStateSchema.NumUint), nil
+	case "GlobalNumByteSlice":
+		return avmUint(txn.GlobalStateSchema.NumByteSlice), nil
+	case "LocalNumUint":
+		return avmUint(txn.LocalStateSchema.NumUint), nil
+	case "LocalNumByteSlice":
+		return avmUint(txn.LocalStateSchema.NumByteSlice), nil
+	case "ExtraProgramPages":
+		return avmUint(uint64(txn.ExtraProgramPages)), nil
+	case "Nonparticipation":
+		return avmBool(txn.Nonparticipation), nil
+	case "StateProofPK":
+		return avmBytes(txn.StateProofPK[:]), nil
+	case "NumApprovalProgramPages":
+		return avmUint(uint64(len(programPages(txn.ApprovalProgram)))), nil
+	case "NumClearStateProgramPages":
+		return avmUint(uint64(len(programPages(txn.ClearStateProgram)))), nil
+	case "RejectVersion":
+		return avmUint(txn.RejectVersion), nil
+	case "Logs", "NumLogs", "LastLog", "CreatedAssetID", "CreatedApplicationID":
+		return avmValue{}, fmt.Errorf("txn field %s is only available to applications", field)
+	}
+	return avmValue{}, fmt.Errorf("txn field %s is not available offline", field)
+}
+
+func programPages(program []byte) [][]byte {
+	var pages [][]byte
+	for start := 0; start < len(program); start += avmProgramPageSize {
+		end := start + avmProgramPageSize
+		if end > len(program) {
+			end = len(program)
+		}
+		pages = append(pages, program[start:end])
+	}
+	return pages
+}
+
+func (e *avmEvaluation) globalField(field string) (avmValue, error) {
+	g := e.globals
+	switch field {
+	case "MinTxnFee":
+		return avmUint(uint64(g.MinTxnFee)), nil
+	case "MinBalance":
+		return avmUint(uint64(g.MinBalance)), nil
+	case "MaxTxnLife":
+		return avmUint(uint64(g.MaxTxnLife)), nil
+	case "ZeroAddress":
+		return avmBytes(make([]byte, len(types.Address{}))), nil
+	case "GroupSize":
+		return avmUint(uint64(len(e.txns))), nil
+	case "LogicSigVersion":
+		return avmUint(uint64(g.LogicSigVersion)), nil
+	case "GroupID":
+		return avmBytes(e.txns[0].Group[:]), nil
+	case "GenesisHash":
+		return avmBytes(g.GenesisHash), nil
+	case "AssetCreateMinBalance":
+		return avmUint(uint64(g.AssetCreateMinBalance)), nil
+	case "AssetOptInMinBalance":
+		return avmUint(uint64(g.AssetOptInMinBalance)), nil
+	case "PayoutsEnabled":
+		return avmBool(g.PayoutsEnabled), nil
+	case "PayoutsGoOnlineFee":
+		return avmUint(uint64(g.PayoutsGoOnlineFee)), nil
+	case "PayoutsPercent":
+		return avmUint(uint64(g.PayoutsPercent)), nil
+	case "PayoutsMinBalance":
+		return avmUint(uint64(g.PayoutsMinBalance)), nil
+	case "PayoutsMaxBalance":
+		return avmUint(uint64(g.PayoutsMaxBalance)), nil
+	}
+	return avmValue{}, fmt.Errorf("global field %s is only available to applications", field)
+}
+
+func (e *avmEvaluation) stepStack(inst *tealInstruction) error {
+	var n uint64
+	if len(inst.uints) > 0 {
+		n = inst.uints[0]
+	}
+	switch inst.name() {
+	case "pop":
+		_, err := e.pop()
+		return err
+	case "popn":
+		if n > uint64(len(e.stack)) {
+			return errors.New("stack underflow")
+		}
+		e.stack = e.stack[:len(e.stack)-int(n)]
+		return nil
+	case "dup", "dupn":
+		if inst.name() == "dup" {
+			n = 1
+		}
+		if len(e.stack) == 0 {
+			return errors.New("stack underflow")
+		}
+		top := e.stack[len(e.stack)-1]
+		for i := uint64(0); i < n; i++ {
+			if err := e.push(top); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "dup2":
+		if len(e.stack) < 2 {
+			return errors.New("stack underflow")
+		}
+		a, b := e.stack[len(e.stack)-2], e.stack[len(e.stack)-1]
+		if err := e.push(a); err != nil {
+			return err
+		}
+		return e.push(b)
+	case "dig":
+		index, err := e.stackIndex(n)
+		if err != nil {
+			return err
+		}
+		return e.push(e.stack[index])
+	case "swap":
+		if len(e.stack) < 2 {
+			return errors.New("stack underflow")
+		}
+		last := len(e.stack) - 1
+		e.stack[last], e.stack[last-1] = e.stack[last-1], e.stack[last]
+		return nil
+	case "select":
+		c, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		b, err := e.pop()
+		if err != nil {
+			return err
+		}
+		a, err := e.pop()
+		if err != nil {
+			return err
+		}
+		if c != 0 {
+			return e.push(b)
+		}
+		return e.push(a)
+	case "cover":
+		index, err := e.stackIndex(n)
+		if err != nil {
+			return err
+		}
+		top := e.stack[len(e.stack)-1]
+		copy(e.stack[index+1:], e.stack[index:len(e.stack)-1])
+		e.stack[index] = top
+		return nil
+	case "uncover":
+		index, err := e.stackIndex(n)
+		if err != nil {
+			return err
+		}
+		v := e.stack[index]
+		copy(e.stack[index:], e.stack[index+1:])
+		e.stack[len(e.stack)-1] = v
+		return nil
+	}
+	// bury
+	if n == 0 {
+		return errors.New("bury 0 is not allowed")
+	}
+	index, err := e.stackIndex(n)
+	if err != nil {
+		return err
+	}
+	e.stack[index] = e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return nil
+}
+
+func (e *avmEvaluation) stepCrypto(inst *tealInstruction) error {
+	switch inst.name() {
+	case "ed25519verify", "ed25519verify_bare":
+		pk, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		sig, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		data, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		if len(pk) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+			return errors.New("invalid public key or signature length")
+		}
+		message := data
+		if inst.name() == "ed25519verify" {
+			// the data is signed along with the address of the program
+			programHash := sha512.Sum512_256(LogicSigProgramForSigning(e.programRaw))
+			message = bytes.Join([][]byte{[]byte("ProgData"), programHash[:], data}, nil)
+		}
+		return e.push(avmBool(ed25519.Verify(pk, message, sig)))
+	}
+
+	data, err := e.popBytes()
+	if err != nil {
+		return err
+	}
+	var hash []byte
+	switch inst.name() {
+	case "sha256":
+		sum := sha256.Sum256(data)
+		hash = sum[:]
+	case "keccak256":
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write(data)
+		hash = hasher.Sum(nil)
+	case "sha512_256":
+		sum := sha512.Sum512_256(data)
+		hash = sum[:]
+	case "sha3_256":
+		sum := sha3.Sum256(data)
+		hash = sum[:]
+	}
+	return e.push(avmBytes(hash))
+}
+
+// checkRange checks that start and end delimit a range of a byte value of length n
+func checkRange(start, end uint64, n int) error {
+	if start > end || end > uint64(n) {
+		return fmt.Errorf("range %d:%d is out of bounds for a value of length %d", start, end, n)
+	}
+	return nil
+}
+
+func (e *avmEvaluation) stepBytes(inst *tealInstruction) error {
+	switch inst.name() {
+	case "len":
+		b, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		return e.push(avmUint(uint64(len(b))))
+	case "itob":
+		v, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		return e.push(avmBytes(binary.BigEndian.AppendUint64(nil, v)))
+	case "btoi":
+		b, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		if len(b) > 8 {
+			return fmt.Errorf("btoi of %d bytes", len(b))
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return e.push(avmUint(v))
+	case "bzero":
+		n, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		if n > maxAVMBytesLength {
+			return fmt.Errorf("bzero of %d bytes", n)
+		}
+		return e.push(avmBytes(make([]byte, n)))
+	case "concat":
+		b, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		return e.push(avmBytes(append(append([]byte(nil), a...), b...)))
+	case "substring", "extract":
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		start, end := inst.uints[0], inst.uints[1]
+		if inst.name() == "extract" {
+			if end == 0 {
+				end = uint64(len(a))
+			} else {
+				end += start
+			}
+		}
+		if err := checkRange(start, end, len(a)); err != nil {
+			return err
+		}
+		return e.push(avmBytes(a[start:end]))
+	case "substring3", "extract3":
+		args, err := e.popUints(2)
+		if err != nil {
+			return err
+		}
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		start, end := args[0], args[1]
+		if inst.name() == "extract3" {
+			if end > math.MaxUint64-start {
+				return errors.New("extract range overflows")
+			}
+			end += start
+		}
+		if err := checkRange(start, end, len(a)); err != nil {
+			return err
+		}
+		return e.push(avmBytes(a[start:end]))
+	case "extract_uint16", "extract_uint32", "extract_uint64":
+		start, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		size := map[string]uint64{"extract_uint16": 2, "extract_uint32": 4, "extract_uint64": 8}[inst.name()]
+		if start > math.MaxUint64-size {
+			return errors.New("extract range overflows")
+		}
+		if err := checkRange(start, start+size, len(a)); err != nil {
+			return err
+		}
+		var v uint64
+		for _, c := range a[start : start+size] {
+			v = v<<8 | uint64(c)
+		}
+		return e.push(avmUint(v))
+	case "replace2", "replace3":
+		b, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		var start uint64
+		if inst.name() == "replace2" {
+			start = inst.uints[0]
+		} else if start, err = e.popUint(); err != nil {
+			return err
+		}
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		if start > math.MaxUint64-uint64(len(b)) {
+			return errors.New("replace range overflows")
+		}
+		if err := checkRange(start, start+uint64(len(b)), len(a)); err != nil {
+			return err
+		}
+		replaced := append([]byte(nil), a...)
+		copy(replaced[start:], b)
+		return e.push(avmBytes(replaced))
+	case "getbyte", "getbit":
+		index, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		a, err := e.pop()
+		if err != nil {
+			return err
+		}
+		if inst.name() == "getbyte" {
+			if !a.isBytes {
+				return errors.New("getbyte expects []byte")
+			}
+			if index >= uint64(len(a.bytes)) {
+				return fmt.Errorf("getbyte index %d is beyond the length %d", index, len(a.bytes))
+			}
+			return e.push(avmUint(uint64(a.bytes[index])))
+		}
+		if !a.isBytes {
+			if index >= 64 {
+				return fmt.Errorf("getbit index %d is beyond 64 bits", index)
+			}
+			return e.push(avmUint(a.uint >> index & 1))
+		}
+		if index >= uint64(len(a.bytes))*8 {
+			return fmt.Errorf("getbit index %d is beyond the length %d", index, len(a.bytes)*8)
+		}
+		return e.push(avmUint(uint64(a.bytes[index/8] >> (7 - index%8) & 1)))
+	case "setbyte", "setbit":
+		c, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		index, err := e.popUint()
+		if err != nil {
+			return err
+		}
+		a, err := e.pop()
+		if err != nil {
+			return err
+		}
+		if inst.name() == "setbyte" {
+			if !a.isBytes {
+				return errors.New("setbyte expects []byte")
+			}
+			if index >= uint64(len(a.bytes)) || c > 255 {
+				return errors.New("setbyte index or value out of range")
+			}
+			b := append([]byte(nil), a.bytes...)
+			b[index] = byte(c)
+			return e.push(avmBytes(b))
+		}
+		if c > 1 {
+			return errors.New("setbit value must be 0 or 1")
+		}
+		if !a.isBytes {
+			if index >= 64 {
+				return fmt.Errorf("setbit index %d is beyond 64 bits", index)
+			}
+			return e.push(avmUint(a.uint&^(1<<index) | c<<index))
+		}
+		if index >= uint64(len(a.bytes))*8 {
+			return fmt.Errorf("setbit index %d is beyond the length %d", index, len(a.bytes)*8)
+		}
+		b := append([]byte(nil), a.bytes...)
+		mask := byte(1) << (7 - index%8)
+		if c == 1 {
+			b[index/8] |= mask
+		} else {
+			b[index/8] &^= mask
+		}
+		return e.push(avmBytes(b))
+	}
+
+	// base64_decode
+	encoded, err := e.popBytes()
+	if err != nil {
+		return err
+	}
+	e.cost += len(encoded) / 16
+	encoding := base64.URLEncoding
+	if inst.field() == "StdEncoding" {
+		encoding = base64.StdEncoding
+	}
+	if len(encoded)%4 != 0 {
+		encoding = encoding.WithPadding(base64.NoPadding)
+	}
+	decoded, err := encoding.Strict().DecodeString(string(encoded))
+	if err != nil {
+		return err
+	}
+	return e.push(avmBytes(decoded))
+}
+
+func (e *avmEvaluation) stepByteMath(inst *tealInstruction) error {
+	switch inst.name() {
+	case "b~":
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		inverted := make([]byte, len(a))
+		for i, c := range a {
+			inverted[i] = ^c
+		}
+		return e.push(avmBytes(inverted))
+	case "bsqrt":
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		if len(a) > maxAVMByteMathSize {
+			return fmt.Errorf("byte math input exceeds %d bytes", maxAVMByteMathSize)
+		}
+		return e.push(avmBytes(new(big.Int).Sqrt(new(big.Int).SetBytes(a)).Bytes()))
+	case "b|", "b&", "b^":
+		b, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		a, err := e.popBytes()
+		if err != nil {
+			return err
+		}
+		// the shorter value is padded with zeros on the left
+		if len(a) < len(b) {
+			a, b = b, a
+		}
+		result := append([]byte(nil), a...)
+		offset := len(a) - len(b)
+		for i := range result {
+			var c byte
+			if i >= offset {
+				c = b[i-offset]
+			}
+			switch inst.name() {
+			case "b|":
+				result[i] |= c
+			case "b&":
+				result[i] &= c
+			default:
+				result[i] ^= c
+			}
+		}
+		return e.push(avmBytes(result))
+	}
+
+	a, b, err := e.popByteMath()
+	if err != nil {
+		return err
+	}
+	result := new(big.Int)
+	switch inst.name() {
+	case "b+":
+		result.Add(a, b)
+	case "b-":
+		if a.Cmp(b) < 0 {
+			return errors.New("byte math would have negative result")
+		}
+		result.Sub(a, b)
+	case "b*":
+		result.Mul(a, b)
+	case "b/", "b%":
+		if b.Sign() == 0 {
+			return errors.New("division by zero")
+		}
+		if inst.name() == "b/" {
+			result.Div(a, b)
+		} else {
+			result.Mod(a, b)
+		}
+	case "b<":
+		return e.push(avmBool(a.Cmp(b) < 0))
+	case "b>":
+		return e.push(avmBool(a.Cmp(b) > 0))
+	case "b<=":
+		return e.push(avmBool(a.Cmp(b) <= 0))
+	case "b>=":
+		return e.push(avmBool(a.Cmp(b) >= 0))
+	case "b==":
+		return e.push(avmBool(a.Cmp(b) == 0))
+	case "b!=":
+		return e.push(avmBool(a.Cmp(b) != 0))
+	}
+	return e.push(avmBytes(result.Bytes()))
+}
+
+func (e *avmEvaluation) stepArithmetic(inst *tealInstruction) error {
+	var operands int
+	switch inst.name() {
+	case "!", "~", "sqrt":
+		operands = 1
+	case "+", "-", "/", "*", "<", ">", "<=", ">=", "&&", "||", "%", "|", "&", "^", "mulw", "addw", "shl", "shr", "exp", "expw":
+		operands = 2
+	case "divw":
+		operands = 3
+	case "divmodw":
+		operands = 4
+	default:
+		return fmt.Errorf("%s is not supported offline", inst.name())
+	}
+	args, err := e.popUints(operands)
+	if err != nil {
+		return err
+	}
+
+	switch inst.name() {
+	case "!":
+		return e.push(avmBool(args[0] == 0))
+	case "~":
+		return e.push(avmUint(^args[0]))
+	case "sqrt":
+		return e.push(avmUint(new(big.Int).Sqrt(new(big.Int).SetUint64(args[0])).Uint64()))
+	case "divw":
+		if args[2] == 0 || args[0] >= args[2] {
+			return errors.New("divw overflows or divides by zero")
+		}
+		quotient, _ := bits.Div64(args[0], args[1], args[2])
+		return e.push(avmUint(quotient))
+	case "divmodw":
+		dividend := new(big.Int).Lsh(new(big.Int).SetUint64(args[0]), 64)
+		dividend.Or(dividend, new(big.Int).SetUint64(args[1]))
+		divisor := new(big.Int).Lsh(new(big.Int).SetUint64(args[2]), 64)
+		divisor.Or(divisor, new(big.Int).SetUint64(args[3]))
+		if divisor.Sign() == 0 {
+			return errors.New("division by zero")
+		}
+		quotient, remainder := new(big.Int).QuoRem(dividend, divisor, new(big.Int))
+		for _, v := range []*big.Int{quotient, remainder} {
+			if err := e.pushWide(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	a, b := args[0], args[1]
+	switch inst.name() {
+	case "+":
+		sum, carry := bits.Add64(a, b, 0)
+		if carry != 0 {
+			return errors.New("+ overflowed")
+		}
+		return e.push(avmUint(sum))
+	case "-":
+		if b > a {
+			return errors.New("- would result negative")
+		}
+		return e.push(avmUint(a - b))
+	case "*":
+		hi, lo := bits.Mul64(a, b)
+		if hi != 0 {
+			return errors.New("* overflowed")
+		}
+		return e.push(avmUint(lo))
+	case "/", "%":
+		if b == 0 {
+			return fmt.Errorf("%s by zero", inst.name())
+		}
+		if inst.name() == "/" {
+			return e.push(avmUint(a / b))
+		}
+		return e.push(avmUint(a % b))
+	case "<":
+		return e.push(avmBool(a < b))
+	case ">":
+		return e.push(avmBool(a > b))
+	case "<=":
+		return e.push(avmBool(a <= b))
+	case ">=":
+		return e.push(avmBool(a >= b))
+	case "&&":
+		return e.push(avmBool(a != 0 && b != 0))
+	case "||":
+		return e.push(avmBool(a != 0 || b != 0))
+	case "|":
+		return e.push(avmUint(a | b))
+	case "&":
+		return e.push(avmUint(a & b))
+	case "^":
+		return e.push(avmUint(a ^ b))
+	case "mulw":
+		hi, lo := bits.Mul64(a, b)
+		if err := e.push(avmUint(hi)); err != nil {
+			return err
+		}
+		return e.push(avmUint(lo))
+	case "addw":
+		sum, carry := bits.Add64(a, b, 0)
+		if err := e.push(avmUint(carry)); err != nil {
+			return err
+		}
+		return e.push(avmUint(sum))
+	case "shl", "shr":
+		if b >= 64 {
+			return fmt.Errorf("%s by %d bits", inst.name(), b)
+		}
+		if inst.name() == "shl" {
+			return e.push(avmUint(a << b))
+		}
+		return e.push(avmUint(a >> b))
+	}
+
+	// exp and expw
+	if a == 0 && b == 0 {
+		return errors.New("0^0 is undefined")
+	}
+	result := new(big.Int).Exp(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b), nil)
+	if inst.name() == "exp" {
+		if !result.IsUint64() {
+			return errors.New("exp overflowed")
+		}
+		return e.push(avmUint(result.Uint64()))
+	}
+	if result.BitLen() > 128 {
+		return errors.New("expw overflowed")
+	}
+	return e.pushWide(result)
+}
+
+// pushWide pushes a 128-bit value as its high and low 64-bit halves
+func (e *avmEvaluation) pushWide(v *big.Int) error {
+	lo := new(big.Int).And(v, new(big.Int).SetUint64(math.MaxUint64))
+	hi := new(big.Int).Rsh(v, 64)
+	if err := e.push(avmUint(hi.Uint64())); err != nil {
+		return err
+	}
+	return e.push(avmUint(lo.Uint64()))
+}
+
+// LogicSigEvalResult is the result of evaluating a LogicSig program with EvaluateLogicSig.
+type LogicSigEvalResult struct {
+	approved bool
+	reason   string
+	cost     int
+	trace    []avmTraceStep
+	stack    []avmValue
+}
+
+// Approved returns true if the program approved the transaction.
+func (r *LogicSigEvalResult) Approved() bool {
+	return r.approved
+}
+
+// RejectReason returns why the program did not approve the transaction, or "" if it did.
+func (r *LogicSigEvalResult) RejectReason() string {
+	return r.reason
+}
+
+// Cost returns the cost of the evaluation.
+func (r *LogicSigEvalResult) Cost() int {
+	return r.cost
+}
+
+// FinalStack returns the values on the stack at the end of the evaluation. Integers are written in
+// decimal, and byte values in hex with a 0x prefix.
+func (r *LogicSigEvalResult) FinalStack() *StringArray {
+	stack := make([]string, len(r.stack))
+	for i, v := range r.stack {
+		stack[i] = v.String()
+	}
+	return &StringArray{stack}
+}
+
+// TraceJSON returns the trace of the evaluation as a JSON array, with the stack after every
+// executed instruction:
+//
+//	[{"pc": 1, "line": "intcblock 1", "cost": 1, "stack": []}, {"pc": 4, "line": "intc_0 // 1", "cost": 2, "stack": ["1"]}]
+func (r *LogicSigEvalResult) TraceJSON() string {
+	trace := r.trace
+	if trace == nil {
+		trace = []avmTraceStep{}
+	}
+	encoded, _ := json.Marshal(trace)
+	return string(encoded)
+}
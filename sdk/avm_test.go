@@ -0,0 +1,260 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testEvalReceiver = "S64XU5HQEY2XLHVUSO6RI3JL6NHC32I4LJHM32ZOM5VC4QPON7BZZRCU2E"
+
+// makeTestEvalGroup makes a group of payments to testEvalReceiver, calling modify on each
+// transaction before the group ID is assigned.
+func makeTestEvalGroup(t *testing.T, modify func(txn *types.Transaction), amounts ...uint64) *BytesArray {
+	t.Helper()
+	params := types.SuggestedParams{
+		Fee:             1000,
+		FlatFee:         true,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     mustDecodeB64(t, "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI="),
+		FirstRoundValid: 2,
+		LastRoundValid:  1002,
+	}
+	txns := &BytesArray{}
+	for _, amount := range amounts {
+		txn, err := transaction.MakePaymentTxn(testEvalReceiver, testEvalReceiver, amount, nil, "", params)
+		require.NoError(t, err)
+		if modify != nil {
+			modify(&txn)
+		}
+		txns.Append(msgpack.Encode(&txn))
+	}
+	if len(amounts) == 1 {
+		return txns
+	}
+	grouped, err := AssignGroupID(txns)
+	require.NoError(t, err)
+	return grouped
+}
+
+func evaluateTestProgram(t *testing.T, program []byte, args [][]byte, txns *BytesArray, index int) *LogicSigEvalResult {
+	t.Helper()
+	lsigAccount, err := MakeLogicSigAccountEscrow(program, &BytesArray{args})
+	require.NoError(t, err)
+	if txns == nil {
+		txns = makeTestEvalGroup(t, nil, 1)
+	}
+	result, err := EvaluateLogicSig(lsigAccount, txns, index, nil)
+	require.NoError(t, err)
+	return result
+}
+
+func pushTestBytes(b []byte) []byte {
+	return append([]byte{0x80, byte(len(b))}, b...)
+}
+
+func TestEvaluateLogicSigApprove(t *testing.T) {
+	t.Parallel()
+	// #pragma version 1
+	// int 1
+	result := evaluateTestProgram(t, []byte{0x01, 0x20, 0x01, 0x01, 0x22}, nil, nil, 0)
+	require.True(t, result.Approved())
+	require.Empty(t, result.RejectReason())
+	require.Equal(t, 2, result.Cost())
+	require.Equal(t, []string{"1"}, result.FinalStack().Extract())
+
+	var trace []struct {
+		PC    int      `json:"pc"`
+		Line  string   `json:"line"`
+		Cost  int      `json:"cost"`
+		Stack []string `json:"stack"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.TraceJSON()), &trace))
+	require.Len(t, trace, 2)
+	require.Equal(t, 1, trace[0].PC)
+	require.Equal(t, "intcblock 1", trace[0].Line)
+	require.Empty(t, trace[0].Stack)
+	require.Equal(t, "intc_0 // 1", trace[1].Line)
+	require.Equal(t, 2, trace[1].Cost)
+	require.Equal(t, []string{"1"}, trace[1].Stack)
+}
+
+func TestEvaluateLogicSigMaxSize(t *testing.T) {
+	t.Parallel()
+	// #pragma version 1
+	// int 1
+	program := []byte{0x01, 0x20, 0x01, 0x01, 0x22}
+	result := evaluateTestProgram(t, program, [][]byte{make([]byte, logicSigMaxSize-len(program))}, nil, 0)
+	require.True(t, result.Approved(), result.RejectReason())
+
+	// the program and its arguments count towards the maximum together
+	result = evaluateTestProgram(t, program, [][]byte{make([]byte, 500), make([]byte, logicSigMaxSize-len(program)-499)}, nil, 0)
+	require.False(t, result.Approved())
+	require.Contains(t, result.RejectReason(), "more than the maximum of 1000")
+	require.Zero(t, result.Cost())
+}
+
+func TestEvaluateLogicSigHashVectors(t *testing.T) {
+	t.Parallel()
+	vectors := []struct {
+		opcode byte
+		digest string
+	}{
+		{0x01, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"}, // sha256
+		{0x02, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"}, // keccak256
+		{0x03, "53048e2681941ef99b2e29b76b4c7dabe4c2d0c634fc6d46e0e2f13107e7af23"}, // sha512_256
+		{0x98, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"}, // sha3_256
+	}
+	program := []byte{0x08}
+	for _, vector := range vectors {
+		digest, err := hex.DecodeString(vector.digest)
+		require.NoError(t, err)
+		program = append(program, pushTestBytes([]byte("abc"))...)
+		program = append(program, vector.opcode)
+		program = append(program, pushTestBytes(digest)...)
+		program = append(program, 0x12, 0x44) // ==; assert
+	}
+	program = append(program, 0x81, 0x01) // pushint 1
+
+	result := evaluateTestProgram(t, program, nil, nil, 0)
+	require.True(t, result.Approved(), result.RejectReason())
+	// 35 + 130 + 45 + 130 for the hashes, and 1 for every other instruction
+	require.Equal(t, 340+4*4+1, result.Cost())
+}
+
+func TestEvaluateLogicSigVectors(t *testing.T) {
+	t.Parallel()
+	maxUint := []byte{0x81, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+	withMaxUint := func(ops ...byte) []byte {
+		return append(append([]byte{0x08}, maxUint...), ops...)
+	}
+	tests := []struct {
+		name    string
+		program []byte
+		stack   []string
+		reason  string
+	}{
+		{"expw", []byte{0x08, 0x81, 0x02, 0x81, 0x40, 0x95}, []string{"1", "0"}, "stack finished with 2 values"},
+		{"mulw", withMaxUint(0x81, 0x02, 0x1d), []string{"1", "18446744073709551614"}, "stack finished with 2 values"},
+		{"addw", withMaxUint(0x81, 0x01, 0x1e), []string{"1", "0"}, "stack finished with 2 values"},
+		{"divmodw", []byte{0x08, 0x81, 0x00, 0x81, 0x0a, 0x81, 0x00, 0x81, 0x03, 0x1f}, []string{"0", "3", "0", "1"}, "stack finished with 4 values"},
+		{"overflow", withMaxUint(0x81, 0x01, 0x08), nil, "pc=14 +: + overflowed"},
+		{"itob btoi", []byte{0x08, 0x81, 0x05, 0x16, 0x17}, []string{"5"}, ""},
+		{"extract", append(append([]byte{0x08}, pushTestBytes([]byte("abc"))...), 0x57, 0x01, 0x02), []string{"0x6263"}, "ended with a []byte value"},
+		{"substring out of bounds", append(append([]byte{0x08}, pushTestBytes([]byte("a"))...), 0x51, 0x00, 0x05), nil, "out of bounds"},
+		{"b+", append(append(append([]byte{0x08}, pushTestBytes([]byte{0xff})...), pushTestBytes([]byte{0x01})...), 0xa0), []string{"0x0100"}, "ended with a []byte value"},
+		{"uncover", []byte{0x08, 0x81, 0x01, 0x81, 0x02, 0x81, 0x03, 0x4f, 0x02}, []string{"2", "3", "1"}, "stack finished with 3 values"},
+		{"cover", []byte{0x08, 0x81, 0x01, 0x81, 0x02, 0x81, 0x03, 0x4e, 0x02}, []string{"3", "1", "2"}, "stack finished with 3 values"},
+		{"switch", []byte{0x08, 0x81, 0x01, 0x8d, 0x02, 0x00, 0x00, 0x00, 0x03, 0x81, 0x00, 0x43, 0x81, 0x07}, []string{"7"}, ""},
+		{"subroutine", []byte{
+			0x08, 0x81, 0x03, 0x81, 0x04, // pushint 3; pushint 4
+			0x88, 0x00, 0x04, // callsub add
+			0x81, 0x07, 0x12, 0x43, // pushint 7; ==; return
+			0x8a, 0x02, 0x01, // add: proto 2 1
+			0x8b, 0xfe, 0x8b, 0xff, 0x08, 0x89, // frame_dig -2; frame_dig -1; +; retsub
+		}, []string{"1"}, ""},
+		{"zero", []byte{0x08, 0x81, 0x00}, []string{"0"}, "program rejected the transaction"},
+		{"err", []byte{0x08, 0x00}, nil, "err opcode executed"},
+		{"assert", []byte{0x08, 0x81, 0x00, 0x44}, nil, "assert failed"},
+		{"backward branch", []byte{0x03, 0x42, 0xff, 0xfd}, nil, "backward branch is not allowed in version 3"},
+		{"budget", []byte{0x08, 0x42, 0xff, 0xfd}, nil, "dynamic cost 20001 exceeds the budget of 20000"},
+		{"missing arg", []byte{0x08, 0x2d}, nil, "cannot load arg[0] of 0"},
+		{"application field", []byte{0x08, 0x31, 0x3b}, nil, "txn field NumLogs is only available to applications"},
+		{"application opcode", []byte{0x08, 0x81, 0x00, 0x60}, nil, "balance is not supported offline"},
+		{"unsupported version", []byte{0x0c, 0x81, 0x01}, nil, "program version 12 is not supported"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := evaluateTestProgram(t, test.program, nil, nil, 0)
+			require.Equal(t, test.reason == "", result.Approved())
+			require.Contains(t, result.RejectReason(), test.reason)
+			if test.stack != nil {
+				require.Equal(t, test.stack, result.FinalStack().Extract())
+			}
+		})
+	}
+}
+
+func TestEvaluateLogicSigTransactionFields(t *testing.T) {
+	t.Parallel()
+	receiver, err := types.DecodeAddress(testEvalReceiver)
+	require.NoError(t, err)
+	program := []byte{
+		0x08,
+		0x31, 0x01, 0x32, 0x00, 0x0e, // txn Fee; global MinTxnFee; <=
+		0x31, 0x20, 0x32, 0x03, 0x12, 0x10, // txn RekeyTo; global ZeroAddress; ==; &&
+		0x33, 0x01, 0x08, 0x81, 0x02, 0x12, 0x10, // gtxn 1 Amount; pushint 2; ==; &&
+		0x32, 0x04, 0x81, 0x02, 0x12, 0x10, // global GroupSize; pushint 2; ==; &&
+		0x31, 0x10, 0x81, 0x01, 0x12, 0x10, // txn TypeEnum; pushint 1; ==; &&
+	}
+	program = append(program, 0x31, 0x07) // txn Receiver
+	program = append(program, pushTestBytes(receiver[:])...)
+	program = append(program, 0x12, 0x10) // ==; &&
+
+	result := evaluateTestProgram(t, program, nil, makeTestEvalGroup(t, nil, 1, 2), 0)
+	require.True(t, result.Approved(), result.RejectReason())
+	result = evaluateTestProgram(t, program, nil, makeTestEvalGroup(t, nil, 1, 3), 0)
+	require.False(t, result.Approved())
+	require.Equal(t, "program rejected the transaction", result.RejectReason())
+
+	rekeyed := makeTestEvalGroup(t, func(txn *types.Transaction) {
+		txn.RekeyTo = receiver
+	}, 1, 2)
+	result = evaluateTestProgram(t, program, nil, rekeyed, 0)
+	require.False(t, result.Approved())
+
+	// version 1 programs cannot approve rekeying groups
+	result = evaluateTestProgram(t, []byte{0x01, 0x20, 0x01, 0x01, 0x22}, nil, rekeyed, 0)
+	require.Contains(t, result.RejectReason(), "cannot approve a group which rekeys")
+
+	// gtxn beyond the group
+	result = evaluateTestProgram(t, program, nil, makeTestEvalGroup(t, nil, 1), 0)
+	require.Contains(t, result.RejectReason(), "transaction index 1 is beyond the group size 1")
+
+	lsigAccount, err := MakeLogicSigAccountEscrow(program, nil)
+	require.NoError(t, err)
+	_, err = EvaluateLogicSig(lsigAccount, makeTestEvalGroup(t, nil, 1), 1, nil)
+	require.Error(t, err)
+	_, err = EvaluateLogicSig(lsigAccount, &BytesArray{[][]byte{{0x01}}}, 0, nil)
+	require.Error(t, err)
+
+	// the caller provides the global values
+	globals := NewLogicSigEvalGlobals()
+	globals.MinTxnFee = 999
+	result, err = EvaluateLogicSig(lsigAccount, makeTestEvalGroup(t, nil, 1, 2), 0, globals)
+	require.NoError(t, err)
+	require.False(t, result.Approved())
+}
+
+func TestEvaluateLogicSigEd25519Verify(t *testing.T) {
+	t.Parallel()
+	pk, sk, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	// arg_0; arg_1; pushbytes pk; ed25519verify
+	program := append([]byte{0x08, 0x2d, 0x2e}, pushTestBytes(pk)...)
+	program = append(program, 0x04)
+
+	data := []byte("withdraw 5 Algos")
+	programHash := sha512.Sum512_256(LogicSigProgramForSigning(program))
+	sig := ed25519.Sign(sk, bytes.Join([][]byte{[]byte("ProgData"), programHash[:], data}, nil))
+	result := evaluateTestProgram(t, program, [][]byte{data, sig}, nil, 0)
+	require.True(t, result.Approved(), result.RejectReason())
+	require.Equal(t, 1903, result.Cost())
+
+	// a signature of the data alone is only valid for ed25519verify_bare
+	bareSig := ed25519.Sign(sk, data)
+	result = evaluateTestProgram(t, program, [][]byte{data, bareSig}, nil, 0)
+	require.False(t, result.Approved())
+	program[len(program)-1] = 0x84
+	result = evaluateTestProgram(t, program, [][]byte{data, bareSig}, nil, 0)
+	require.True(t, result.Approved(), result.RejectReason())
+}
@@ -0,0 +1,467 @@
+package sdk
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// tealConstant is a constant pushed by an instruction, either directly or from a constant block
+type tealConstant struct {
+	isBytes bool
+	uint    uint64
+	bytes   []byte
+}
+
+// tealInstruction is a single decoded instruction of a TEAL program
+type tealInstruction struct {
+	offset     int
+	size       int
+	spec       *tealOpSpec
+	immediates []string
+	// uints holds the numeric immediates, in order
+	uints []uint64
+	// fields holds the names of the field immediates, in order
+	fields []string
+	// constants holds the values of intcblock, bytecblock, pushints and pushbytess
+	constants []tealConstant
+	// value is the constant pushed by intc, bytec, pushint and pushbytes instructions
+	value *tealConstant
+	// targets holds the offsets of branch targets
+	targets []int
+	comment string
+}
+
+func (inst *tealInstruction) name() string {
+	return inst.spec.name
+}
+
+func (inst *tealInstruction) field() string {
+	if len(inst.fields) == 0 {
+		return ""
+	}
+	return inst.fields[0]
+}
+
+// tealProgram is a decoded TEAL program
+type tealProgram struct {
+	version      uint64
+	length       int
+	instructions []tealInstruction
+	// labels maps branch target offsets to label names
+	labels map[int]string
+}
+
+// instructionAt returns the index of the instruction at offset, or len(instructions) when offset is
+// the end of the program.
+func (p *tealProgram) instructionAt(offset int) (int, bool) {
+	i := sort.Search(len(p.instructions), func(i int) bool {
+		return p.instructions[i].offset >= offset
+	})
+	if i == len(p.instructions) {
+		return i, offset == p.length
+	}
+	return i, p.instructions[i].offset == offset
+}
+
+type tealReader struct {
+	program []byte
+	pc      int
+}
+
+func (r *tealReader) readByte() (byte, error) {
+	if r.pc >= len(r.program) {
+		return 0, fmt.Errorf("unexpected end of program at offset %d", r.pc)
+	}
+	b := r.program[r.pc]
+	r.pc++
+	return b, nil
+}
+
+func (r *tealReader) readInt16() (int, error) {
+	if r.pc+2 > len(r.program) {
+		return 0, fmt.Errorf("unexpected end of program at offset %d", r.pc)
+	}
+	v := int16(binary.BigEndian.Uint16(r.program[r.pc:]))
+	r.pc += 2
+	return int(v), nil
+}
+
+func (r *tealReader) readVarUint() (uint64, error) {
+	v, n := binary.Uvarint(r.program[r.pc:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varuint at offset %d", r.pc)
+	}
+	r.pc += n
+	return v, nil
+}
+
+func (r *tealReader) readBytes() ([]byte, error) {
+	start := r.pc
+	length, err := r.readVarUint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(r.program)-r.pc) {
+		return nil, fmt.Errorf("byte constant at offset %d exceeds the program length", start)
+	}
+	b := r.program[r.pc : r.pc+int(length)]
+	r.pc += int(length)
+	return b, nil
+}
+
+// TEALDisassembler turns TEAL bytecode back into source text
+type TEALDisassembler struct {
+	// methods maps ABI method selectors to method signatures
+	methods map[string]string
+}
+
+// NewTEALDisassembler creates a new TEALDisassembler
+func NewTEALDisassembler() *TEALDisassembler {
+	return &TEALDisassembler{methods: make(map[string]string)}
+}
+
+// AddMethodSignature registers an ABI method, so 4-byte constants matching its selector are
+// annotated with the method signature.
+func (d *TEALDisassembler) AddMethodSignature(signature string) error {
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		return err
+	}
+	d.methods[string(method.GetSelector())] = method.GetSignature()
+	return nil
+}
+
+// describeBytes returns a human readable description of a byte constant, or "" if there is none.
+func (d *TEALDisassembler) describeBytes(b []byte) string {
+	if len(b) == 4 {
+		if signature, ok := d.methods[string(b)]; ok {
+			return fmt.Sprintf("method %q", signature)
+		}
+	}
+	if len(b) == len(types.Address{}) {
+		var addr types.Address
+		copy(addr[:], b)
+		return "addr " + addr.String()
+	}
+	if len(b) == 0 {
+		return ""
+	}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return strconv.Quote(string(b))
+}
+
+func tealBytesText(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func fieldName(names []string, index byte) (string, bool) {
+	if int(index) >= len(names) {
+		return "", false
+	}
+	return names[index], true
+}
+
+// decode parses program into its instructions, resolving constants and branch targets.
+func (d *TEALDisassembler) decode(program []byte) (*tealProgram, error) {
+	if len(program) == 0 {
+		return nil, errors.New("program is empty")
+	}
+	version, n := binary.Uvarint(program)
+	if n <= 0 {
+		return nil, errors.New("invalid program version")
+	}
+	if version == 0 || version > maxTEALVersion {
+		return nil, fmt.Errorf("unsupported program version %d", version)
+	}
+
+	result := &tealProgram{
+		version: version,
+		length:  len(program),
+		labels:  make(map[int]string),
+	}
+	var intc []uint64
+	var bytec [][]byte
+	r := &tealReader{program: program, pc: n}
+	for r.pc < len(program) {
+		offset := r.pc
+		opcode, _ := r.readByte()
+		spec := tealOpsByCode[opcode]
+		if spec == nil {
+			return nil, fmt.Errorf("invalid opcode 0x%02x at offset %d", opcode, offset)
+		}
+		if spec.version > version {
+			return nil, fmt.Errorf("%s at offset %d requires version %d", spec.name, offset, spec.version)
+		}
+
+		inst := tealInstruction{offset: offset, spec: spec}
+		var relativeTargets []int
+		for _, imm := range spec.immediates {
+			switch imm.kind {
+			case immUint8:
+				b, err := r.readByte()
+				if err != nil {
+					return nil, err
+				}
+				inst.uints = append(inst.uints, uint64(b))
+				inst.immediates = append(inst.immediates, strconv.Itoa(int(b)))
+			case immInt8:
+				b, err := r.readByte()
+				if err != nil {
+					return nil, err
+				}
+				inst.immediates = append(inst.immediates, strconv.Itoa(int(int8(b))))
+			case immField:
+				b, err := r.readByte()
+				if err != nil {
+					return nil, err
+				}
+				name, ok := fieldName(imm.fields, b)
+				if !ok {
+					return nil, fmt.Errorf("invalid field %d for %s at offset %d", b, spec.name, offset)
+				}
+				inst.fields = append(inst.fields, name)
+				inst.immediates = append(inst.immediates, name)
+			case immLabel:
+				target, err := r.readInt16()
+				if err != nil {
+					return nil, err
+				}
+				relativeTargets = append(relativeTargets, target)
+			case immLabels:
+				count, err := r.readByte()
+				if err != nil {
+					return nil, err
+				}
+				for i := 0; i < int(count); i++ {
+					target, err := r.readInt16()
+					if err != nil {
+						return nil, err
+					}
+					relativeTargets = append(relativeTargets, target)
+				}
+			case immVarUint:
+				v, err := r.readVarUint()
+				if err != nil {
+					return nil, err
+				}
+				inst.uints = append(inst.uints, v)
+				inst.immediates = append(inst.immediates, strconv.FormatUint(v, 10))
+				inst.value = &tealConstant{uint: v}
+			case immBytes:
+				b, err := r.readBytes()
+				if err != nil {
+					return nil, err
+				}
+				inst.immediates = append(inst.immediates, tealBytesText(b))
+				inst.value = &tealConstant{isBytes: true, bytes: b}
+				inst.comment = d.describeBytes(b)
+			case immVarUints:
+				count, err := r.readVarUint()
+				if err != nil {
+					return nil, err
+				}
+				for i := uint64(0); i < count; i++ {
+					v, err := r.readVarUint()
+					if err != nil {
+						return nil, err
+					}
+					inst.constants = append(inst.constants, tealConstant{uint: v})
+					inst.immediates = append(inst.immediates, strconv.FormatUint(v, 10))
+				}
+			case immBytesList:
+				count, err := r.readVarUint()
+				if err != nil {
+					return nil, err
+				}
+				var descriptions []string
+				for i := uint64(0); i < count; i++ {
+					b, err := r.readBytes()
+					if err != nil {
+						return nil, err
+					}
+					inst.constants = append(inst.constants, tealConstant{isBytes: true, bytes: b})
+					inst.immediates = append(inst.immediates, tealBytesText(b))
+					if description := d.describeBytes(b); description != "" {
+						descriptions = append(descriptions, description)
+					}
+				}
+				inst.comment = strings.Join(descriptions, ", ")
+			}
+		}
+		inst.size = r.pc - offset
+		for _, relative := range relativeTargets {
+			target := r.pc + relative
+			if target < n || target > len(program) {
+				return nil, fmt.Errorf("branch target %d of %s at offset %d is outside the program", target, spec.name, offset)
+			}
+			inst.targets = append(inst.targets, target)
+			result.labels[target] = ""
+		}
+
+		// resolve constants loaded from the constant blocks
+		switch spec.name {
+		case "intcblock":
+			intc = intc[:0]
+			for _, c := range inst.constants {
+				intc = append(intc, c.uint)
+			}
+		case "bytecblock":
+			bytec = bytec[:0]
+			for _, c := range inst.constants {
+				bytec = append(bytec, c.bytes)
+			}
+		case "intc", "intc_0", "intc_1", "intc_2", "intc_3":
+			index := constantIndex(&inst)
+			if index < len(intc) {
+				inst.value = &tealConstant{uint: intc[index]}
+				inst.comment = strconv.FormatUint(intc[index], 10)
+			}
+		case "bytec", "bytec_0", "bytec_1", "bytec_2", "bytec_3":
+			index := constantIndex(&inst)
+			if index < len(bytec) {
+				inst.value = &tealConstant{isBytes: true, bytes: bytec[index]}
+				inst.comment = d.describeBytes(bytec[index])
+			}
+		}
+		result.instructions = append(result.instructions, inst)
+	}
+
+	targets := make([]int, 0, len(result.labels))
+	for target := range result.labels {
+		if _, ok := result.instructionAt(target); !ok {
+			return nil, fmt.Errorf("branch target %d is not the start of an instruction", target)
+		}
+		targets = append(targets, target)
+	}
+	sort.Ints(targets)
+	for i, target := range targets {
+		result.labels[target] = fmt.Sprintf("label%d", i+1)
+	}
+	return result, nil
+}
+
+// constantIndex returns the constant block index used by an intc or bytec instruction
+func constantIndex(inst *tealInstruction) int {
+	name := inst.name()
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		return int(name[i+1] - '0')
+	}
+	return int(inst.uints[0])
+}
+
+func (p *tealProgram) instructionText(inst *tealInstruction) string {
+	parts := append([]string{inst.name()}, inst.immediates...)
+	for _, target := range inst.targets {
+		parts = append(parts, p.labels[target])
+	}
+	text := strings.Join(parts, " ")
+	if inst.comment != "" {
+		text += " // " + inst.comment
+	}
+	return text
+}
+
+// Disassemble turns a compiled TEAL program into source text. Branch targets are replaced with
+// labels, and constants are annotated with their values, addresses and known method signatures.
+func (d *TEALDisassembler) Disassemble(program []byte) (string, error) {
+	decoded, err := d.decode(program)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#pragma version %d\n", decoded.version)
+	for i := range decoded.instructions {
+		inst := &decoded.instructions[i]
+		if label, ok := decoded.labels[inst.offset]; ok {
+			sb.WriteString(label + ":\n")
+		}
+		sb.WriteString(decoded.instructionText(inst) + "\n")
+	}
+	if label, ok := decoded.labels[decoded.length]; ok {
+		sb.WriteString(label + ":\n")
+	}
+	return sb.String(), nil
+}
+
+type tealInstructionJSON struct {
+	Offset     int      `json:"offset"`
+	Label      string   `json:"label,omitempty"`
+	Opcode     byte     `json:"opcode"`
+	Name       string   `json:"name"`
+	Immediates []string `json:"immediates,omitempty"`
+	Targets    []string `json:"targets,omitempty"`
+	Comment    string   `json:"comment,omitempty"`
+	Text       string   `json:"text"`
+}
+
+type tealProgramJSON struct {
+	Version      uint64                `json:"version"`
+	Instructions []tealInstructionJSON `json:"instructions"`
+	EndLabel     string                `json:"endLabel,omitempty"`
+}
+
+// DisassembleJSON decodes a compiled TEAL program into a structured list of instructions, encoded
+// as JSON. Each instruction has its offset, opcode, name, immediates, branch target labels, the
+// annotation of the constant it uses and its source text.
+func (d *TEALDisassembler) DisassembleJSON(program []byte) (string, error) {
+	decoded, err := d.decode(program)
+	if err != nil {
+		return "", err
+	}
+
+	result := tealProgramJSON{
+		Version:      decoded.version,
+		Instructions: make([]tealInstructionJSON, len(decoded.instructions)),
+		EndLabel:     decoded.labels[decoded.length],
+	}
+	for i := range decoded.instructions {
+		inst := &decoded.instructions[i]
+		var targets []string
+		for _, target := range inst.targets {
+			targets = append(targets, decoded.labels[target])
+		}
+		result.Instructions[i] = tealInstructionJSON{
+			Offset:     inst.offset,
+			Label:      decoded.labels[inst.offset],
+			Opcode:     inst.spec.opcode,
+			Name:       inst.name(),
+			Immediates: inst.immediates,
+			Targets:    targets,
+			Comment:    inst.comment,
+			Text:       decoded.instructionText(inst),
+		}
+	}
+	encoded, err := json.Marshal(result)
+	return string(encoded), err
+}
+
+// DisassembleTEAL turns a compiled TEAL program into source text. See TEALDisassembler.Disassemble.
+func DisassembleTEAL(program []byte) (string, error) {
+	return NewTEALDisassembler().Disassemble(program)
+}
+
+// DisassembleTEALJSON decodes a compiled TEAL program into a structured list of instructions. See
+// TEALDisassembler.DisassembleJSON.
+func DisassembleTEALJSON(program []byte) (string, error) {
+	return NewTEALDisassembler().DisassembleJSON(program)
+}
+
+// Disassemble returns the source text of the LogicSig program, so it can be reviewed before it is
+// signed or used.
+func (lsa *LogicSigAccount) Disassemble() (string, error) {
+	return DisassembleTEAL(lsa.value.Lsig.Logic)
+}
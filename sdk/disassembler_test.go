@@ -0,0 +1,146 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestTEALProgram(t *testing.T) []byte {
+	method, err := abi.MethodFromSignature("hello(string)string")
+	require.NoError(t, err)
+
+	program := []byte{
+		0x08,                   // #pragma version 8
+		0x20, 0x02, 0x01, 0x00, // intcblock 1 0
+		0x26, 0x02, 0x04, // bytecblock, with a selector and the zero address
+	}
+	program = append(program, method.GetSelector()...)
+	program = append(program, 0x20)
+	program = append(program, make([]byte, 32)...)
+	program = append(program,
+		0x31, 0x10, // txn TypeEnum
+		0x22,             // intc_0
+		0x12,             // ==
+		0x41, 0x00, 0x03, // bz label1
+		0x28,       // bytec_0
+		0x29,       // bytec_1
+		0x48,       // pop
+		0x81, 0x01, // label1: pushint 1
+		0x43,                               // return
+		0x8d, 0x02, 0xff, 0xf7, 0x00, 0x00, // switch label1 label2
+	)
+	return program
+}
+
+func TestDisassembleTEAL(t *testing.T) {
+	t.Parallel()
+	program := makeTestTEALProgram(t)
+	selector := program[8:12]
+	zeroAddress := "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ"
+
+	text, err := DisassembleTEAL(program)
+	require.NoError(t, err)
+	expected := strings.Join([]string{
+		"#pragma version 8",
+		"intcblock 1 0",
+		"bytecblock 0x" + hex.EncodeToString(selector) + " 0x" + strings.Repeat("00", 32) + " // addr " + zeroAddress,
+		"txn TypeEnum",
+		"intc_0 // 1",
+		"==",
+		"bz label1",
+		"bytec_0",
+		"bytec_1 // addr " + zeroAddress,
+		"pop",
+		"label1:",
+		"pushint 1",
+		"return",
+		"switch label1 label2",
+		"label2:",
+	}, "\n") + "\n"
+	require.Equal(t, expected, text)
+
+	// registered methods are recognized by their selectors
+	disassembler := NewTEALDisassembler()
+	require.NoError(t, disassembler.AddMethodSignature("hello(string)string"))
+	require.Error(t, disassembler.AddMethodSignature("not a method"))
+	text, err = disassembler.Disassemble(program)
+	require.NoError(t, err)
+	require.Contains(t, text, "bytec_0 // method \"hello(string)string\"\n")
+
+	encoded, err := disassembler.DisassembleJSON(program)
+	require.NoError(t, err)
+	var decoded struct {
+		Version      int `json:"version"`
+		Instructions []struct {
+			Offset     int      `json:"offset"`
+			Label      string   `json:"label"`
+			Opcode     int      `json:"opcode"`
+			Name       string   `json:"name"`
+			Immediates []string `json:"immediates"`
+			Targets    []string `json:"targets"`
+			Comment    string   `json:"comment"`
+			Text       string   `json:"text"`
+		} `json:"instructions"`
+		EndLabel string `json:"endLabel"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(encoded), &decoded))
+	require.Equal(t, 8, decoded.Version)
+	require.Len(t, decoded.Instructions, 12)
+	require.Equal(t, "label2", decoded.EndLabel)
+
+	txn := decoded.Instructions[2]
+	require.Equal(t, 45, txn.Offset)
+	require.Equal(t, 0x31, txn.Opcode)
+	require.Equal(t, "txn", txn.Name)
+	require.Equal(t, []string{"TypeEnum"}, txn.Immediates)
+
+	require.Equal(t, []string{"label1"}, decoded.Instructions[5].Targets)
+	require.Equal(t, "method \"hello(string)string\"", decoded.Instructions[6].Comment)
+	require.Equal(t, "label1", decoded.Instructions[9].Label)
+	require.Equal(t, "pushint 1", decoded.Instructions[9].Text)
+	require.Equal(t, []string{"label1", "label2"}, decoded.Instructions[11].Targets)
+}
+
+func TestDisassembleTEALInvalidPrograms(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		program []byte
+		err     string
+	}{
+		{"empty", nil, "program is empty"},
+		{"version 0", []byte{0x00, 0x01}, "unsupported program version 0"},
+		{"future version", []byte{0x0d, 0x01}, "unsupported program version 13"},
+		{"unknown opcode", []byte{0x08, 0x6f}, "invalid opcode 0x6f at offset 1"},
+		{"opcode from a later version", []byte{0x01, 0x42, 0x00, 0x00}, "b at offset 1 requires version 2"},
+		{"truncated immediate", []byte{0x02, 0x31}, "unexpected end of program"},
+		{"truncated bytes", []byte{0x03, 0x80, 0x05, 0x01}, "exceeds the program length"},
+		{"invalid field", []byte{0x02, 0x32, 0xff}, "invalid field 255 for global"},
+		{"branch outside the program", []byte{0x02, 0x42, 0x00, 0x05}, "outside the program"},
+		{"branch into an instruction", []byte{0x02, 0x42, 0x00, 0x01, 0x31, 0x00}, "not the start of an instruction"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DisassembleTEAL(test.program)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestLogicSigAccountDisassemble(t *testing.T) {
+	t.Parallel()
+	// #pragma version 1
+	// int 1
+	program := []byte{0x01, 0x20, 0x01, 0x01, 0x22}
+	lsigAccount, err := MakeLogicSigAccountEscrow(program, nil)
+	require.NoError(t, err)
+
+	text, err := lsigAccount.Disassemble()
+	require.NoError(t, err)
+	require.Equal(t, "#pragma version 1\nintcblock 1\nintc_0 // 1\n", text)
+}
@@ -11,3 +11,7 @@ var errWrongKeyLen = errors.New("wrong key length") // TODO: check the actual er
 
 // transaction
 var errNegativeArgument = errors.New("all integer arguments must be >= 0")
+
+// async signer
+var errSignRequestTimedOut = errors.New("sign request timed out")
+var errSignRequestCancelled = errors.New("sign request was cancelled")
@@ -13,6 +13,7 @@ func MakeKeyRegTxn(
 	voteKey, selectionKey string,
 	voteFirst, voteLast, voteKeyDilution *Uint64,
 	suggestedParams *SuggestedParams,
+	options *TransactionOptions,
 ) (txn []byte, err error) {
 	paramsConverted, err := convertSuggestedParams(suggestedParams)
 	if err != nil {
@@ -49,6 +50,11 @@ func MakeKeyRegTxn(
 		return nil, fmt.Errorf("failed to construct key reg txn: %v", err)
 	}
 
+	err = applyTransactionOptions(&txnObj, options, paramsConverted)
+	if err != nil {
+		return nil, err
+	}
+
 	txn = msgpack.Encode(&txnObj)
 
 	return txn, nil
@@ -61,6 +67,7 @@ func MakeKeyRegTxnWithStateProofKey(
 	voteKey, selectionKey, stateProofPK string,
 	voteFirst, voteLast, voteKeyDilution *Uint64,
 	nonpart bool,
+	options *TransactionOptions,
 ) (txn []byte, err error) {
 	paramsConverted, err := convertSuggestedParams(params)
 	if err != nil {
@@ -99,6 +106,11 @@ func MakeKeyRegTxnWithStateProofKey(
 		return nil, fmt.Errorf("failed to construct key reg txn: %v", err)
 	}
 
+	err = applyTransactionOptions(&txnObj, options, paramsConverted)
+	if err != nil {
+		return nil, err
+	}
+
 	txn = msgpack.Encode(&txnObj)
 
 	return txn, nil
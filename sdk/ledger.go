@@ -0,0 +1,289 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// APDU fields of the Algorand Ledger app.
+const (
+	ledgerCLA = 0x80
+
+	ledgerInsGetAddress = 0x03
+	ledgerInsSignTxn    = 0x08
+	ledgerInsSignData   = 0x10
+
+	ledgerP1Retrieve      = 0x00
+	ledgerP1Display       = 0x01
+	ledgerP1WithAccountID = 0x01
+	ledgerP1More          = 0x80
+	ledgerP2Last          = 0x00
+	ledgerP2More          = 0x80
+
+	// ledgerChunkSize is the largest payload sent in one APDU.
+	ledgerChunkSize = 250
+)
+
+// Status words returned by the Algorand Ledger app.
+const (
+	ledgerSWOK              = 0x9000
+	ledgerSWWrongLength     = 0x6700
+	ledgerSWSecurityStatus  = 0x6982
+	ledgerSWDataInvalid     = 0x6984
+	ledgerSWRejected        = 0x6985
+	ledgerSWTxnRejected     = 0x6986
+	ledgerSWWrongData       = 0x6a80
+	ledgerSWWrongP1P2       = 0x6a86
+	ledgerSWInsNotSupported = 0x6d00
+	ledgerSWCLANotSupported = 0x6e00
+	ledgerSWAppNotOpen      = 0x6e01
+	ledgerSWLocked          = 0x5515
+)
+
+// Scopes and encodings of a sign-data request, as defined by ARC-60.
+const (
+	// LedgerSignDataScopeAuth is the scope of data signed to authenticate the account to a domain.
+	// It is the only scope supported by the Algorand Ledger app.
+	LedgerSignDataScopeAuth = 1
+	// LedgerSignDataEncodingBase64 means the binary fields of the signed data are base64 encoded.
+	// It is the only encoding supported by the Algorand Ledger app.
+	LedgerSignDataEncodingBase64 = 1
+)
+
+// Kinds of LedgerError.
+const (
+	// LedgerErrorRejected means the user declined the request on the device.
+	LedgerErrorRejected = "rejected"
+	// LedgerErrorLocked means the device is locked.
+	LedgerErrorLocked = "locked"
+	// LedgerErrorAppNotOpen means the Algorand app is not open on the device.
+	LedgerErrorAppNotOpen = "app-not-open"
+	// LedgerErrorInvalidData means the app could not parse the request, for example an unsupported
+	// transaction.
+	LedgerErrorInvalidData = "invalid-data"
+	// LedgerErrorUnsupported means the app does not support the request, usually because it is
+	// outdated.
+	LedgerErrorUnsupported = "unsupported"
+	// LedgerErrorUnknown is any other status word.
+	LedgerErrorUnknown = "unknown"
+)
+
+// LedgerError is the error returned for a response of the Algorand Ledger app with a status word
+// other than success.
+type LedgerError struct {
+	statusWord int
+	kind       string
+}
+
+func newLedgerError(statusWord int) *LedgerError {
+	kind := LedgerErrorUnknown
+	switch statusWord {
+	case ledgerSWRejected, ledgerSWTxnRejected:
+		kind = LedgerErrorRejected
+	case ledgerSWLocked, ledgerSWSecurityStatus:
+		kind = LedgerErrorLocked
+	case ledgerSWAppNotOpen, ledgerSWCLANotSupported:
+		kind = LedgerErrorAppNotOpen
+	case ledgerSWDataInvalid, ledgerSWWrongData, ledgerSWWrongLength:
+		kind = LedgerErrorInvalidData
+	case ledgerSWInsNotSupported, ledgerSWWrongP1P2:
+		kind = LedgerErrorUnsupported
+	}
+	return &LedgerError{statusWord: statusWord, kind: kind}
+}
+
+func (e *LedgerError) Error() string {
+	return fmt.Sprintf("ledger returned status word 0x%04x (%s)", e.statusWord, e.kind)
+}
+
+// StatusWord returns the status word of the response.
+func (e *LedgerError) StatusWord() int {
+	return e.statusWord
+}
+
+// Kind returns the kind of the error, one of the LedgerError constants.
+func (e *LedgerError) Kind() string {
+	return e.kind
+}
+
+// CheckLedgerResponse returns the LedgerError of a response of the Algorand Ledger app, or nil if
+// the response is successful. An error is returned if the response has no status word.
+func CheckLedgerResponse(response []byte) (*LedgerError, error) {
+	_, err := ParseLedgerResponse(response)
+	if err == nil {
+		return nil, nil
+	}
+	var ledgerErr *LedgerError
+	if errors.As(err, &ledgerErr) {
+		return ledgerErr, nil
+	}
+	return nil, err
+}
+
+// ParseLedgerResponse returns the data of a response of the Algorand Ledger app, without its
+// status word. A LedgerError is returned if the status word is not success.
+func ParseLedgerResponse(response []byte) ([]byte, error) {
+	if len(response) < 2 {
+		return nil, fmt.Errorf("ledger response is %d bytes, too short for a status word", len(response))
+	}
+	data := response[:len(response)-2]
+	statusWord := int(binary.BigEndian.Uint16(response[len(response)-2:]))
+	if statusWord != ledgerSWOK {
+		return nil, newLedgerError(statusWord)
+	}
+	return data, nil
+}
+
+func ledgerAPDU(ins, p1, p2 byte, data []byte) []byte {
+	apdu := []byte{ledgerCLA, ins, p1, p2, byte(len(data))}
+	return append(apdu, data...)
+}
+
+func ledgerAccountIndex(accountIndex int64) ([]byte, error) {
+	if accountIndex < 0 || accountIndex > 0x7fffffff {
+		return nil, fmt.Errorf("account index %d must be between 0 and %d", accountIndex, 0x7fffffff)
+	}
+	encoded := make([]byte, 4)
+	binary.BigEndian.PutUint32(encoded, uint32(accountIndex))
+	return encoded, nil
+}
+
+// ledgerChunks splits the account index and payload of a sign request into APDUs. The first APDU
+// carries the account index, and P2 tells the app whether more APDUs follow.
+func ledgerChunks(ins byte, accountIndex int64, payload []byte) (*BytesArray, error) {
+	if len(payload) == 0 {
+		return nil, errors.New("payload to sign must not be empty")
+	}
+	index, err := ledgerAccountIndex(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	buffer := append(index, payload...)
+	var apdus [][]byte
+	for start := 0; start < len(buffer); start += ledgerChunkSize {
+		end := start + ledgerChunkSize
+		p2 := byte(ledgerP2More)
+		if end >= len(buffer) {
+			end = len(buffer)
+			p2 = ledgerP2Last
+		}
+		p1 := byte(ledgerP1More)
+		if start == 0 {
+			p1 = ledgerP1WithAccountID
+		}
+		apdus = append(apdus, ledgerAPDU(ins, p1, p2, buffer[start:end]))
+	}
+	return &BytesArray{apdus}, nil
+}
+
+// MakeLedgerGetAddressAPDU returns the APDU requesting the address of the account accountIndex
+// from the Algorand Ledger app. If display is true, the device shows the address for the user to
+// confirm. See ParseLedgerGetAddressResponse for the response.
+func MakeLedgerGetAddressAPDU(accountIndex int64, display bool) ([]byte, error) {
+	index, err := ledgerAccountIndex(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	p1 := byte(ledgerP1Retrieve)
+	if display {
+		p1 = ledgerP1Display
+	}
+	return ledgerAPDU(ledgerInsGetAddress, p1, ledgerP2Last, index), nil
+}
+
+// ParseLedgerGetAddressResponse returns the address in the response to a get-address APDU. The
+// response holds the public key of the account, which some versions of the app follow with the
+// address.
+func ParseLedgerGetAddressResponse(response []byte) (string, error) {
+	data, err := ParseLedgerResponse(response)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < ed25519.PublicKeySize {
+		return "", fmt.Errorf("ledger returned a %d byte public key, expected %d", len(data), ed25519.PublicKeySize)
+	}
+	var addr types.Address
+	copy(addr[:], data[:ed25519.PublicKeySize])
+	if len(data) > ed25519.PublicKeySize && string(data[ed25519.PublicKeySize:]) != addr.String() {
+		return "", errors.New("ledger returned an address which does not match its public key")
+	}
+	return addr.String(), nil
+}
+
+// MakeLedgerSignTransactionAPDUs returns the APDUs requesting the signature of the encoded
+// transaction encodedTx by the account accountIndex. They must be sent in order, and every
+// response but the last is empty. See ParseLedgerSignResponse for the last response.
+func MakeLedgerSignTransactionAPDUs(accountIndex int64, encodedTx []byte) (*BytesArray, error) {
+	var tx types.Transaction
+	err := msgpack.Decode(encodedTx, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return ledgerChunks(ledgerInsSignTxn, accountIndex, msgpack.Encode(&tx))
+}
+
+// MakeLedgerSignDataAPDUs returns the APDUs requesting the signature of an ARC-60 sign-data request
+// by the account accountIndex, sent like the APDUs of MakeLedgerSignTransactionAPDUs.
+//
+// The app does not sign raw bytes: it shows the request to the user, and only signs it if data is
+// a JSON object and authData starts with the SHA-256 hash of domain. signer is the address of the
+// account accountIndex, which the app checks against its own key. requestID may be empty.
+//
+// The request is serialized as the public key of signer, the scope and encoding bytes, then data,
+// domain, requestID and authData, each preceded by its length as a big endian uint16.
+func MakeLedgerSignDataAPDUs(accountIndex int64, signer string, scope, encoding int, data []byte, domain string, requestID, authData []byte) (*BytesArray, error) {
+	signerAddr, err := types.DecodeAddress(signer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer address: %w", err)
+	}
+	if scope != LedgerSignDataScopeAuth {
+		return nil, fmt.Errorf("unsupported sign data scope %d", scope)
+	}
+	if encoding != LedgerSignDataEncodingBase64 {
+		return nil, fmt.Errorf("unsupported sign data encoding %d", encoding)
+	}
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, errors.New("data to sign must be a JSON object")
+	}
+	if domain == "" {
+		return nil, errors.New("sign data domain must not be empty")
+	}
+	domainHash := sha256.Sum256([]byte(domain))
+	if !bytes.HasPrefix(authData, domainHash[:]) {
+		return nil, errors.New("auth data must start with the SHA-256 hash of the domain")
+	}
+
+	payload := append([]byte(nil), signerAddr[:]...)
+	payload = append(payload, byte(scope), byte(encoding))
+	for _, field := range [][]byte{data, []byte(domain), requestID, authData} {
+		if len(field) > math.MaxUint16 {
+			return nil, fmt.Errorf("sign data field of %d bytes is longer than %d", len(field), math.MaxUint16)
+		}
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(field)))
+		payload = append(payload, field...)
+	}
+	return ledgerChunks(ledgerInsSignData, accountIndex, payload)
+}
+
+// ParseLedgerSignResponse returns the signature in the response to the last APDU of a sign
+// request.
+func ParseLedgerSignResponse(response []byte) ([]byte, error) {
+	data, err := ParseLedgerResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("ledger returned a %d byte signature, expected %d", len(data), ed25519.SignatureSize)
+	}
+	return data, nil
+}
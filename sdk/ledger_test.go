@@ -0,0 +1,196 @@
+package sdk
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(t *testing.T, value string) []byte {
+	t.Helper()
+	decoded, err := hex.DecodeString(value)
+	require.NoError(t, err)
+	return decoded
+}
+
+// ledgerExchange is an APDU sent to the Algorand Ledger app and the response of the app, in hex.
+type ledgerExchange struct {
+	command  string
+	response string
+}
+
+// The traces below are exchanges with the Algorand Ledger app for account 0, which holds the key
+// of the seed 000102...1f. The signatures in the responses are signatures of that key.
+const (
+	testLedgerAddress   = "AOQQPP7TZYIL4HLQ3UMOOS6ATFT6JVRQTOSQ2XY53SDGIESVGG4MPFYUMQ"
+	testLedgerPublicKey = "03a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b8"
+)
+
+// testLedgerTrace checks that commands are the commands of trace, and returns the data of the
+// last response. Every response but the last must be empty.
+func testLedgerTrace(t *testing.T, trace []ledgerExchange, commands [][]byte) []byte {
+	t.Helper()
+	require.Len(t, commands, len(trace))
+	var data []byte
+	for i, exchange := range trace {
+		require.Equal(t, exchange.command, hex.EncodeToString(commands[i]), "command %d", i)
+		var err error
+		data, err = ParseLedgerResponse(mustDecodeHex(t, exchange.response))
+		require.NoError(t, err)
+		if i < len(trace)-1 {
+			require.Empty(t, data)
+		}
+	}
+	return data
+}
+
+func TestLedgerGetAddress(t *testing.T) {
+	t.Parallel()
+	traces := []struct {
+		display bool
+		trace   ledgerExchange
+	}{
+		{false, ledgerExchange{"800300000400000000", testLedgerPublicKey + "9000"}},
+		// newer versions of the app follow the public key with the address
+		{true, ledgerExchange{"800301000400000000", testLedgerPublicKey + hex.EncodeToString([]byte(testLedgerAddress)) + "9000"}},
+	}
+	for _, testcase := range traces {
+		apdu, err := MakeLedgerGetAddressAPDU(0, testcase.display)
+		require.NoError(t, err)
+		require.Equal(t, testcase.trace.command, hex.EncodeToString(apdu))
+		address, err := ParseLedgerGetAddressResponse(mustDecodeHex(t, testcase.trace.response))
+		require.NoError(t, err)
+		require.Equal(t, testLedgerAddress, address)
+	}
+
+	apdu, err := MakeLedgerGetAddressAPDU(1, true)
+	require.NoError(t, err)
+	require.Equal(t, "800301000400000001", hex.EncodeToString(apdu))
+	_, err = MakeLedgerGetAddressAPDU(-1, false)
+	require.Error(t, err)
+	_, err = ParseLedgerGetAddressResponse(mustDecodeHex(t, testLedgerPublicKey+hex.EncodeToString([]byte("other"))+"9000"))
+	require.ErrorContains(t, err, "does not match")
+}
+
+func TestLedgerSignTransaction(t *testing.T) {
+	t.Parallel()
+	// a payment with a 200 byte note, which takes two APDUs
+	encodedTx := mustDecodeHex(t, "8aa3616d74cd03e8a3666565cd03e8a2667602a367656eac746573746e65742d76312e30a26768c4204863b518a4b3c84ec810f22d4f1081cb0f71f059a7ac20"+
+		"dec62f7f70e5093a22a26c76cd03eaa46e6f7465c4c8000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212223242526272829"+
+		"2a2b2c2d2e2f303132333435363738393a3b3c3d3e3f404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f60616263646566676869"+
+		"6a6b6c6d6e6f707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9fa0a1a2a3a4a5a6a7a8a9"+
+		"aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebfc0c1c2c3c4c5c6c7a3726376c42003a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664"+
+		"125531b8a3736e64c42003a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b8a474797065a3706179")
+	trace := []ledgerExchange{
+		{"80080180fa000000008aa3616d74cd03e8a3666565cd03e8a2667602a367656eac746573746e65742d76312e30a26768c4204863b518a4b3c84ec810f22d4f10" +
+			"81cb0f71f059a7ac20dec62f7f70e5093a22a26c76cd03eaa46e6f7465c4c8000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20" +
+			"2122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f60" +
+			"6162636465666768696a6b6c6d6e6f707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f", "9000"},
+		{"800880007da0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebfc0c1c2c3c4c5c6c7a3726376c42003a107bff3ce10be1d70dd18e7" +
+			"4bc09967e4d6309ba50d5f1ddc8664125531b8a3736e64c42003a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b8a474797065a370" +
+			"6179", "e890c8aaf6a612f796fed6ef07bd5363f5c42877c057238525b298d8667ace43186700e480dd837d963dce9c1c1202fd91fdf0e4dcd586a8e0c5c211d0251d03" + "9000"},
+	}
+
+	apdus, err := MakeLedgerSignTransactionAPDUs(0, encodedTx)
+	require.NoError(t, err)
+	signature, err := ParseLedgerSignResponse(append(testLedgerTrace(t, trace, apdus.Extract()), 0x90, 0x00))
+	require.NoError(t, err)
+
+	// the signature of the device is attached to the transaction
+	bytesToSign, err := RawTransactionBytesToSign(encodedTx)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(mustDecodeHex(t, testLedgerPublicKey), bytesToSign, signature))
+	signed, err := AttachSignature(signature, encodedTx)
+	require.NoError(t, err)
+	stx := decodeTestSignedTxn(t, signed)
+	require.Equal(t, signature, stx.Sig[:])
+
+	_, err = MakeLedgerSignTransactionAPDUs(0, []byte("invalid"))
+	require.Error(t, err)
+}
+
+func TestLedgerSignData(t *testing.T) {
+	t.Parallel()
+	data := []byte(`{"type":"arc60.create","challenge":"eSZVsYmvNCjJGH5a9WWIjKp5jm5DFxlwBBAw9zc8FZM=","origin":"https://arc60.io"}`)
+	domain := "arc60.io"
+	requestID := []byte{0x01, 0x02}
+	authData := mustDecodeHex(t, "281187fa8467178cff8d0d00caddc16d54a062eea86e4756b92fe464609aae844100000000")
+	trace := []ledgerExchange{
+		{"80100100cb0000000003a107bff3ce10be1d70dd18e74bc09967e4d6309ba50d5f1ddc8664125531b80101006e7b2274797065223a2261726336302e63726561" +
+			"7465222c226368616c6c656e6765223a2265535a5673596d764e436a4a47483561395757496a4b70356a6d354446786c7742424177397a6338465a4d3d222c22" +
+			"6f726967696e223a2268747470733a2f2f61726336302e696f227d000861726336302e696f000201020025281187fa8467178cff8d0d00caddc16d54a062eea8" +
+			"6e4756b92fe464609aae844100000000", "0e79f1f09c227ddecf66ae37d35b8a7a869a473b1a91353831533e4b3adc2b845e4f0ba534856c214b1c67a0888f6b960f8697f1553df3be135b9f91f75b2a02" + "9000"},
+	}
+
+	apdus, err := MakeLedgerSignDataAPDUs(0, testLedgerAddress, LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, data, domain, requestID, authData)
+	require.NoError(t, err)
+	signature, err := ParseLedgerSignResponse(append(testLedgerTrace(t, trace, apdus.Extract()), 0x90, 0x00))
+	require.NoError(t, err)
+
+	// the app signs the hash of the data followed by the auth data
+	dataHash := sha256.Sum256(data)
+	require.True(t, ed25519.Verify(mustDecodeHex(t, testLedgerPublicKey), append(dataHash[:], authData...), signature))
+
+	testcases := []struct {
+		name     string
+		signer   string
+		scope    int
+		encoding int
+		data     []byte
+		domain   string
+		authData []byte
+	}{
+		{"invalid signer", "invalid", LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, data, domain, authData},
+		{"unsupported scope", testLedgerAddress, 2, LedgerSignDataEncodingBase64, data, domain, authData},
+		{"unsupported encoding", testLedgerAddress, LedgerSignDataScopeAuth, 2, data, domain, authData},
+		{"data not JSON", testLedgerAddress, LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, []byte{0xab}, domain, authData},
+		{"data null", testLedgerAddress, LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, []byte("null"), domain, authData},
+		{"empty data", testLedgerAddress, LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, nil, domain, authData},
+		{"empty domain", testLedgerAddress, LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, data, "", authData},
+		{"auth data of other domain", testLedgerAddress, LedgerSignDataScopeAuth, LedgerSignDataEncodingBase64, data, "other.io", authData},
+	}
+	for _, testcase := range testcases {
+		_, err = MakeLedgerSignDataAPDUs(0, testcase.signer, testcase.scope, testcase.encoding, testcase.data, testcase.domain, nil, testcase.authData)
+		require.Error(t, err, testcase.name)
+	}
+}
+
+func TestLedgerErrors(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		response   string
+		statusWord int
+		kind       string
+	}{
+		{"6985", 0x6985, LedgerErrorRejected},
+		{"6986", 0x6986, LedgerErrorRejected},
+		{"5515", 0x5515, LedgerErrorLocked},
+		{"6e01", 0x6e01, LedgerErrorAppNotOpen},
+		{"6984", 0x6984, LedgerErrorInvalidData},
+		{"6d00", 0x6d00, LedgerErrorUnsupported},
+		{"6f00", 0x6f00, LedgerErrorUnknown},
+	}
+	for _, testcase := range testcases {
+		_, err := ParseLedgerSignResponse(mustDecodeHex(t, testcase.response))
+		var ledgerErr *LedgerError
+		require.ErrorAs(t, err, &ledgerErr, testcase.response)
+		require.Equal(t, testcase.statusWord, ledgerErr.StatusWord())
+		require.Equal(t, testcase.kind, ledgerErr.Kind())
+
+		checked, err := CheckLedgerResponse(mustDecodeHex(t, testcase.response))
+		require.NoError(t, err)
+		require.Equal(t, ledgerErr, checked)
+	}
+
+	checked, err := CheckLedgerResponse(mustDecodeHex(t, "9000"))
+	require.NoError(t, err)
+	require.Nil(t, checked)
+	_, err = CheckLedgerResponse([]byte{0x90})
+	require.ErrorContains(t, err, "too short")
+	_, err = ParseLedgerSignResponse(mustDecodeHex(t, "01029000"))
+	require.ErrorContains(t, err, "2 byte signature")
+	require.EqualError(t, newLedgerError(0x6985), "ledger returned status word 0x6985 (rejected)")
+}
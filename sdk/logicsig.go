@@ -300,6 +300,156 @@ func (lsa *LogicSigAccount) Address() (string, error) {
 	return addr.String(), nil
 }
 
+// Signature types of a LogicSigAccount, as returned by LogicSigAccount.SignatureType.
+const (
+	// LogicSigTypeEscrow is a LogicSig which is not delegated and has authority over the escrow
+	// address derived from its program.
+	LogicSigTypeEscrow = "escrow"
+	// LogicSigTypeDelegatedSig is a LogicSig delegated by a single signature account.
+	LogicSigTypeDelegatedSig = "sig"
+	// LogicSigTypeDelegatedMsig is a LogicSig delegated by a multisig account.
+	LogicSigTypeDelegatedMsig = "msig"
+)
+
+// delegatingMultisig returns the multisig signature of the LogicSig, if it was delegated by a
+// multisig account.
+func (lsa *LogicSigAccount) delegatingMultisig() (types.MultisigSig, bool) {
+	if !lsa.value.Lsig.Msig.Blank() {
+		return lsa.value.Lsig.Msig, true
+	}
+	if !lsa.value.Lsig.LMsig.Blank() {
+		return lsa.value.Lsig.LMsig, true
+	}
+	return types.MultisigSig{}, false
+}
+
+// Program returns the program of the LogicSigAccount.
+func (lsa *LogicSigAccount) Program() []byte {
+	return append([]byte(nil), lsa.value.Lsig.Logic...)
+}
+
+// Args returns the arguments which are passed to the program of the LogicSigAccount.
+func (lsa *LogicSigAccount) Args() *BytesArray {
+	args := make([][]byte, len(lsa.value.Lsig.Args))
+	for i, arg := range lsa.value.Lsig.Args {
+		args[i] = append([]byte(nil), arg...)
+	}
+	return &BytesArray{args}
+}
+
+// SignatureType returns LogicSigTypeEscrow, LogicSigTypeDelegatedSig or LogicSigTypeDelegatedMsig
+// depending on how the LogicSigAccount was delegated.
+func (lsa *LogicSigAccount) SignatureType() string {
+	if lsa.value.Lsig.Sig != (types.Signature{}) {
+		return LogicSigTypeDelegatedSig
+	}
+	if _, ok := lsa.delegatingMultisig(); ok {
+		return LogicSigTypeDelegatedMsig
+	}
+	return LogicSigTypeEscrow
+}
+
+// DelegatedSigner returns the address of the account which delegated the LogicSigAccount: the
+// signing key for a single signature delegation, or the multisig address for a multisig delegation.
+// An empty string is returned for an escrow LogicSigAccount.
+func (lsa *LogicSigAccount) DelegatedSigner() (string, error) {
+	if lsa.SignatureType() == LogicSigTypeEscrow {
+		return "", nil
+	}
+	return lsa.Address()
+}
+
+// DelegatingMultisigAccount returns the multisig account which delegated the LogicSigAccount.
+//
+// The LogicSigAccount must represent a delegated LogicSig backed by a multisig account.
+func (lsa *LogicSigAccount) DelegatingMultisigAccount() (*MultisigAccount, error) {
+	msig, ok := lsa.delegatingMultisig()
+	if !ok {
+		return nil, errors.New("LogicSigAccount is not delegated by a multisig account")
+	}
+	account, err := crypto.MultisigAccountFromSig(msig)
+	if err != nil {
+		return nil, err
+	}
+	return &MultisigAccount{account}, nil
+}
+
+// MultisigSignatureCount returns the number of members of the delegating multisig account which
+// have validly signed the program. The delegation is complete once this count reaches
+// MultisigThreshold. Signatures which do not verify are not counted.
+//
+// This returns 0 if the LogicSigAccount is not delegated by a multisig account.
+func (lsa *LogicSigAccount) MultisigSignatureCount() int {
+	msig, ok := lsa.delegatingMultisig()
+	if !ok {
+		return 0
+	}
+
+	// The legacy Msig field signs the program like a single signature, while LMsig binds the
+	// signature to the multisig address as well
+	programData := LogicSigProgramForSigning(lsa.value.Lsig.Logic)
+	if lsa.value.Lsig.Msig.Blank() {
+		account, err := crypto.MultisigAccountFromSig(msig)
+		if err != nil {
+			return 0
+		}
+		addr, err := account.Address()
+		if err != nil {
+			return 0
+		}
+		programData = bytes.Join([][]byte{[]byte("MsigProgram"), addr[:], lsa.value.Lsig.Logic}, nil)
+	}
+	count := 0
+	for _, subsig := range msig.Subsigs {
+		if subsig.Sig == (types.Signature{}) || len(subsig.Key) != ed25519.PublicKeySize {
+			continue
+		}
+		if ed25519.Verify(subsig.Key, programData, subsig.Sig[:]) {
+			count++
+		}
+	}
+	return count
+}
+
+// MultisigThreshold returns the number of signatures the delegating multisig account requires.
+//
+// This returns 0 if the LogicSigAccount is not delegated by a multisig account.
+func (lsa *LogicSigAccount) MultisigThreshold() int {
+	msig, ok := lsa.delegatingMultisig()
+	if !ok {
+		return 0
+	}
+	return int(msig.Threshold)
+}
+
+// WithArgs returns a copy of the LogicSigAccount whose program receives the arguments args instead.
+// Arguments are not covered by the delegation signature, so a delegated copy stays valid.
+func (lsa *LogicSigAccount) WithArgs(args *BytesArray) (*LogicSigAccount, error) {
+	var account crypto.LogicSigAccount
+	err := msgpack.Decode(msgpack.Encode(&lsa.value), &account)
+	if err != nil {
+		return nil, err
+	}
+
+	account.Lsig.Args = nil
+	if args != nil {
+		for _, arg := range args.Extract() {
+			account.Lsig.Args = append(account.Lsig.Args, append([]byte(nil), arg...))
+		}
+	}
+
+	return &LogicSigAccount{account}, nil
+}
+
+// Equals returns true if and only if both LogicSigAccounts have the same program, arguments and
+// delegation signatures.
+func (lsa *LogicSigAccount) Equals(other *LogicSigAccount) bool {
+	if other == nil {
+		return false
+	}
+	return bytes.Equal(msgpack.Encode(&lsa.value), msgpack.Encode(&other.value))
+}
+
 // ToJSON returns a JSON serialization of a LogicSigAccount. See DeserializeLogicSigAccountFromJSON for
 // deserialization.
 func (lsa *LogicSigAccount) ToJSON() string {
@@ -325,3 +475,37 @@ func SignLogicSigTransaction(account *LogicSigAccount, encodedTx []byte) ([]byte
 func LogicSigProgramForSigning(program []byte) []byte {
 	return bytes.Join([][]byte{[]byte("Program"), program}, []byte{})
 }
+
+// MakeLogicSigDelegationRevocationTxn constructs the rekey transaction which revokes a delegated
+// LogicSigAccount. A delegation is only valid while its signer is the authorizing address of the
+// account, so rekeying the account to newAuthAddr makes the LogicSig unable to sign for it.
+//
+// - sender is the account whose authority was delegated. If empty, the delegating address of the
+// LogicSigAccount is used, which is correct unless that account had already been rekeyed.
+// - newAuthAddr is the new authorizing address of sender, and must differ from the delegating
+// address.
+//
+// The returned transaction must be signed by the current authorizing address of sender.
+func MakeLogicSigDelegationRevocationTxn(account *LogicSigAccount, sender, newAuthAddr string, params *SuggestedParams) ([]byte, error) {
+	if !account.IsDelegated() {
+		return nil, errors.New("LogicSigAccount is not delegated")
+	}
+
+	delegator, err := account.Address()
+	if err != nil {
+		return nil, err
+	}
+	if sender == "" {
+		sender = delegator
+	}
+
+	authAddr, err := types.DecodeAddress(newAuthAddr)
+	if err != nil {
+		return nil, err
+	}
+	if authAddr.String() == delegator {
+		return nil, errors.New("rekeying to the delegating address does not revoke the delegation")
+	}
+
+	return MakeRekeyTxn(sender, newAuthAddr, params, nil)
+}
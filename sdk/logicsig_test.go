@@ -332,3 +332,164 @@ func TestLogicSigProgramForSigning(t *testing.T) {
 	expected := []byte{0x50, 0x72, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x1, 0x20, 0x1, 0x1, 0x22}
 	require.Equal(t, expected, actual)
 }
+
+func TestLogicSigAccountGetters(t *testing.T) {
+	t.Parallel()
+	program := []byte{0x1, 0x20, 0x1, 0x1, 0x22}
+	args := [][]byte{
+		{0x01},
+		{0x02, 0x03},
+	}
+
+	escrow, err := MakeLogicSigAccountEscrow(program, &BytesArray{args})
+	require.NoError(t, err)
+	require.Equal(t, program, escrow.Program())
+	require.Equal(t, args, escrow.Args().Extract())
+	require.Equal(t, LogicSigTypeEscrow, escrow.SignatureType())
+	signer, err := escrow.DelegatedSigner()
+	require.NoError(t, err)
+	require.Empty(t, signer)
+	require.Equal(t, 0, escrow.MultisigSignatureCount())
+	require.Equal(t, 0, escrow.MultisigThreshold())
+	_, err = escrow.DelegatingMultisigAccount()
+	require.Error(t, err)
+
+	// the returned values are copies
+	escrow.Program()[0] = 0xff
+	escrow.Args().Get(0)[0] = 0xff
+	require.Equal(t, program, escrow.value.Lsig.Logic)
+	require.Equal(t, args, escrow.value.Lsig.Args)
+
+	ma, acct1, acct2, _ := makeTestMultisigAccount(t)
+	delegated, err := MakeLogicSigAccountDelegatedSign(program, nil, acct1.PrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, LogicSigTypeDelegatedSig, delegated.SignatureType())
+	require.Equal(t, 0, delegated.Args().Length())
+	signer, err = delegated.DelegatedSigner()
+	require.NoError(t, err)
+	require.Equal(t, acct1.Address.String(), signer)
+
+	msigDelegated, err := MakeLogicSigAccountDelegatedMsig(program, nil, ma)
+	require.NoError(t, err)
+	require.Equal(t, LogicSigTypeDelegatedMsig, msigDelegated.SignatureType())
+	require.Equal(t, 2, msigDelegated.MultisigThreshold())
+	require.Equal(t, 0, msigDelegated.MultisigSignatureCount())
+	msigAddr, err := ma.Address()
+	require.NoError(t, err)
+	signer, err = msigDelegated.DelegatedSigner()
+	require.NoError(t, err)
+	require.Equal(t, msigAddr, signer)
+	delegatingAccount, err := msigDelegated.DelegatingMultisigAccount()
+	require.NoError(t, err)
+	require.Equal(t, ma.value, delegatingAccount.value)
+
+	require.NoError(t, msigDelegated.AppendSignMultisigSignature(acct1.PrivateKey))
+	require.Equal(t, 1, msigDelegated.MultisigSignatureCount())
+
+	// a signature over another program is not counted
+	otherSignature := ed25519.Sign(acct2.PrivateKey, LogicSigProgramForSigning([]byte{0x1}))
+	require.NoError(t, msigDelegated.AppendAttachMultisigSignature(acct2.Address.String(), otherSignature))
+	require.Equal(t, 1, msigDelegated.MultisigSignatureCount())
+	require.NoError(t, msigDelegated.AppendSignMultisigSignature(acct2.PrivateKey))
+	require.Equal(t, 2, msigDelegated.MultisigSignatureCount())
+
+	// current delegations use LMsig, which signs the multisig address along with the program
+	lmsigAccount, err := crypto.MakeLogicSigAccountDelegatedMsig(program, nil, ma.value, acct1.PrivateKey)
+	require.NoError(t, err)
+	require.True(t, lmsigAccount.Lsig.Msig.Blank())
+	lmsigDelegated := &LogicSigAccount{lmsigAccount}
+	require.Equal(t, LogicSigTypeDelegatedMsig, lmsigDelegated.SignatureType())
+	require.Equal(t, 1, lmsigDelegated.MultisigSignatureCount())
+	require.NoError(t, lmsigAccount.AppendMultisigSignature(acct2.PrivateKey))
+	lmsigDelegated, err = DeserializeLogicSigAccountFromJSON((&LogicSigAccount{lmsigAccount}).ToJSON())
+	require.NoError(t, err)
+	require.Equal(t, 2, lmsigDelegated.MultisigThreshold())
+	require.Equal(t, 2, lmsigDelegated.MultisigSignatureCount())
+
+	// a legacy signature of the program is not valid for LMsig
+	lmsigAccount.Lsig.LMsig.Subsigs[1].Sig = types.Signature{}
+	copy(lmsigAccount.Lsig.LMsig.Subsigs[1].Sig[:], ed25519.Sign(acct2.PrivateKey, LogicSigProgramForSigning(program)))
+	require.Equal(t, 1, (&LogicSigAccount{lmsigAccount}).MultisigSignatureCount())
+}
+
+func TestLogicSigAccountWithArgs(t *testing.T) {
+	t.Parallel()
+	program := []byte{0x1, 0x20, 0x1, 0x1, 0x22}
+	ma, acct1, acct2, _ := makeTestMultisigAccount(t)
+
+	original, err := MakeLogicSigAccountDelegatedMsig(program, &BytesArray{[][]byte{{0x01}}}, ma)
+	require.NoError(t, err)
+	require.NoError(t, original.AppendSignMultisigSignature(acct1.PrivateKey))
+
+	updated, err := original.WithArgs(&BytesArray{[][]byte{{0x02}, {0x03}}})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x02}, {0x03}}, updated.Args().Extract())
+	require.Equal(t, [][]byte{{0x01}}, original.Args().Extract())
+	require.False(t, original.Equals(updated))
+	require.Equal(t, original.value.Lsig.Msig, updated.value.Lsig.Msig)
+
+	// the copy does not share signatures with the original
+	require.NoError(t, updated.AppendSignMultisigSignature(acct2.PrivateKey))
+	require.Equal(t, 1, original.MultisigSignatureCount())
+	require.Equal(t, 2, updated.MultisigSignatureCount())
+
+	restored, err := updated.WithArgs(&BytesArray{[][]byte{{0x01}}})
+	require.NoError(t, err)
+	require.False(t, original.Equals(restored))
+	require.NoError(t, original.AppendSignMultisigSignature(acct2.PrivateKey))
+	require.True(t, original.Equals(restored))
+	require.False(t, original.Equals(nil))
+
+	noArgs, err := original.WithArgs(nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, noArgs.Args().Length())
+
+	fromJSON, err := DeserializeLogicSigAccountFromJSON(original.ToJSON())
+	require.NoError(t, err)
+	require.True(t, original.Equals(fromJSON))
+}
+
+func TestMakeLogicSigDelegationRevocationTxn(t *testing.T) {
+	t.Parallel()
+	program := []byte{0x1, 0x20, 0x1, 0x1, 0x22}
+	_, acct1, acct2, acct3 := makeTestMultisigAccount(t)
+	params := &SuggestedParams{
+		Fee:             1000,
+		FlatFee:         true,
+		FirstRoundValid: 2,
+		LastRoundValid:  1002,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     mustDecodeB64(t, "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI="),
+	}
+
+	delegated, err := MakeLogicSigAccountDelegatedSign(program, nil, acct1.PrivateKey)
+	require.NoError(t, err)
+
+	encoded, err := MakeLogicSigDelegationRevocationTxn(delegated, "", acct2.Address.String(), params)
+	require.NoError(t, err)
+	expected, err := MakeRekeyTxn(acct1.Address.String(), acct2.Address.String(), params, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	var tx types.Transaction
+	require.NoError(t, msgpack.Decode(encoded, &tx))
+	require.Equal(t, acct1.Address, tx.Sender)
+	require.Equal(t, acct2.Address, tx.RekeyTo)
+
+	// the delegating key may be the auth address of another account
+	encoded, err = MakeLogicSigDelegationRevocationTxn(delegated, acct3.Address.String(), acct3.Address.String(), params)
+	require.NoError(t, err)
+	require.NoError(t, msgpack.Decode(encoded, &tx))
+	require.Equal(t, acct3.Address, tx.Sender)
+	require.Equal(t, acct3.Address, tx.RekeyTo)
+
+	_, err = MakeLogicSigDelegationRevocationTxn(delegated, "", acct1.Address.String(), params)
+	require.ErrorContains(t, err, "does not revoke the delegation")
+	_, err = MakeLogicSigDelegationRevocationTxn(delegated, "", "invalid", params)
+	require.Error(t, err)
+
+	escrow, err := MakeLogicSigAccountEscrow(program, nil)
+	require.NoError(t, err)
+	_, err = MakeLogicSigDelegationRevocationTxn(escrow, "", acct2.Address.String(), params)
+	require.ErrorContains(t, err, "not delegated")
+}
@@ -0,0 +1,390 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Severities of the findings of a LogicSig risk report, from the most to the least severe
+const (
+	RiskSeverityCritical = "critical"
+	RiskSeverityHigh     = "high"
+	RiskSeverityMedium   = "medium"
+	RiskSeverityLow      = "low"
+	RiskSeverityInfo     = "info"
+	// RiskSeverityNone is the risk level of a report without findings
+	RiskSeverityNone = "none"
+)
+
+var riskSeverityRanks = map[string]int{
+	RiskSeverityNone:     0,
+	RiskSeverityInfo:     1,
+	RiskSeverityLow:      2,
+	RiskSeverityMedium:   3,
+	RiskSeverityHigh:     4,
+	RiskSeverityCritical: 5,
+}
+
+// Codes of the findings of a LogicSig risk report
+const (
+	RiskMissingRekeyToCheck          = "missing-rekey-to-check"
+	RiskMissingCloseRemainderToCheck = "missing-close-remainder-to-check"
+	RiskMissingAssetCloseToCheck     = "missing-asset-close-to-check"
+	RiskMissingFeeCheck              = "missing-fee-check"
+	RiskMissingLastValidCheck        = "missing-last-valid-check"
+	RiskUnconditionalApproval        = "unconditional-approval"
+	RiskIncompleteAnalysis           = "incomplete-analysis"
+)
+
+// guardedField is a transaction field which a delegated LogicSig must check
+type guardedField struct {
+	name     string
+	code     string
+	severity string
+	message  string
+	// minVersion is the first program version which can approve transactions setting the field
+	minVersion uint64
+}
+
+var guardedFields = []guardedField{
+	{"RekeyTo", RiskMissingRekeyToCheck, RiskSeverityCritical,
+		"the program can approve a transaction which rekeys the account to anyone", 2},
+	{"CloseRemainderTo", RiskMissingCloseRemainderToCheck, RiskSeverityCritical,
+		"the program can approve a payment which closes the account and sends all its Algos to anyone", 1},
+	{"AssetCloseTo", RiskMissingAssetCloseToCheck, RiskSeverityHigh,
+		"the program can approve an asset transfer which closes out an asset holding to anyone", 1},
+	{"Fee", RiskMissingFeeCheck, RiskSeverityHigh,
+		"the program can approve a transaction with an arbitrarily high fee", 1},
+	{"LastValid", RiskMissingLastValidCheck, RiskSeverityMedium,
+		"the program never expires, so the delegation can be used forever", 1},
+}
+
+const (
+	// maxRiskAnalysisStates bounds the number of program states explored by the analysis
+	maxRiskAnalysisStates = 100000
+	// maxRiskAnalysisCallDepth bounds the depth of nested subroutine calls followed by the analysis
+	maxRiskAnalysisCallDepth = 16
+	// maxReportedRiskPaths bounds the number of code paths reported for a single finding
+	maxReportedRiskPaths = 5
+)
+
+// Known values of the top of the stack
+const (
+	stackTopUnknown = iota
+	stackTopZero
+	stackTopNonZero
+)
+
+// riskPathState is the state of the analysis at an instruction of an execution path
+type riskPathState struct {
+	index int
+	calls []int
+	// checked has a bit set for every guarded field read on the path
+	checked     uint
+	conditional bool
+	top         int
+	labels      []string
+}
+
+func (s *riskPathState) key() string {
+	calls := make([]string, len(s.calls))
+	for i, call := range s.calls {
+		calls[i] = strconv.Itoa(call)
+	}
+	return fmt.Sprintf("%d/%s/%d/%t/%d", s.index, strings.Join(calls, ","), s.checked, s.conditional, s.top)
+}
+
+func (s *riskPathState) next(index int) *riskPathState {
+	return &riskPathState{
+		index:       index,
+		calls:       s.calls,
+		checked:     s.checked,
+		conditional: s.conditional,
+		top:         stackTopUnknown,
+		labels:      s.labels,
+	}
+}
+
+// logicSigRiskPath is a code path of a LogicSig program which approves transactions
+type logicSigRiskPath struct {
+	// Labels are the labels entered along the path, starting with "start"
+	Labels []string `json:"labels"`
+	// End is the offset of the instruction ending the path, or the program length if the path runs
+	// off the end of the program
+	End int `json:"end"`
+	// EndText is the source text of the instruction ending the path
+	EndText string `json:"endText,omitempty"`
+}
+
+// logicSigRiskFinding is a single issue found in a LogicSig program
+type logicSigRiskFinding struct {
+	Code     string             `json:"code"`
+	Severity string             `json:"severity"`
+	Message  string             `json:"message"`
+	Paths    []logicSigRiskPath `json:"paths,omitempty"`
+}
+
+type logicSigRiskReport struct {
+	Version   uint64                `json:"version"`
+	RiskLevel string                `json:"riskLevel"`
+	Complete  bool                  `json:"complete"`
+	Findings  []logicSigRiskFinding `json:"findings"`
+}
+
+type approvingPath struct {
+	checked     uint
+	conditional bool
+	path        logicSigRiskPath
+}
+
+// approvingPaths explores the execution paths of program, and returns the paths which may approve a
+// transaction. It also returns false if the exploration was cut short.
+func approvingPaths(program *tealProgram) ([]approvingPath, bool) {
+	fieldBits := make(map[string]uint, len(guardedFields))
+	for i, field := range guardedFields {
+		fieldBits[field.name] = 1 << uint(i)
+	}
+
+	var approving []approvingPath
+	complete := true
+	visited := make(map[string]bool)
+	pending := []*riskPathState{{labels: []string{"start"}}}
+	approve := func(state *riskPathState, conditional bool, end int, endText string) {
+		approving = append(approving, approvingPath{
+			checked:     state.checked,
+			conditional: conditional,
+			path:        logicSigRiskPath{Labels: state.labels, End: end, EndText: endText},
+		})
+	}
+
+	for len(pending) > 0 {
+		state := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if state.index == len(program.instructions) {
+			// running off the end of the program approves if the top of the stack is not zero
+			if state.top != stackTopZero {
+				approve(state, state.conditional || state.top == stackTopUnknown, program.length, "")
+			}
+			continue
+		}
+
+		key := state.key()
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		if len(visited) > maxRiskAnalysisStates {
+			complete = false
+			break
+		}
+
+		inst := &program.instructions[state.index]
+		if label, ok := program.labels[inst.offset]; ok && state.index != 0 {
+			state.labels = append(state.labels[:len(state.labels):len(state.labels)], label)
+		}
+		jump := func(from *riskPathState, target int, conditional bool) {
+			index, _ := program.instructionAt(target)
+			next := from.next(index)
+			next.conditional = next.conditional || conditional
+			if index == len(program.instructions) {
+				// the label of the end of the program is not entered through an instruction
+				next.labels = append(next.labels[:len(next.labels):len(next.labels)], program.labels[target])
+			}
+			pending = append(pending, next)
+		}
+
+		switch inst.name() {
+		case "err":
+			// rejects
+		case "return":
+			if state.top != stackTopZero {
+				approve(state, state.conditional || state.top == stackTopUnknown, inst.offset, program.instructionText(inst))
+			}
+		case "assert":
+			switch state.top {
+			case stackTopZero:
+			case stackTopNonZero:
+				pending = append(pending, state.next(state.index+1))
+			default:
+				next := state.next(state.index + 1)
+				next.conditional = true
+				pending = append(pending, next)
+			}
+		case "b":
+			jump(state, inst.targets[0], false)
+		case "bz", "bnz":
+			taken := stackTopZero
+			if inst.name() == "bnz" {
+				taken = stackTopNonZero
+			}
+			switch state.top {
+			case taken:
+				jump(state, inst.targets[0], false)
+			case stackTopUnknown:
+				next := state.next(state.index + 1)
+				next.conditional = true
+				pending = append(pending, next)
+				jump(state, inst.targets[0], true)
+			default:
+				pending = append(pending, state.next(state.index+1))
+			}
+		case "switch", "match":
+			next := state.next(state.index + 1)
+			next.conditional = true
+			pending = append(pending, next)
+			for _, target := range inst.targets {
+				jump(state, target, true)
+			}
+		case "callsub":
+			if len(state.calls) >= maxRiskAnalysisCallDepth {
+				complete = false
+				continue
+			}
+			calls := append(state.calls[:len(state.calls):len(state.calls)], state.index+1)
+			index, _ := program.instructionAt(inst.targets[0])
+			next := state.next(index)
+			next.calls = calls
+			pending = append(pending, next)
+		case "retsub":
+			if len(state.calls) == 0 {
+				// fails at runtime
+				continue
+			}
+			next := state.next(state.calls[len(state.calls)-1])
+			next.calls = state.calls[:len(state.calls)-1]
+			pending = append(pending, next)
+		default:
+			next := state.next(state.index + 1)
+			switch inst.name() {
+			case "txn", "txna", "txnas":
+				next.checked |= fieldBits[inst.field()]
+			}
+			if inst.value != nil && !inst.value.isBytes {
+				if inst.value.uint == 0 {
+					next.top = stackTopZero
+				} else {
+					next.top = stackTopNonZero
+				}
+			} else if inst.name() == "pushints" && len(inst.constants) > 0 {
+				if inst.constants[len(inst.constants)-1].uint == 0 {
+					next.top = stackTopZero
+				} else {
+					next.top = stackTopNonZero
+				}
+			}
+			pending = append(pending, next)
+		}
+	}
+	return approving, complete
+}
+
+// analyzeDelegatedLogicSig returns the risk report of a delegated LogicSig program.
+func analyzeDelegatedLogicSig(program []byte) (*logicSigRiskReport, error) {
+	// accept the bytes returned by LogicSigProgramForSigning as well
+	program = bytes.TrimPrefix(program, []byte("Program"))
+	decoded, err := NewTEALDisassembler().decode(program)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, complete := approvingPaths(decoded)
+	report := &logicSigRiskReport{
+		Version:   decoded.version,
+		RiskLevel: RiskSeverityNone,
+		Complete:  complete,
+		Findings:  []logicSigRiskFinding{},
+	}
+	addFinding := func(code, severity, message string, offending func(p *approvingPath) bool) {
+		finding := logicSigRiskFinding{Code: code, Severity: severity, Message: message}
+		found := offending == nil
+		for i := range paths {
+			if offending == nil || !offending(&paths[i]) {
+				continue
+			}
+			found = true
+			if len(finding.Paths) < maxReportedRiskPaths {
+				finding.Paths = append(finding.Paths, paths[i].path)
+			}
+		}
+		if !found {
+			return
+		}
+		report.Findings = append(report.Findings, finding)
+		if riskSeverityRanks[severity] > riskSeverityRanks[report.RiskLevel] {
+			report.RiskLevel = severity
+		}
+	}
+
+	addFinding(RiskUnconditionalApproval, RiskSeverityCritical,
+		"the program approves any transaction", func(p *approvingPath) bool {
+			return !p.conditional
+		})
+	for i, field := range guardedFields {
+		if decoded.version < field.minVersion {
+			continue
+		}
+		bit := uint(1) << uint(i)
+		addFinding(field.code, field.severity, field.message, func(p *approvingPath) bool {
+			return p.checked&bit == 0
+		})
+	}
+	if !complete {
+		addFinding(RiskIncompleteAnalysis, RiskSeverityInfo,
+			"the program is too complex to analyze completely, so some issues may be missing", nil)
+	}
+	return report, nil
+}
+
+// AnalyzeDelegatedLogicSig analyzes a LogicSig program before it is delegated, and returns a risk
+// report encoded as JSON. The program may also be given as returned by LogicSigProgramForSigning.
+//
+// The report flags programs which can approve a transaction without reading its RekeyTo,
+// CloseRemainderTo, AssetCloseTo, Fee or LastValid fields, and programs which approve any
+// transaction. The report has the form:
+//
+//	{
+//	  "version": 8,
+//	  "riskLevel": "critical",
+//	  "complete": true,
+//	  "findings": [
+//	    {
+//	      "code": "missing-rekey-to-check",
+//	      "severity": "critical",
+//	      "message": "...",
+//	      "paths": [{"labels": ["start", "label1"], "end": 42, "endText": "return"}]
+//	    }
+//	  ]
+//	}
+//
+// Each finding lists the code paths approving transactions without the check, by the labels of the
+// program disassembly (see DisassembleTEAL) that they go through. The analysis is a heuristic: a
+// field only counts as checked if it is read with txn, txna or txnas on the path, whatever the
+// program does with it. A report without findings does not prove that a program is safe.
+func AnalyzeDelegatedLogicSig(program []byte) (string, error) {
+	report, err := analyzeDelegatedLogicSig(program)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(report)
+	return string(encoded), err
+}
+
+// GetDelegatedLogicSigRiskLevel returns the severity of the most severe finding of the risk report
+// of a LogicSig program, or RiskSeverityNone. See AnalyzeDelegatedLogicSig.
+func GetDelegatedLogicSigRiskLevel(program []byte) (string, error) {
+	report, err := analyzeDelegatedLogicSig(program)
+	if err != nil {
+		return "", err
+	}
+	return report.RiskLevel, nil
+}
+
+// AnalyzeDelegationRisks returns the risk report of delegating the LogicSig program. See
+// AnalyzeDelegatedLogicSig.
+func (lsa *LogicSigAccount) AnalyzeDelegationRisks() (string, error) {
+	return AnalyzeDelegatedLogicSig(lsa.value.Lsig.Logic)
+}
@@ -0,0 +1,123 @@
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRiskReport struct {
+	Version   int                   `json:"version"`
+	RiskLevel string                `json:"riskLevel"`
+	Complete  bool                  `json:"complete"`
+	Findings  []logicSigRiskFinding `json:"findings"`
+}
+
+func analyzeTestLogicSig(t *testing.T, program []byte) testRiskReport {
+	encoded, err := AnalyzeDelegatedLogicSig(program)
+	require.NoError(t, err)
+	var report testRiskReport
+	require.NoError(t, json.Unmarshal([]byte(encoded), &report))
+	require.True(t, report.Complete)
+	return report
+}
+
+func riskFindingCodes(report testRiskReport) []string {
+	var codes []string
+	for _, finding := range report.Findings {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+func TestAnalyzeDelegatedLogicSigApproveAll(t *testing.T) {
+	t.Parallel()
+	// #pragma version 1
+	// int 1
+	program := []byte{0x01, 0x20, 0x01, 0x01, 0x22}
+	report := analyzeTestLogicSig(t, program)
+	require.Equal(t, 1, report.Version)
+	require.Equal(t, RiskSeverityCritical, report.RiskLevel)
+	// version 1 programs cannot approve rekeying transactions
+	require.Equal(t, []string{
+		RiskUnconditionalApproval,
+		RiskMissingCloseRemainderToCheck,
+		RiskMissingAssetCloseToCheck,
+		RiskMissingFeeCheck,
+		RiskMissingLastValidCheck,
+	}, riskFindingCodes(report))
+	require.Equal(t, []logicSigRiskPath{{Labels: []string{"start"}, End: 5}}, report.Findings[0].Paths)
+
+	// the bytes signed for a delegation are accepted as well
+	level, err := GetDelegatedLogicSigRiskLevel(LogicSigProgramForSigning(program))
+	require.NoError(t, err)
+	require.Equal(t, RiskSeverityCritical, level)
+
+	lsigAccount, err := MakeLogicSigAccountEscrow(program, nil)
+	require.NoError(t, err)
+	encoded, err := lsigAccount.AnalyzeDelegationRisks()
+	require.NoError(t, err)
+	expected, err := AnalyzeDelegatedLogicSig(program)
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+}
+
+func TestAnalyzeDelegatedLogicSigSafeProgram(t *testing.T) {
+	t.Parallel()
+	program := []byte{
+		0x06,                   // #pragma version 6
+		0x31, 0x20, 0x32, 0x03, // txn RekeyTo; global ZeroAddress
+		0x12, 0x44, // ==; assert
+		0x31, 0x09, 0x32, 0x03, // txn CloseRemainderTo; global ZeroAddress
+		0x12, 0x44, // ==; assert
+		0x31, 0x15, 0x32, 0x03, // txn AssetCloseTo; global ZeroAddress
+		0x12, 0x44, // ==; assert
+		0x31, 0x01, 0x81, 0xe8, 0x07, // txn Fee; pushint 1000
+		0x0e, 0x44, // <=; assert
+		0x31, 0x04, 0x81, 0x88, 0x27, // txn LastValid; pushint 5000
+		0x0c, 0x44, // <; assert
+		0x81, 0x01, // pushint 1
+	}
+	report := analyzeTestLogicSig(t, program)
+	require.Equal(t, RiskSeverityNone, report.RiskLevel)
+	require.Empty(t, report.Findings)
+
+	// programs which never approve have no findings either
+	report = analyzeTestLogicSig(t, []byte{0x03, 0x81, 0x00, 0x43}) // pushint 0; return
+	require.Equal(t, RiskSeverityNone, report.RiskLevel)
+	report = analyzeTestLogicSig(t, []byte{0x02, 0x42, 0xff, 0xfd}) // label1: b label1
+	require.Equal(t, RiskSeverityNone, report.RiskLevel)
+}
+
+func TestAnalyzeDelegatedLogicSigPaths(t *testing.T) {
+	t.Parallel()
+	program := []byte{
+		0x06,       // #pragma version 6
+		0x31, 0x10, // txn TypeEnum
+		0x81, 0x01, // pushint 1
+		0x12,             // ==
+		0x40, 0x00, 0x07, // bnz label1
+		0x31, 0x01, 0x81, 0xe8, 0x07, // txn Fee; pushint 1000
+		0x0e, 0x43, // <=; return
+		0x81, 0x01, // label1: pushint 1
+	}
+	report := analyzeTestLogicSig(t, program)
+	require.Equal(t, RiskSeverityCritical, report.RiskLevel)
+	require.Equal(t, []string{
+		RiskMissingRekeyToCheck,
+		RiskMissingCloseRemainderToCheck,
+		RiskMissingAssetCloseToCheck,
+		RiskMissingFeeCheck,
+		RiskMissingLastValidCheck,
+	}, riskFindingCodes(report))
+
+	feePath := logicSigRiskPath{Labels: []string{"start", "label1"}, End: 18}
+	returnPath := logicSigRiskPath{Labels: []string{"start"}, End: 15, EndText: "return"}
+	require.Equal(t, []logicSigRiskPath{feePath}, report.Findings[3].Paths)
+	require.ElementsMatch(t, []logicSigRiskPath{feePath, returnPath}, report.Findings[0].Paths)
+	require.Equal(t, RiskSeverityMedium, report.Findings[4].Severity)
+
+	_, err := AnalyzeDelegatedLogicSig([]byte{0x06, 0xff})
+	require.Error(t, err)
+}
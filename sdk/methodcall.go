@@ -0,0 +1,291 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// maxAppArgs is the maximum number of application arguments in a transaction. ABI method calls with
+// more arguments pack the remaining ones into a tuple in the last application argument.
+const maxAppArgs = 16
+
+type methodWithStructs struct {
+	method  abi.Method
+	structs arc56MethodStructs
+}
+
+// parseMethods accepts either a single method description or an app spec, ARC-4 or ARC-56, with a
+// "methods" array. Struct definitions from an ARC-56 app spec are returned as well.
+func parseMethods(methodsJSON string) ([]methodWithStructs, *ABIStructDefinitions, error) {
+	var spec struct {
+		Methods []json.RawMessage             `json:"methods"`
+		Structs map[string][]arc56StructField `json:"structs"`
+	}
+	err := json.Unmarshal([]byte(methodsJSON), &spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not decode methods from JSON: %w", err)
+	}
+	rawMethods := spec.Methods
+	if rawMethods == nil {
+		rawMethods = []json.RawMessage{json.RawMessage(methodsJSON)}
+	}
+
+	methods := make([]methodWithStructs, len(rawMethods))
+	for i, rawMethod := range rawMethods {
+		err = json.Unmarshal(rawMethod, &methods[i].method)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not decode method from JSON: %w", err)
+		}
+		if methods[i].method.Name == "" {
+			return nil, nil, errors.New("method has no name")
+		}
+		methods[i].structs, err = parseMethodStructs(string(rawMethod))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not decode method from JSON: %w", err)
+		}
+	}
+
+	var structs *ABIStructDefinitions
+	if spec.Structs != nil {
+		structs, err = makeABIStructDefinitions(spec.Structs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return methods, structs, nil
+}
+
+type decodedMethodArg struct {
+	Name       string          `json:"name,omitempty"`
+	Type       string          `json:"type"`
+	Value      json.RawMessage `json:"value"`
+	GroupIndex *int            `json:"groupIndex,omitempty"`
+}
+
+type decodedMethodCall struct {
+	Method    string             `json:"method"`
+	Signature string             `json:"signature"`
+	Args      []decodedMethodArg `json:"args"`
+	Display   string             `json:"display"`
+}
+
+// resolveReferenceArg resolves the uint8 index of a reference type argument through the foreign
+// arrays of the application call.
+func resolveReferenceArg(txn types.Transaction, argType string, index uint8) (json.RawMessage, error) {
+	switch argType {
+	case abi.AccountReferenceType:
+		if index == 0 {
+			return json.Marshal(txn.Sender.String())
+		}
+		if int(index) > len(txn.Accounts) {
+			return nil, fmt.Errorf("account reference %d out of range", index)
+		}
+		return json.Marshal(txn.Accounts[index-1].String())
+	case abi.AssetReferenceType:
+		if int(index) >= len(txn.ForeignAssets) {
+			return nil, fmt.Errorf("asset reference %d out of range", index)
+		}
+		return json.Marshal(uint64(txn.ForeignAssets[index]))
+	case abi.ApplicationReferenceType:
+		if index == 0 {
+			return json.Marshal(uint64(txn.ApplicationID))
+		}
+		if int(index) > len(txn.ForeignApps) {
+			return nil, fmt.Errorf("application reference %d out of range", index)
+		}
+		return json.Marshal(uint64(txn.ForeignApps[index-1]))
+	default:
+		return nil, fmt.Errorf("unsupported reference type: %s", argType)
+	}
+}
+
+func displayMethodArg(arg decodedMethodArg) string {
+	switch {
+	case arg.GroupIndex != nil:
+		return fmt.Sprintf("%s #%d", arg.Type, *arg.GroupIndex)
+	case abi.IsReferenceType(arg.Type):
+		return arg.Type + " " + strings.Trim(string(arg.Value), `"`)
+	default:
+		return string(arg.Value)
+	}
+}
+
+func decodeMethodCall(txGroup []types.Transaction, index int, m methodWithStructs, structs *ABIStructDefinitions) (decodedMethodCall, error) {
+	txn := txGroup[index]
+	method := m.method
+
+	numTxnArgs := method.GetTxCount() - 1
+	if index < numTxnArgs {
+		return decodedMethodCall{}, fmt.Errorf("method requires %d transaction arguments, but only %d transactions precede the call", numTxnArgs, index)
+	}
+
+	// Collect the types of the arguments which are encoded in the application arguments
+	var encodedArgTypes []abi.Type
+	for _, arg := range method.Args {
+		if arg.IsTransactionArg() {
+			continue
+		}
+		var argType abi.Type
+		var err error
+		if arg.IsReferenceArg() {
+			argType, err = abi.TypeOf("uint8")
+		} else {
+			argType, err = arg.GetTypeObject()
+		}
+		if err != nil {
+			return decodedMethodCall{}, err
+		}
+		encodedArgTypes = append(encodedArgTypes, argType)
+	}
+
+	appArgs := txn.ApplicationArgs[1:]
+	var encodedValues []interface{}
+	if len(encodedArgTypes) > maxAppArgs-1 {
+		// The trailing arguments are packed into a tuple in the last application argument
+		if len(appArgs) != maxAppArgs-1 {
+			return decodedMethodCall{}, fmt.Errorf("expected %d application arguments, got %d", maxAppArgs, len(txn.ApplicationArgs))
+		}
+		for i := 0; i < maxAppArgs-2; i++ {
+			value, err := encodedArgTypes[i].Decode(appArgs[i])
+			if err != nil {
+				return decodedMethodCall{}, fmt.Errorf("could not decode argument %d: %w", i, err)
+			}
+			encodedValues = append(encodedValues, value)
+		}
+		tupleType, err := abi.MakeTupleType(encodedArgTypes[maxAppArgs-2:])
+		if err != nil {
+			return decodedMethodCall{}, err
+		}
+		tupleValue, err := tupleType.Decode(appArgs[maxAppArgs-2])
+		if err != nil {
+			return decodedMethodCall{}, fmt.Errorf("could not decode packed arguments: %w", err)
+		}
+		encodedValues = append(encodedValues, tupleValue.([]interface{})...)
+	} else {
+		if len(appArgs) != len(encodedArgTypes) {
+			return decodedMethodCall{}, fmt.Errorf("expected %d application arguments, got %d", len(encodedArgTypes)+1, len(txn.ApplicationArgs))
+		}
+		for i, argType := range encodedArgTypes {
+			value, err := argType.Decode(appArgs[i])
+			if err != nil {
+				return decodedMethodCall{}, fmt.Errorf("could not decode argument %d: %w", i, err)
+			}
+			encodedValues = append(encodedValues, value)
+		}
+	}
+
+	result := decodedMethodCall{
+		Method:    method.Name,
+		Signature: method.GetSignature(),
+		Args:      make([]decodedMethodArg, len(method.Args)),
+	}
+	txnArgIndex := 0
+	encodedIndex := 0
+	for i, arg := range method.Args {
+		decodedArg := decodedMethodArg{Name: arg.Name, Type: arg.Type}
+		switch {
+		case arg.IsTransactionArg():
+			groupIndex := index - numTxnArgs + txnArgIndex
+			txnArgIndex++
+			argTxn := txGroup[groupIndex]
+			if arg.Type != abi.AnyTransactionType && string(argTxn.Type) != arg.Type {
+				return decodedMethodCall{}, fmt.Errorf("argument %d requires a %s transaction, but transaction %d is %s", i, arg.Type, groupIndex, argTxn.Type)
+			}
+			decodedArg.GroupIndex = &groupIndex
+			value, err := json.Marshal(crypto.TransactionIDString(argTxn))
+			if err != nil {
+				return decodedMethodCall{}, err
+			}
+			decodedArg.Value = value
+		case arg.IsReferenceArg():
+			value, err := resolveReferenceArg(txn, arg.Type, encodedValues[encodedIndex].(uint8))
+			encodedIndex++
+			if err != nil {
+				return decodedMethodCall{}, fmt.Errorf("could not resolve argument %d: %w", i, err)
+			}
+			decodedArg.Value = value
+		default:
+			argType := encodedArgTypes[encodedIndex]
+			value, err := argType.MarshalToJSON(encodedValues[encodedIndex])
+			encodedIndex++
+			if err != nil {
+				return decodedMethodCall{}, err
+			}
+			if structs != nil && i < len(m.structs.Args) && m.structs.Args[i].Struct != "" {
+				named, err := structs.TupleToStructJSON(m.structs.Args[i].Struct, string(value))
+				if err != nil {
+					return decodedMethodCall{}, err
+				}
+				value = json.RawMessage(named)
+			}
+			decodedArg.Value = value
+		}
+		result.Args[i] = decodedArg
+	}
+
+	displayArgs := make([]string, len(result.Args))
+	for i, arg := range result.Args {
+		displayArgs[i] = displayMethodArg(arg)
+	}
+	result.Display = method.Name + "(" + strings.Join(displayArgs, ", ") + ")"
+	return result, nil
+}
+
+// DecodeABIMethodCall decodes an application call transaction back into the ABI method it calls and
+// the arguments it passes.
+//
+// txns is the transaction group containing the application call, and index is the position of the
+// application call in the group. methodsJSON is either the JSON description of a single method or an
+// app spec (ARC-4 contract or ARC-56) with a "methods" array. The method is identified by matching
+// its selector against the first application argument.
+//
+// The result is a JSON object:
+//
+//	{"method":"swap","signature":"swap(asset,uint64)void","display":"swap(asset 31566704, 1000000)",
+//	 "args":[{"name":"a","type":"asset","value":31566704},{"type":"uint64","value":1000000}]}
+//
+// Argument values use the same format as `ABIType.Decode()`. Reference type arguments are resolved
+// through the foreign arrays of the transaction: accounts become addresses, and assets and
+// applications become IDs. Transaction type arguments have the ID of the group transaction which
+// satisfies them as their value, and its position in the group as "groupIndex". If methodsJSON is an
+// ARC-56 app spec, struct arguments are returned as JSON objects with named fields.
+func DecodeABIMethodCall(txns *BytesArray, index int, methodsJSON string) (string, error) {
+	txGroup, err := decodeTxns(txns)
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || index >= len(txGroup) {
+		return "", fmt.Errorf("transaction index %d out of range", index)
+	}
+	txn := txGroup[index]
+	if txn.Type != types.ApplicationCallTx {
+		return "", fmt.Errorf("transaction %d is not an application call", index)
+	}
+	if len(txn.ApplicationArgs) == 0 {
+		return "", errors.New("application call has no arguments")
+	}
+
+	methods, structs, err := parseMethods(methodsJSON)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range methods {
+		if !bytes.Equal(m.method.GetSelector(), txn.ApplicationArgs[0]) {
+			continue
+		}
+		decoded, err := decodeMethodCall(txGroup, index, m, structs)
+		if err != nil {
+			return "", fmt.Errorf("could not decode call to '%s': %w", m.method.GetSignature(), err)
+		}
+		encoded, err := json.Marshal(decoded)
+		return string(encoded), err
+	}
+	return "", fmt.Errorf("no method matches selector %x", txn.ApplicationArgs[0])
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTestMethodCallGroup(t *testing.T, methodJSON string, foreignAssets []int64, args []string, payment bool) *BytesArray {
+	t.Helper()
+	sender := "DN7MBMCL5JQ3PFUQS7TMX5AH4EEKOBJVDUF4TCV6WERATKFLQF4MQUPZTA"
+	params := &SuggestedParams{Fee: 1000, FlatFee: true, FirstRoundValid: 1, LastRoundValid: 1001, GenesisID: "testnet-v1.0", GenesisHash: mustDecodeB64(t, "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI=")}
+
+	callParams, err := NewAddMethodCallParams(
+		1234,
+		0,
+		methodJSON,
+		&StringArray{},
+		&Int64Array{},
+		&Int64Array{foreignAssets},
+		&AppBoxRefArray{},
+		params,
+		nil,
+		sender,
+		nil,
+	)
+	require.NoError(t, err)
+	if payment {
+		amount := MakeUint64(100000)
+		payTxn, err := MakePaymentTxn(sender, "WWYNX3TKQYVEREVSW6QQP3SXSFOCE3SKUSEIVJ7YAGUPEACNI5UGI4DZCE", &amount, nil, "", params, nil)
+		require.NoError(t, err)
+		require.NoError(t, callParams.AddMethodArgumentTransaction(payTxn, nil))
+	}
+	for _, arg := range args {
+		require.NoError(t, callParams.AddMethodArgument(arg))
+	}
+
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddMethodCall(callParams))
+	group, err := atc.BuildGroup()
+	require.NoError(t, err)
+	return group
+}
+
+func TestDecodeABIMethodCall(t *testing.T) {
+	t.Parallel()
+	methodJSON := `{"name":"swap","args":[{"type":"pay","name":"fee"},{"type":"asset","name":"asset"},{"type":"uint64","name":"amount"},{"type":"account"}],"returns":{"type":"void"}}`
+	group := buildTestMethodCallGroup(t, methodJSON, []int64{31566704}, []string{`31566704`, `1000000`, `"WWYNX3TKQYVEREVSW6QQP3SXSFOCE3SKUSEIVJ7YAGUPEACNI5UGI4DZCE"`}, true)
+	require.Equal(t, 2, group.Length())
+
+	var payTxn types.Transaction
+	require.NoError(t, msgpack.Decode(group.Get(0), &payTxn))
+
+	decoded, err := DecodeABIMethodCall(group, 1, methodJSON)
+	require.NoError(t, err)
+	require.JSONEq(t, fmt.Sprintf(`{
+		"method": "swap",
+		"signature": "swap(pay,asset,uint64,account)void",
+		"display": "swap(pay #0, asset 31566704, 1000000, account WWYNX3TKQYVEREVSW6QQP3SXSFOCE3SKUSEIVJ7YAGUPEACNI5UGI4DZCE)",
+		"args": [
+			{"name": "fee", "type": "pay", "value": "%s", "groupIndex": 0},
+			{"name": "asset", "type": "asset", "value": 31566704},
+			{"name": "amount", "type": "uint64", "value": 1000000},
+			{"type": "account", "value": "WWYNX3TKQYVEREVSW6QQP3SXSFOCE3SKUSEIVJ7YAGUPEACNI5UGI4DZCE"}
+		]
+	}`, crypto.TransactionIDString(payTxn)), decoded)
+
+	// the method can also be found in an app spec
+	appSpec := `{"name":"dex","methods":[{"name":"other","args":[],"returns":{"type":"void"}},` + methodJSON + `]}`
+	decodedFromSpec, err := DecodeABIMethodCall(group, 1, appSpec)
+	require.NoError(t, err)
+	require.Equal(t, decoded, decodedFromSpec)
+
+	_, err = DecodeABIMethodCall(group, 0, methodJSON)
+	require.Error(t, err)
+	_, err = DecodeABIMethodCall(group, 1, `{"name":"other","args":[],"returns":{"type":"void"}}`)
+	require.Error(t, err)
+}
+
+func TestDecodeABIMethodCallPackedArgs(t *testing.T) {
+	t.Parallel()
+	argTypes := make([]string, 17)
+	args := make([]string, 17)
+	expectedDisplay := make([]string, 17)
+	for i := range argTypes {
+		argTypes[i] = `{"type":"uint16"}`
+		args[i] = fmt.Sprint(i * 10)
+		expectedDisplay[i] = args[i]
+	}
+	methodJSON := `{"name":"many","args":[` + strings.Join(argTypes, ",") + `],"returns":{"type":"void"}}`
+	group := buildTestMethodCallGroup(t, methodJSON, nil, args, false)
+
+	var txn types.Transaction
+	require.NoError(t, msgpack.Decode(group.Get(0), &txn))
+	require.Len(t, txn.ApplicationArgs, 16)
+
+	decoded, err := DecodeABIMethodCall(group, 0, methodJSON)
+	require.NoError(t, err)
+
+	var result struct {
+		Display string `json:"display"`
+		Args    []struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"args"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(decoded), &result))
+	require.Len(t, result.Args, 17)
+	require.Equal(t, "160", string(result.Args[16].Value))
+	require.Equal(t, "many("+strings.Join(expectedDisplay, ", ")+")", result.Display)
+}
+
+func TestDecodeABIMethodCallStruct(t *testing.T) {
+	t.Parallel()
+	appSpec := `{
+		"name": "Exchange",
+		"structs": {"Limits": [{"name": "min", "type": "uint32"}, {"name": "max", "type": "uint32"}]},
+		"methods": [{"name": "setLimits", "args": [{"type": "(uint32,uint32)", "struct": "Limits", "name": "limits"}], "returns": {"type": "void"}}]
+	}`
+	methodJSON := `{"name": "setLimits", "args": [{"type": "(uint32,uint32)", "name": "limits"}], "returns": {"type": "void"}}`
+	group := buildTestMethodCallGroup(t, methodJSON, nil, []string{`[1,9]`}, false)
+
+	decoded, err := DecodeABIMethodCall(group, 0, appSpec)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"method": "setLimits",
+		"signature": "setLimits((uint32,uint32))void",
+		"display": "setLimits({\"min\":1,\"max\":9})",
+		"args": [{"name": "limits", "type": "(uint32,uint32)", "value": {"min": 1, "max": 9}}]
+	}`, decoded)
+}
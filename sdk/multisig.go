@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -16,6 +17,32 @@ type MultisigAccount struct {
 	value crypto.MultisigAccount
 }
 
+// multisigPreimage is the serializable definition of a multisig account, from which its address is
+// derived.
+type multisigPreimage struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Version   uint8           `codec:"v"`
+	Threshold uint8           `codec:"thr"`
+	Addresses []types.Address `codec:"addrs"`
+}
+
+func makeMultisigPreimage(account crypto.MultisigAccount) multisigPreimage {
+	preimage := multisigPreimage{
+		Version:   account.Version,
+		Threshold: account.Threshold,
+		Addresses: make([]types.Address, len(account.Pks)),
+	}
+	for i, pk := range account.Pks {
+		copy(preimage.Addresses[i][:], pk)
+	}
+	return preimage
+}
+
+func (p multisigPreimage) account() (crypto.MultisigAccount, error) {
+	return crypto.MultisigAccountWithParams(p.Version, p.Threshold, p.Addresses)
+}
+
 // MakeMultisigAccount creates a new instance of a MultiSig account. The order of the addresses matters.
 func MakeMultisigAccount(version int, threshold int, addrs *StringArray) (*MultisigAccount, error) {
 	addresses := make([]types.Address, addrs.Length())
@@ -194,3 +221,87 @@ func MergeMultisigTransactions(encodedSignedTx1, encodedSignedTx2 []byte) ([]byt
 	_, stxnBytes, err := crypto.MergeMultisigTransactions(encodedSignedTx1, encodedSignedTx2)
 	return stxnBytes, err
 }
+
+type multisigSubsigInspection struct {
+	Address string `json:"address"`
+	Signed  bool   `json:"signed"`
+	Valid   bool   `json:"valid"`
+}
+
+type multisigInspection struct {
+	Address         string                     `json:"address"`
+	Version         int                        `json:"version"`
+	Threshold       int                        `json:"threshold"`
+	Sender          string                     `json:"sender"`
+	AuthAddr        string                     `json:"authAddr,omitempty"`
+	AuthAddrValid   bool                       `json:"authAddrValid"`
+	ValidSignatures int                        `json:"validSignatures"`
+	ThresholdMet    bool                       `json:"thresholdMet"`
+	Subsigs         []multisigSubsigInspection `json:"subsigs"`
+}
+
+// InspectMultisigSignedTransaction reports the signature state of a partially or fully signed
+// multisig transaction, as a JSON object:
+//
+//	{"address":"...","version":1,"threshold":2,"sender":"...","authAddr":"...","authAddrValid":true,
+//	 "validSignatures":1,"thresholdMet":false,
+//	 "subsigs":[{"address":"...","signed":true,"valid":true},{"address":"...","signed":false,"valid":false}]}
+//
+// "valid" is true if the signature of a contributing address verifies over the transaction, and only
+// valid signatures count towards the threshold. "authAddrValid" is true if AuthAddr is set to the
+// multisig address when the sender is a different (rekeyed) account, and is unset otherwise.
+//
+// An error is returned if the transaction is not signed by a multisig account.
+func InspectMultisigSignedTransaction(encodedSignedTx []byte) (string, error) {
+	var stx types.SignedTxn
+	err := msgpack.Decode(encodedSignedTx, &stx)
+	if err != nil {
+		return "", err
+	}
+	if stx.Msig.Blank() {
+		return "", errors.New("transaction is not signed by a multisig account")
+	}
+	account, err := crypto.MultisigAccountFromSig(stx.Msig)
+	if err != nil {
+		return "", err
+	}
+	msigAddr, err := account.Address()
+	if err != nil {
+		return "", err
+	}
+
+	inspection := multisigInspection{
+		Address:   msigAddr.String(),
+		Version:   int(stx.Msig.Version),
+		Threshold: int(stx.Msig.Threshold),
+		Sender:    stx.Txn.Sender.String(),
+		Subsigs:   make([]multisigSubsigInspection, len(stx.Msig.Subsigs)),
+	}
+	if !stx.AuthAddr.IsZero() {
+		inspection.AuthAddr = stx.AuthAddr.String()
+	}
+	if stx.Txn.Sender == msigAddr {
+		inspection.AuthAddrValid = stx.AuthAddr.IsZero()
+	} else {
+		inspection.AuthAddrValid = stx.AuthAddr == msigAddr
+	}
+
+	for i, subsig := range stx.Msig.Subsigs {
+		var addr types.Address
+		copy(addr[:], subsig.Key)
+		signed := subsig.Sig != (types.Signature{})
+		valid := signed && verifyTransactionSignature(subsig.Key, stx.Txn, subsig.Sig)
+		if valid {
+			inspection.ValidSignatures++
+		}
+		inspection.Subsigs[i] = multisigSubsigInspection{
+			Address: addr.String(),
+			Signed:  signed,
+			Valid:   valid,
+		}
+	}
+	inspection.ThresholdMet = inspection.ValidSignatures >= inspection.Threshold
+
+	encoded, err := json.Marshal(inspection)
+	return string(encoded), err
+}
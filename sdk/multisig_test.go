@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"testing"
 
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
@@ -195,3 +196,63 @@ func TestMergeMultisigTransactions(t *testing.T) {
 		require.ErrorContains(t, err, "multisig parameters do not match")
 	})
 }
+
+func TestInspectMultisigSignedTransaction(t *testing.T) {
+	t.Parallel()
+	t.Run("threshold met", func(t *testing.T) {
+		encodedStx := mustDecodeB64(t, "g6Rtc2lng6ZzdWJzaWeTgqJwa8Qg1GH0gzzF09k0L/vGb0LahKCtBtcbE31tcJCJEfgHwOKhc8RAL+e7SP6OijDGQTe4wzHdI9kXM4erxh15OOphWBXmvrEv/DjBFpgqFldJVt5Oeva50CxtzFZSbuaFhPMfsB4TB4GicGvEIJe5enTwJjV1nrSTvRRtK/NOLekcWk7N6y5nai5B7m/DgqJwa8QgttQgDSXW4jm7c2Y9vEAqwPBXnKGn+pgy/YHcSMQuz/qhc8RAiIsrT+BmBMjqCo/Hkuq1/NnmHcUzZiXYRyOtHsPxXi6dJo8m16N00iU//Pd65QbZYpqc6DMl+OsgBpMm0WydCqN0aHICoXYBpHNnbnLEIFXsWKb87ulZ3fGOgPv0kIN2mCaitongANK5TvQ2u+7Eo3R4bomjYW10zgAPQkCjZmVlzQPoomZ2AqNnZW6sdGVzdG5ldC12MS4womdoxCBIY7UYpLPITsgQ8i1PEIHLD3HwWaesIN7GL39w5Qk6IqJsds0D6qNyY3bEIJe5enTwJjV1nrSTvRRtK/NOLekcWk7N6y5nai5B7m/Do3NuZMQg1GH0gzzF09k0L/vGb0LahKCtBtcbE31tcJCJEfgHwOKkdHlwZaNwYXk=")
+		inspection, err := InspectMultisigSignedTransaction(encodedStx)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"address": "KXWFRJX453UVTXPRR2APX5EQQN3JQJVCW2E6AAGSXFHPINV353CETLQCYA",
+			"version": 1,
+			"threshold": 2,
+			"sender": "2RQ7JAZ4YXJ5SNBP7PDG6QW2QSQK2BWXDMJX23LQSCERD6AHYDRH4N4MXY",
+			"authAddr": "KXWFRJX453UVTXPRR2APX5EQQN3JQJVCW2E6AAGSXFHPINV353CETLQCYA",
+			"authAddrValid": true,
+			"validSignatures": 2,
+			"thresholdMet": true,
+			"subsigs": [
+				{"address": "2RQ7JAZ4YXJ5SNBP7PDG6QW2QSQK2BWXDMJX23LQSCERD6AHYDRH4N4MXY", "signed": true, "valid": true},
+				{"address": "S64XU5HQEY2XLHVUSO6RI3JL6NHC32I4LJHM32ZOM5VC4QPON7BZZRCU2E", "signed": false, "valid": false},
+				{"address": "W3KCADJF23RDTO3TMY63YQBKYDYFPHFBU75JQMX5QHOERRBOZ75L3B2J7Y", "signed": true, "valid": true}
+			]
+		}`, inspection)
+	})
+
+	t.Run("invalid signature and auth address", func(t *testing.T) {
+		ma, acct1, _, _ := makeTestMultisigAccount(t)
+		txns := makeTestMultisigGroup(t, ma, 1)
+		encodedStx, err := SignMultisigTransaction(acct1.PrivateKey, ma, txns.Get(0))
+		require.NoError(t, err)
+
+		var stx types.SignedTxn
+		require.NoError(t, msgpack.Decode(encodedStx, &stx))
+		stx.Msig.Subsigs[0].Sig[0] ^= 1
+		stx.AuthAddr = acct1.Address
+
+		inspection, err := InspectMultisigSignedTransaction(msgpack.Encode(stx))
+		require.NoError(t, err)
+		var result struct {
+			AuthAddrValid   bool `json:"authAddrValid"`
+			ValidSignatures int  `json:"validSignatures"`
+			ThresholdMet    bool `json:"thresholdMet"`
+			Subsigs         []struct {
+				Signed bool `json:"signed"`
+				Valid  bool `json:"valid"`
+			} `json:"subsigs"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(inspection), &result))
+		require.False(t, result.AuthAddrValid)
+		require.Equal(t, 0, result.ValidSignatures)
+		require.False(t, result.ThresholdMet)
+		require.True(t, result.Subsigs[0].Signed)
+		require.False(t, result.Subsigs[0].Valid)
+	})
+
+	t.Run("no msig", func(t *testing.T) {
+		encodedStx := mustDecodeB64(t, "gqNzaWfEQC/nu0j+joowxkE3uMMx3SPZFzOHq8YdeTjqYVgV5r6xL/w4wRaYKhZXSVbeTnr2udAsbcxWUm7mhYTzH7AeEwejdHhuiaNhbXTOAA9CQKNmZWXNA+iiZnYCo2dlbqx0ZXN0bmV0LXYxLjCiZ2jEIEhjtRiks8hOyBDyLU8QgcsPcfBZp6wg3sYvf3DlCToiomx2zQPqo3JjdsQgl7l6dPAmNXWetJO9FG0r804t6RxaTs3rLmdqLkHub8Ojc25kxCDUYfSDPMXT2TQv+8ZvQtqEoK0G1xsTfW1wkIkR+AfA4qR0eXBlo3BheQ==")
+		_, err := InspectMultisigSignedTransaction(encodedStx)
+		require.Error(t, err)
+	})
+}
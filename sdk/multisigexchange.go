@@ -0,0 +1,379 @@
+package sdk
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/json"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// multisigExchangeVersion is the version of the multisig exchange document.
+const multisigExchangeVersion = 1
+
+// multisigExchangeChunkPrefix starts every chunk of an encoded multisig exchange document.
+const multisigExchangeChunkPrefix = "msigx"
+
+// multisigExchangeSig is a signature of the transaction at index Txn by the contributing account at
+// index Subsig of the multisig account.
+type multisigExchangeSig struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Txn    int             `codec:"t"`
+	Subsig int             `codec:"k"`
+	Sig    types.Signature `codec:"s"`
+}
+
+type multisigExchangeDocument struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Version     int                   `codec:"v"`
+	Multisig    multisigPreimage      `codec:"msig"`
+	Txns        []types.Transaction   `codec:"txns"`
+	Sigs        []multisigExchangeSig `codec:"sigs"`
+	Description string                `codec:"desc"`
+	ExpiryRound uint64                `codec:"exp"`
+}
+
+// MultisigExchange is a document which co-signers of a MultisigAccount pass to each other to sign a
+// transaction group. It holds the multisig account definition, the unsigned transactions, the
+// signatures gathered so far, a description, and the round after which the transactions expire.
+//
+// The document can be encoded as msgpack, base64 msgpack or JSON, and split into chunks small enough
+// for QR codes, or shown as an animated QR code with `EncodeAnimatedQR()`.
+type MultisigExchange struct {
+	session     *MultisigSession
+	description string
+	expiryRound uint64
+}
+
+// NewMultisigExchange creates a MultisigExchange for the signatures gathered by a MultisigSession.
+// The exchange shares the session, so signatures added to either are visible in both.
+//
+// If expiryRound is 0, the last valid round of the transactions is used.
+func NewMultisigExchange(session *MultisigSession, description string, expiryRound int64) (*MultisigExchange, error) {
+	if expiryRound < 0 {
+		return nil, errNegativeArgument
+	}
+	exchange := &MultisigExchange{
+		session:     session,
+		description: description,
+		expiryRound: uint64(expiryRound),
+	}
+	if exchange.expiryRound == 0 {
+		for _, txn := range session.txns {
+			if uint64(txn.LastValid) > exchange.expiryRound {
+				exchange.expiryRound = uint64(txn.LastValid)
+			}
+		}
+	}
+	return exchange, nil
+}
+
+// Session returns the MultisigSession of this exchange, which can be used to add signatures and to
+// track the signing progress.
+func (e *MultisigExchange) Session() *MultisigSession {
+	return e.session
+}
+
+// Account returns the MultisigAccount which signs the transactions of this exchange.
+func (e *MultisigExchange) Account() *MultisigAccount {
+	return &MultisigAccount{e.session.account}
+}
+
+// Transactions returns the unsigned transactions of this exchange.
+func (e *MultisigExchange) Transactions() *BytesArray {
+	txns := make([][]byte, len(e.session.txns))
+	for i := range e.session.txns {
+		txns[i] = msgpack.Encode(&e.session.txns[i])
+	}
+	return &BytesArray{txns}
+}
+
+// Description returns the description of this exchange.
+func (e *MultisigExchange) Description() string {
+	return e.description
+}
+
+// ExpiryRound returns the round after which the transactions of this exchange can no longer be
+// committed.
+func (e *MultisigExchange) ExpiryRound() int64 {
+	return int64(e.expiryRound)
+}
+
+// IsExpired returns true if the transactions of this exchange can no longer be committed at the
+// given round.
+func (e *MultisigExchange) IsExpired(currentRound int64) bool {
+	return currentRound > int64(e.expiryRound)
+}
+
+// Merge adds the signatures of another exchange for the same multisig account and transactions to
+// this exchange. Every signature is verified, and the merge fails without changing this exchange if
+// any of them is invalid or conflicts with a signature already present.
+func (e *MultisigExchange) Merge(other *MultisigExchange) error {
+	if len(other.session.txIDs) != len(e.session.txIDs) {
+		return errors.New("exchanges do not have the same transactions")
+	}
+	for i, txID := range other.session.txIDs {
+		if txID != e.session.txIDs[i] {
+			return errors.New("exchanges do not have the same transactions")
+		}
+	}
+	if e.session.address != other.session.address {
+		return errors.New("exchanges do not have the same multisig account")
+	}
+
+	for txnIndex, sigs := range other.session.sigs {
+		for subsigIndex, sig := range sigs {
+			if sig == nil {
+				continue
+			}
+			err := e.session.checkSubsig(txnIndex, subsigIndex, *sig)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for txnIndex, sigs := range other.session.sigs {
+		for subsigIndex, sig := range sigs {
+			if sig != nil {
+				e.session.setSubsig(txnIndex, subsigIndex, *sig)
+			}
+		}
+	}
+	return nil
+}
+
+// MergeSignedTransactions adds the signatures of partially signed transactions, as produced by
+// `MergeMultisigTransactions()` or `MultisigSession.PartiallySignedTransactions()`, to this exchange.
+func (e *MultisigExchange) MergeSignedTransactions(encodedSignedTxns *BytesArray) error {
+	for i, stx := range encodedSignedTxns.Extract() {
+		_, err := e.session.AddSignedTransaction(stx)
+		if err != nil {
+			return fmt.Errorf("could not merge signed transaction %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (e *MultisigExchange) document() multisigExchangeDocument {
+	doc := multisigExchangeDocument{
+		Version:     multisigExchangeVersion,
+		Multisig:    makeMultisigPreimage(e.session.account),
+		Txns:        e.session.txns,
+		Description: e.description,
+		ExpiryRound: e.expiryRound,
+	}
+	for txnIndex, sigs := range e.session.sigs {
+		for subsigIndex, sig := range sigs {
+			if sig != nil {
+				doc.Sigs = append(doc.Sigs, multisigExchangeSig{Txn: txnIndex, Subsig: subsigIndex, Sig: *sig})
+			}
+		}
+	}
+	return doc
+}
+
+// Encode encodes this exchange into a msgpack document.
+func (e *MultisigExchange) Encode() []byte {
+	doc := e.document()
+	return msgpack.Encode(&doc)
+}
+
+// EncodeBase64 encodes this exchange into a base64 encoded msgpack document.
+func (e *MultisigExchange) EncodeBase64() string {
+	return base64.StdEncoding.EncodeToString(e.Encode())
+}
+
+// EncodeJSON encodes this exchange into a JSON document.
+func (e *MultisigExchange) EncodeJSON() string {
+	doc := e.document()
+	return string(json.Encode(&doc))
+}
+
+func multisigExchangeFromDocument(doc multisigExchangeDocument) (*MultisigExchange, error) {
+	if doc.Version != multisigExchangeVersion {
+		return nil, fmt.Errorf("unsupported multisig exchange version: %d", doc.Version)
+	}
+	account, err := doc.Multisig.account()
+	if err != nil {
+		return nil, fmt.Errorf("invalid multisig account: %w", err)
+	}
+	txns := make([][]byte, len(doc.Txns))
+	for i := range doc.Txns {
+		txns[i] = msgpack.Encode(&doc.Txns[i])
+	}
+	session, err := NewMultisigSession(&MultisigAccount{account}, &BytesArray{txns})
+	if err != nil {
+		return nil, err
+	}
+	for _, sig := range doc.Sigs {
+		if sig.Txn < 0 || sig.Txn >= len(session.txns) || sig.Subsig < 0 || sig.Subsig >= len(account.Pks) {
+			return nil, errors.New("signature index out of range")
+		}
+		err = session.checkSubsig(sig.Txn, sig.Subsig, sig.Sig)
+		if err != nil {
+			return nil, err
+		}
+		session.setSubsig(sig.Txn, sig.Subsig, sig.Sig)
+	}
+	return &MultisigExchange{
+		session:     session,
+		description: doc.Description,
+		expiryRound: doc.ExpiryRound,
+	}, nil
+}
+
+// DecodeMultisigExchange decodes a msgpack document created by `MultisigExchange.Encode()`. Every
+// signature in the document is verified.
+func DecodeMultisigExchange(encoded []byte) (*MultisigExchange, error) {
+	var doc multisigExchangeDocument
+	err := msgpack.Decode(encoded, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode multisig exchange: %w", err)
+	}
+	return multisigExchangeFromDocument(doc)
+}
+
+// DecodeMultisigExchangeBase64 decodes a document created by `MultisigExchange.EncodeBase64()`.
+func DecodeMultisigExchangeBase64(encoded string) (*MultisigExchange, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeMultisigExchange(decoded)
+}
+
+// DecodeMultisigExchangeJSON decodes a JSON document created by `MultisigExchange.EncodeJSON()`.
+func DecodeMultisigExchangeJSON(encoded string) (*MultisigExchange, error) {
+	var doc multisigExchangeDocument
+	err := json.Decode([]byte(encoded), &doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode multisig exchange: %w", err)
+	}
+	return multisigExchangeFromDocument(doc)
+}
+
+func multisigExchangeChecksum(encoded []byte) string {
+	digest := sha512.Sum512_256(encoded)
+	return hex.EncodeToString(digest[:4])
+}
+
+// EncodeChunks encodes this exchange and splits it into chunks of at most maxChunkLength characters,
+// for example to show them as a sequence of QR codes. Each chunk has the form
+// "msigx:<index>/<total>:<checksum>:<data>", so that the chunks can be collected in any order and
+// reassembled with `DecodeMultisigExchangeChunks()`.
+func (e *MultisigExchange) EncodeChunks(maxChunkLength int) (*StringArray, error) {
+	encoded := e.Encode()
+	checksum := multisigExchangeChecksum(encoded)
+	data := base64.RawURLEncoding.EncodeToString(encoded)
+
+	// The header is longest for the last chunk, so it bounds the data length of every chunk
+	headerLength := func(total int) int {
+		return len(fmt.Sprintf("%s:%d/%d:%s:", multisigExchangeChunkPrefix, total, total, checksum))
+	}
+	total := 1
+	for {
+		dataLength := maxChunkLength - headerLength(total)
+		if dataLength <= 0 {
+			return nil, fmt.Errorf("chunk length %d is too small", maxChunkLength)
+		}
+		needed := (len(data) + dataLength - 1) / dataLength
+		if needed <= total {
+			break
+		}
+		total = needed
+	}
+
+	chunks := &StringArray{}
+	dataLength := (len(data) + total - 1) / total
+	for i := 0; i < total; i++ {
+		end := (i + 1) * dataLength
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks.Append(fmt.Sprintf("%s:%d/%d:%s:%s", multisigExchangeChunkPrefix, i+1, total, checksum, data[i*dataLength:end]))
+	}
+	return chunks, nil
+}
+
+// DecodeMultisigExchangeChunks reassembles and decodes the chunks created by
+// `MultisigExchange.EncodeChunks()`. The chunks may be given in any order, and duplicates are
+// ignored.
+func DecodeMultisigExchangeChunks(chunks *StringArray) (*MultisigExchange, error) {
+	var checksum string
+	var parts []string
+	for _, chunk := range chunks.Extract() {
+		fields := strings.SplitN(chunk, ":", 4)
+		if len(fields) != 4 || fields[0] != multisigExchangeChunkPrefix {
+			return nil, errors.New("not a multisig exchange chunk")
+		}
+		position := strings.SplitN(fields[1], "/", 2)
+		if len(position) != 2 {
+			return nil, errors.New("invalid chunk position")
+		}
+		index, err := strconv.Atoi(position[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid chunk position: %w", err)
+		}
+		total, err := strconv.Atoi(position[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid chunk position: %w", err)
+		}
+		if total < 1 || index < 1 || index > total {
+			return nil, fmt.Errorf("invalid chunk position: %s", fields[1])
+		}
+		if parts == nil {
+			checksum = fields[2]
+			parts = make([]string, total)
+		}
+		if fields[2] != checksum || total != len(parts) {
+			return nil, errors.New("chunks belong to different documents")
+		}
+		parts[index-1] = fields[3]
+	}
+	if parts == nil {
+		return nil, errors.New("no chunks provided")
+	}
+	for i, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("missing chunk %d of %d", i+1, len(parts))
+		}
+	}
+
+	encoded, err := base64.RawURLEncoding.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		return nil, err
+	}
+	if multisigExchangeChecksum(encoded) != checksum {
+		return nil, errors.New("checksum of the reassembled document does not match")
+	}
+	return DecodeMultisigExchange(encoded)
+}
+
+// EncodeAnimatedQR encodes this exchange as an animated QR code of the payload type
+// QRPayloadMultisigExchange, with at most maxFragmentLength bytes of the document in each frame.
+// Receivers reassemble it with an AnimatedQRDecoder and `DecodeMultisigExchangeAnimatedQR()`.
+func (e *MultisigExchange) EncodeAnimatedQR(maxFragmentLength int) (*AnimatedQREncoder, error) {
+	return NewAnimatedQREncoder(QRPayloadMultisigExchange, e.Encode(), maxFragmentLength)
+}
+
+// DecodeMultisigExchangeAnimatedQR decodes the exchange reassembled by an AnimatedQRDecoder from
+// the frames of `MultisigExchange.EncodeAnimatedQR()`. An error is returned if the decoder is not
+// complete or its payload is not a multisig exchange.
+func DecodeMultisigExchangeAnimatedQR(decoder *AnimatedQRDecoder) (*MultisigExchange, error) {
+	payload, err := decoder.Payload()
+	if err != nil {
+		return nil, err
+	}
+	if decoder.PayloadType() != QRPayloadMultisigExchange {
+		return nil, fmt.Errorf("animated QR payload is '%s', not a multisig exchange", decoder.PayloadType())
+	}
+	return DecodeMultisigExchange(payload)
+}
@@ -0,0 +1,141 @@
+package sdk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultisigExchange(t *testing.T) {
+	t.Parallel()
+	ma, acct1, acct2, _ := makeTestMultisigAccount(t)
+	group := makeTestMultisigGroup(t, ma, 1, 2)
+
+	session, err := NewMultisigSession(ma, group)
+	require.NoError(t, err)
+	require.NoError(t, session.Sign(acct1.PrivateKey))
+	exchange, err := NewMultisigExchange(session, "pay rent", 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1002), exchange.ExpiryRound())
+	require.False(t, exchange.IsExpired(1002))
+	require.True(t, exchange.IsExpired(1003))
+
+	// every encoding round trips
+	fromMsgpack, err := DecodeMultisigExchange(exchange.Encode())
+	require.NoError(t, err)
+	fromBase64, err := DecodeMultisigExchangeBase64(exchange.EncodeBase64())
+	require.NoError(t, err)
+	fromJSON, err := DecodeMultisigExchangeJSON(exchange.EncodeJSON())
+	require.NoError(t, err)
+	for _, decoded := range []*MultisigExchange{fromMsgpack, fromBase64, fromJSON} {
+		require.Equal(t, exchange.Encode(), decoded.Encode())
+		require.Equal(t, "pay rent", decoded.Description())
+		require.Equal(t, group.Extract(), decoded.Transactions().Extract())
+		require.Equal(t, 1, decoded.Session().SignaturesNeeded(1))
+	}
+	addr, err := fromJSON.Account().Address()
+	require.NoError(t, err)
+	expectedAddr, err := ma.Address()
+	require.NoError(t, err)
+	require.Equal(t, expectedAddr, addr)
+
+	// the second co-signer signs their copy, and the copies are merged
+	require.NoError(t, fromMsgpack.Session().Sign(acct2.PrivateKey))
+	require.NoError(t, exchange.Merge(fromMsgpack))
+	require.True(t, exchange.Session().IsComplete())
+
+	// signed transactions from other tools can be merged as well
+	stx, err := SignMultisigTransaction(acct2.PrivateKey, ma, group.Get(0))
+	require.NoError(t, err)
+	require.NoError(t, fromBase64.MergeSignedTransactions(&BytesArray{[][]byte{stx}}))
+	require.Equal(t, 0, fromBase64.Session().SignaturesNeeded(0))
+	require.Equal(t, 1, fromBase64.Session().SignaturesNeeded(1))
+
+	// exchanges for other transactions cannot be merged
+	otherSession, err := NewMultisigSessionForTransaction(ma, makeTestMultisigGroup(t, ma, 3).Get(0))
+	require.NoError(t, err)
+	other, err := NewMultisigExchange(otherSession, "", 0)
+	require.NoError(t, err)
+	require.Error(t, exchange.Merge(other))
+}
+
+func TestMultisigExchangeChunks(t *testing.T) {
+	t.Parallel()
+	ma, acct1, _, _ := makeTestMultisigAccount(t)
+	session, err := NewMultisigSession(ma, makeTestMultisigGroup(t, ma, 1, 2, 3))
+	require.NoError(t, err)
+	require.NoError(t, session.Sign(acct1.PrivateKey))
+	exchange, err := NewMultisigExchange(session, "", 5000)
+	require.NoError(t, err)
+
+	chunks, err := exchange.EncodeChunks(200)
+	require.NoError(t, err)
+	require.Greater(t, chunks.Length(), 1)
+	for _, chunk := range chunks.Extract() {
+		require.LessOrEqual(t, len(chunk), 200)
+	}
+
+	// chunks may arrive in any order, with duplicates
+	reordered := &StringArray{}
+	for i := chunks.Length() - 1; i >= 0; i-- {
+		reordered.Append(chunks.Get(i))
+	}
+	reordered.Append(chunks.Get(0))
+	decoded, err := DecodeMultisigExchangeChunks(reordered)
+	require.NoError(t, err)
+	require.Equal(t, exchange.Encode(), decoded.Encode())
+
+	_, err = DecodeMultisigExchangeChunks(&StringArray{chunks.Extract()[1:]})
+	require.ErrorContains(t, err, "missing chunk 1")
+
+	otherChunks, err := NewMultisigExchange(session, "other", 0)
+	require.NoError(t, err)
+	otherEncoded, err := otherChunks.EncodeChunks(200)
+	require.NoError(t, err)
+	_, err = DecodeMultisigExchangeChunks(&StringArray{[]string{chunks.Get(0), otherEncoded.Get(1)}})
+	require.Error(t, err)
+
+	_, err = exchange.EncodeChunks(10)
+	require.Error(t, err)
+}
+
+func TestMultisigExchangeAnimatedQR(t *testing.T) {
+	t.Parallel()
+	ma, acct1, _, _ := makeTestMultisigAccount(t)
+	session, err := NewMultisigSession(ma, makeTestMultisigGroup(t, ma, 1, 2, 3))
+	require.NoError(t, err)
+	require.NoError(t, session.Sign(acct1.PrivateKey))
+	exchange, err := NewMultisigExchange(session, "", 5000)
+	require.NoError(t, err)
+
+	encoder, err := exchange.EncodeAnimatedQR(100)
+	require.NoError(t, err)
+	require.False(t, encoder.IsSingleFrame())
+
+	// frames may arrive in any order, with duplicates
+	decoder := NewAnimatedQRDecoder()
+	for seq := encoder.FragmentCount(); seq >= 1; seq-- {
+		frame, err := encoder.Frame(seq)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(frame, animatedQRScheme+QRPayloadMultisigExchange+"/"))
+		_, err = DecodeMultisigExchangeAnimatedQR(decoder)
+		require.ErrorContains(t, err, "incomplete")
+		require.NoError(t, decoder.ReceiveFrame(frame))
+		require.NoError(t, decoder.ReceiveFrame(frame))
+	}
+	decoded, err := DecodeMultisigExchangeAnimatedQR(decoder)
+	require.NoError(t, err)
+	require.Equal(t, exchange.Encode(), decoded.Encode())
+
+	// a payload of another type is not a multisig exchange
+	other, err := NewAnimatedQREncoder(QRPayloadBytes, exchange.Encode(), 1000)
+	require.NoError(t, err)
+	decoder = NewAnimatedQRDecoder()
+	require.NoError(t, decoder.ReceiveFrame(other.NextFrame()))
+	_, err = DecodeMultisigExchangeAnimatedQR(decoder)
+	require.ErrorContains(t, err, "not a multisig exchange")
+
+	_, err = exchange.EncodeAnimatedQR(0)
+	require.Error(t, err)
+}
@@ -0,0 +1,407 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"golang.org/x/crypto/ed25519"
+)
+
+// verifyTransactionSignature returns true if sig is a valid signature of txn by the public key pk.
+func verifyTransactionSignature(pk ed25519.PublicKey, txn types.Transaction, sig types.Signature) bool {
+	if len(pk) != ed25519.PublicKeySize {
+		return false
+	}
+	bytesToSign := bytes.Join([][]byte{txidPrefix, msgpack.Encode(txn)}, nil)
+	return ed25519.Verify(pk, bytesToSign, sig[:])
+}
+
+// checkMultisigMatchesAccount returns an error if msig was not made for the given multisig account.
+func checkMultisigMatchesAccount(msig types.MultisigSig, account crypto.MultisigAccount) error {
+	if msig.Version != account.Version || msig.Threshold != account.Threshold || len(msig.Subsigs) != len(account.Pks) {
+		return errors.New("multisig signature does not match the multisig account")
+	}
+	for i, subsig := range msig.Subsigs {
+		if !bytes.Equal(subsig.Key, account.Pks[i]) {
+			return errors.New("multisig signature does not match the multisig account")
+		}
+	}
+	return nil
+}
+
+// MultisigSession collects the signatures of the contributing accounts of a MultisigAccount for a
+// transaction or a group of transactions, until the threshold is met for every transaction.
+//
+// Signatures may be added in any order and from any source, and each one is verified as it is
+// added.
+type MultisigSession struct {
+	account crypto.MultisigAccount
+	address types.Address
+	txns    []types.Transaction
+	txIDs   []string
+	// sigs holds the signature of each contributing account for each transaction, if present
+	sigs [][]*types.Signature
+}
+
+// NewMultisigSession creates a MultisigSession for a group of transactions, all of which must be
+// signed by the given MultisigAccount. If the group has more than one transaction, the group ID
+// must be assigned and valid.
+func NewMultisigSession(account *MultisigAccount, encodedTxns *BytesArray) (*MultisigSession, error) {
+	txns, err := decodeTxns(encodedTxns)
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) == 0 {
+		return nil, errors.New("no transactions provided")
+	}
+	if len(txns) > 1 {
+		// verifyTxnsGroupID clears the group IDs of the transactions it is given
+		valid, err := verifyTxnsGroupID(append([]types.Transaction(nil), txns...))
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			return nil, errors.New("transactions do not form a valid group")
+		}
+	}
+	address, err := account.value.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &MultisigSession{
+		account: account.value,
+		address: address,
+		txns:    txns,
+		txIDs:   make([]string, len(txns)),
+		sigs:    make([][]*types.Signature, len(txns)),
+	}
+	for i, txn := range txns {
+		session.txIDs[i] = crypto.TransactionIDString(txn)
+		session.sigs[i] = make([]*types.Signature, len(account.value.Pks))
+	}
+	return session, nil
+}
+
+// NewMultisigSessionForTransaction creates a MultisigSession for a single transaction.
+func NewMultisigSessionForTransaction(account *MultisigAccount, encodedTx []byte) (*MultisigSession, error) {
+	return NewMultisigSession(account, &BytesArray{[][]byte{encodedTx}})
+}
+
+// Count returns the number of transactions in this session.
+func (s *MultisigSession) Count() int {
+	return len(s.txns)
+}
+
+func (s *MultisigSession) contributingAddress(subsigIndex int) string {
+	var addr types.Address
+	copy(addr[:], s.account.Pks[subsigIndex])
+	return addr.String()
+}
+
+// checkSubsig returns an error if the signature of a contributing account does not verify, or if it
+// differs from one already recorded.
+func (s *MultisigSession) checkSubsig(txnIndex, subsigIndex int, sig types.Signature) error {
+	if !verifyTransactionSignature(s.account.Pks[subsigIndex], s.txns[txnIndex], sig) {
+		return fmt.Errorf("signature of %s does not verify for transaction %d", s.contributingAddress(subsigIndex), txnIndex)
+	}
+	existing := s.sigs[txnIndex][subsigIndex]
+	if existing != nil && *existing != sig {
+		return fmt.Errorf("conflicting signatures of %s for transaction %d", s.contributingAddress(subsigIndex), txnIndex)
+	}
+	return nil
+}
+
+func (s *MultisigSession) setSubsig(txnIndex, subsigIndex int, sig types.Signature) {
+	s.sigs[txnIndex][subsigIndex] = &sig
+}
+
+// subsigIndexes returns the indexes of the subsigs of pk. A public key may appear more than once in
+// a multisig account, in which case its signature fills every one of its subsigs.
+func (s *MultisigSession) subsigIndexes(pk []byte) []int {
+	var indexes []int
+	for i, accountPk := range s.account.Pks {
+		if bytes.Equal(accountPk, pk) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func (s *MultisigSession) findTransaction(txn types.Transaction) (int, error) {
+	txID := crypto.TransactionIDString(txn)
+	for i, id := range s.txIDs {
+		if id == txID {
+			return i, nil
+		}
+	}
+	// A transaction from the same sender and group, or with the same sender and validity range
+	// but a different ID, was most likely modified
+	for i, sessionTxn := range s.txns {
+		sameGroup := sessionTxn.Group != (types.Digest{}) && sessionTxn.Group == txn.Group
+		sameRange := sessionTxn.FirstValid == txn.FirstValid && sessionTxn.LastValid == txn.LastValid
+		if sessionTxn.Sender == txn.Sender && (sameGroup || sameRange) {
+			return -1, fmt.Errorf("signed transaction %s conflicts with transaction %d (%s) of the session", txID, i, s.txIDs[i])
+		}
+	}
+	return -1, fmt.Errorf("signed transaction %s is not part of the session", txID)
+}
+
+// AddSignedTransaction adds the signatures of a partially or fully signed multisig transaction, as
+// created by `SignMultisigTransaction()`, `AttachMultisigSignature()` or
+// `MergeMultisigTransactions()`, and returns the index of the transaction in the session.
+//
+// An error is returned if the transaction is not part of the session, if it was signed for another
+// multisig account, or if any of its signatures does not verify. In that case, none of its
+// signatures are added.
+func (s *MultisigSession) AddSignedTransaction(encodedSignedTx []byte) (int, error) {
+	var stx types.SignedTxn
+	err := msgpack.Decode(encodedSignedTx, &stx)
+	if err != nil {
+		return -1, err
+	}
+	if stx.Msig.Blank() {
+		return -1, errors.New("transaction is not signed by a multisig account")
+	}
+	err = checkMultisigMatchesAccount(stx.Msig, s.account)
+	if err != nil {
+		return -1, err
+	}
+	index, err := s.findTransaction(stx.Txn)
+	if err != nil {
+		return -1, err
+	}
+
+	// Verify every signature before adding any, so that an invalid blob leaves the session unchanged
+	for i, subsig := range stx.Msig.Subsigs {
+		if subsig.Sig == (types.Signature{}) {
+			continue
+		}
+		for _, j := range s.subsigIndexes(s.account.Pks[i]) {
+			err = s.checkSubsig(index, j, subsig.Sig)
+			if err != nil {
+				return -1, err
+			}
+		}
+	}
+	for i, subsig := range stx.Msig.Subsigs {
+		if subsig.Sig != (types.Signature{}) {
+			for _, j := range s.subsigIndexes(s.account.Pks[i]) {
+				s.setSubsig(index, j, subsig.Sig)
+			}
+		}
+	}
+	return index, nil
+}
+
+// AddSignature adds a raw signature of the transaction at the given index by one of the
+// contributing addresses of the multisig account.
+func (s *MultisigSession) AddSignature(index int, signer string, signature []byte) error {
+	if index < 0 || index >= len(s.txns) {
+		return fmt.Errorf("transaction index %d out of range", index)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("incorrect signature length expected %d, got %d", ed25519.SignatureSize, len(signature))
+	}
+	signerAddr, err := types.DecodeAddress(signer)
+	if err != nil {
+		return err
+	}
+	subsigIndexes := s.subsigIndexes(signerAddr[:])
+	if len(subsigIndexes) == 0 {
+		return errors.New("signer address does not match any of the addresses in the multisig account")
+	}
+	var sig types.Signature
+	copy(sig[:], signature)
+	for _, i := range subsigIndexes {
+		err = s.checkSubsig(index, i, sig)
+		if err != nil {
+			return err
+		}
+	}
+	for _, i := range subsigIndexes {
+		s.setSubsig(index, i, sig)
+	}
+	return nil
+}
+
+// Sign signs every transaction in the session with the private key of one of the contributing
+// accounts.
+func (s *MultisigSession) Sign(sk []byte) error {
+	if len(sk) != ed25519.PrivateKeySize {
+		return fmt.Errorf("Incorrect privateKey length expected %d, got %d", ed25519.PrivateKeySize, len(sk))
+	}
+	signer, err := GenerateAddressFromSK(sk)
+	if err != nil {
+		return err
+	}
+	for i, txn := range s.txns {
+		_, stxBytes, err := crypto.SignMultisigTransaction(sk, s.account, txn)
+		if err != nil {
+			return err
+		}
+		var stx types.SignedTxn
+		err = msgpack.Decode(stxBytes, &stx)
+		if err != nil {
+			return err
+		}
+		// AddSignature fills every subsig of the signer, so one signature is enough
+		for _, subsig := range stx.Msig.Subsigs {
+			if subsig.Sig != (types.Signature{}) {
+				err = s.AddSignature(i, signer, subsig.Sig[:])
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MultisigSession) signatureCount(index int) int {
+	count := 0
+	for _, sig := range s.sigs[index] {
+		if sig != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// SignedAddresses returns the contributing addresses which have signed the transaction at the
+// given index.
+func (s *MultisigSession) SignedAddresses(index int) *StringArray {
+	addrs := &StringArray{}
+	if index < 0 || index >= len(s.txns) {
+		return addrs
+	}
+	for i, sig := range s.sigs[index] {
+		if sig != nil {
+			addrs.Append(s.contributingAddress(i))
+		}
+	}
+	return addrs
+}
+
+// MissingAddresses returns the contributing addresses which have not signed the transaction at the
+// given index.
+func (s *MultisigSession) MissingAddresses(index int) *StringArray {
+	addrs := &StringArray{}
+	if index < 0 || index >= len(s.txns) {
+		return addrs
+	}
+	for i, sig := range s.sigs[index] {
+		if sig == nil {
+			addrs.Append(s.contributingAddress(i))
+		}
+	}
+	return addrs
+}
+
+// SignaturesNeeded returns how many more signatures the transaction at the given index needs to
+// meet the threshold of the multisig account.
+func (s *MultisigSession) SignaturesNeeded(index int) int {
+	if index < 0 || index >= len(s.txns) {
+		return 0
+	}
+	needed := int(s.account.Threshold) - s.signatureCount(index)
+	if needed < 0 {
+		return 0
+	}
+	return needed
+}
+
+// IsComplete returns true if the threshold is met for every transaction in the session.
+func (s *MultisigSession) IsComplete() bool {
+	for i := range s.txns {
+		if s.SignaturesNeeded(i) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type multisigSessionTxnStatus struct {
+	TxID             string   `json:"txID"`
+	Signed           []string `json:"signed"`
+	Missing          []string `json:"missing"`
+	SignaturesNeeded int      `json:"signaturesNeeded"`
+}
+
+type multisigSessionStatus struct {
+	Address      string                     `json:"address"`
+	Threshold    int                        `json:"threshold"`
+	Complete     bool                       `json:"complete"`
+	Transactions []multisigSessionTxnStatus `json:"transactions"`
+}
+
+// GetStatusJSON returns the progress of the session as a JSON object:
+//
+//	{"address":"...","threshold":2,"complete":false,
+//	 "transactions":[{"txID":"...","signed":["..."],"missing":["...","..."],"signaturesNeeded":1}]}
+func (s *MultisigSession) GetStatusJSON() (string, error) {
+	status := multisigSessionStatus{
+		Address:      s.address.String(),
+		Threshold:    int(s.account.Threshold),
+		Complete:     s.IsComplete(),
+		Transactions: make([]multisigSessionTxnStatus, len(s.txns)),
+	}
+	for i := range s.txns {
+		status.Transactions[i] = multisigSessionTxnStatus{
+			TxID:             s.txIDs[i],
+			Signed:           s.SignedAddresses(i).Extract(),
+			Missing:          s.MissingAddresses(i).Extract(),
+			SignaturesNeeded: s.SignaturesNeeded(i),
+		}
+	}
+	encoded, err := json.Marshal(status)
+	return string(encoded), err
+}
+
+func (s *MultisigSession) signedTransaction(index int) []byte {
+	stx := types.SignedTxn{
+		Txn: s.txns[index],
+		Msig: types.MultisigSig{
+			Version:   s.account.Version,
+			Threshold: s.account.Threshold,
+			Subsigs:   make([]types.MultisigSubsig, len(s.account.Pks)),
+		},
+	}
+	for i, pk := range s.account.Pks {
+		stx.Msig.Subsigs[i].Key = append(ed25519.PublicKey(nil), pk...)
+		if s.sigs[index][i] != nil {
+			stx.Msig.Subsigs[i].Sig = *s.sigs[index][i]
+		}
+	}
+	if s.txns[index].Sender != s.address {
+		stx.AuthAddr = s.address
+	}
+	return msgpack.Encode(stx)
+}
+
+// PartiallySignedTransactions returns the transactions of the session with the signatures gathered
+// so far, for example to pass them on to the next co-signer.
+func (s *MultisigSession) PartiallySignedTransactions() *BytesArray {
+	stxns := make([][]byte, len(s.txns))
+	for i := range s.txns {
+		stxns[i] = s.signedTransaction(i)
+	}
+	return &BytesArray{stxns}
+}
+
+// SignedTransactions returns the fully signed transactions of the session. An error is returned if
+// the threshold is not met for every transaction.
+func (s *MultisigSession) SignedTransactions() (*BytesArray, error) {
+	for i := range s.txns {
+		if needed := s.SignaturesNeeded(i); needed > 0 {
+			return nil, fmt.Errorf("transaction %d needs %d more signatures", i, needed)
+		}
+	}
+	return s.PartiallySignedTransactions(), nil
+}
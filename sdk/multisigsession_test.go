@@ -0,0 +1,195 @@
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func makeTestMultisigGroup(t *testing.T, ma *MultisigAccount, amounts ...uint64) *BytesArray {
+	t.Helper()
+	msigAddr, err := ma.Address()
+	require.NoError(t, err)
+	params := types.SuggestedParams{
+		Fee:             1000,
+		FlatFee:         true,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     mustDecodeB64(t, "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI="),
+		FirstRoundValid: 2,
+		LastRoundValid:  1002,
+	}
+	txns := &BytesArray{}
+	for _, amount := range amounts {
+		txn, err := transaction.MakePaymentTxn(msigAddr, "S64XU5HQEY2XLHVUSO6RI3JL6NHC32I4LJHM32ZOM5VC4QPON7BZZRCU2E", amount, nil, "", params)
+		require.NoError(t, err)
+		txns.Append(msgpack.Encode(&txn))
+	}
+	if len(amounts) == 1 {
+		return txns
+	}
+	grouped, err := AssignGroupID(txns)
+	require.NoError(t, err)
+	return grouped
+}
+
+func TestMultisigSession(t *testing.T) {
+	t.Parallel()
+	ma, acct1, acct2, acct3 := makeTestMultisigAccount(t)
+	group := makeTestMultisigGroup(t, ma, 1, 2)
+
+	session, err := NewMultisigSession(ma, group)
+	require.NoError(t, err)
+	require.Equal(t, 2, session.Count())
+	require.Equal(t, 2, session.SignaturesNeeded(0))
+	require.False(t, session.IsComplete())
+	_, err = session.SignedTransactions()
+	require.Error(t, err)
+
+	// a blob from SignMultisigTransaction
+	stx1, err := SignMultisigTransaction(acct1.PrivateKey, ma, group.Get(0))
+	require.NoError(t, err)
+	index, err := session.AddSignedTransaction(stx1)
+	require.NoError(t, err)
+	require.Equal(t, 0, index)
+	require.Equal(t, []string{acct1.Address.String()}, session.SignedAddresses(0).Extract())
+	require.Equal(t, []string{acct2.Address.String(), acct3.Address.String()}, session.MissingAddresses(0).Extract())
+	require.Equal(t, 1, session.SignaturesNeeded(0))
+
+	// adding the same signature again is harmless
+	_, err = session.AddSignedTransaction(stx1)
+	require.NoError(t, err)
+
+	// a raw signature, as used by AttachMultisigSignature
+	bytesToSign, err := RawTransactionBytesToSign(group.Get(0))
+	require.NoError(t, err)
+	sig3 := ed25519.Sign(acct3.PrivateKey, bytesToSign)
+	require.NoError(t, session.AddSignature(0, acct3.Address.String(), sig3))
+	require.Equal(t, 0, session.SignaturesNeeded(0))
+	require.Error(t, session.AddSignature(1, acct3.Address.String(), sig3))
+
+	require.NoError(t, session.Sign(acct2.PrivateKey))
+	require.False(t, session.IsComplete())
+	stx1Second, err := SignMultisigTransaction(acct1.PrivateKey, ma, group.Get(1))
+	require.NoError(t, err)
+	index, err = session.AddSignedTransaction(stx1Second)
+	require.NoError(t, err)
+	require.Equal(t, 1, index)
+	require.True(t, session.IsComplete())
+
+	statusJSON, err := session.GetStatusJSON()
+	require.NoError(t, err)
+	var status struct {
+		Complete     bool `json:"complete"`
+		Threshold    int  `json:"threshold"`
+		Transactions []struct {
+			Signed []string `json:"signed"`
+		} `json:"transactions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(statusJSON), &status))
+	require.True(t, status.Complete)
+	require.Equal(t, 2, status.Threshold)
+	require.Len(t, status.Transactions[0].Signed, 3)
+	require.Len(t, status.Transactions[1].Signed, 2)
+
+	stxns, err := session.SignedTransactions()
+	require.NoError(t, err)
+	require.Equal(t, 2, stxns.Length())
+
+	// the result matches merging the individual signatures
+	stx2, err := SignMultisigTransaction(acct2.PrivateKey, ma, group.Get(0))
+	require.NoError(t, err)
+	stx3, err := AttachMultisigSignature(acct3.Address.String(), sig3, ma, group.Get(0))
+	require.NoError(t, err)
+	merged, err := MergeMultisigTransactions(stx1, stx2)
+	require.NoError(t, err)
+	merged, err = MergeMultisigTransactions(merged, stx3)
+	require.NoError(t, err)
+	require.Equal(t, merged, stxns.Get(0))
+}
+
+func TestMultisigSessionRejects(t *testing.T) {
+	t.Parallel()
+	ma, acct1, _, _ := makeTestMultisigAccount(t)
+	txns := makeTestMultisigGroup(t, ma, 1)
+	session, err := NewMultisigSessionForTransaction(ma, txns.Get(0))
+	require.NoError(t, err)
+
+	// a modified transaction conflicts with the one in the session
+	var txn types.Transaction
+	require.NoError(t, msgpack.Decode(txns.Get(0), &txn))
+	txn.Amount = 1000
+	stx, err := SignMultisigTransaction(acct1.PrivateKey, ma, msgpack.Encode(&txn))
+	require.NoError(t, err)
+	_, err = session.AddSignedTransaction(stx)
+	require.ErrorContains(t, err, "conflicts")
+
+	// a signature which does not verify
+	var signed types.SignedTxn
+	stx, err = SignMultisigTransaction(acct1.PrivateKey, ma, txns.Get(0))
+	require.NoError(t, err)
+	require.NoError(t, msgpack.Decode(stx, &signed))
+	signed.Msig.Subsigs[0].Sig[0] ^= 1
+	_, err = session.AddSignedTransaction(msgpack.Encode(signed))
+	require.Error(t, err)
+	require.Equal(t, 2, session.SignaturesNeeded(0))
+
+	// a signature for another multisig account
+	other, err := MakeMultisigAccount(1, 1, &StringArray{[]string{acct1.Address.String()}})
+	require.NoError(t, err)
+	stx, err = SignMultisigTransaction(acct1.PrivateKey, other, txns.Get(0))
+	require.NoError(t, err)
+	_, err = session.AddSignedTransaction(stx)
+	require.Error(t, err)
+
+	// a signature by an account which is not part of the multisig account
+	outsider := crypto.GenerateAccount()
+	bytesToSign, err := RawTransactionBytesToSign(txns.Get(0))
+	require.NoError(t, err)
+	sig := ed25519.Sign(outsider.PrivateKey, bytesToSign)
+	require.Error(t, session.AddSignature(0, outsider.Address.String(), sig))
+}
+
+func TestMultisigSessionDuplicateKeys(t *testing.T) {
+	t.Parallel()
+	_, acct1, acct2, _ := makeTestMultisigAccount(t)
+	// acct1 appears twice, so its signature alone meets the threshold
+	ma, err := MakeMultisigAccount(1, 2, &StringArray{[]string{acct1.Address.String(), acct2.Address.String(), acct1.Address.String()}})
+	require.NoError(t, err)
+	txns := makeTestMultisigGroup(t, ma, 1)
+	bytesToSign, err := RawTransactionBytesToSign(txns.Get(0))
+	require.NoError(t, err)
+
+	session, err := NewMultisigSessionForTransaction(ma, txns.Get(0))
+	require.NoError(t, err)
+	require.NoError(t, session.AddSignature(0, acct1.Address.String(), ed25519.Sign(acct1.PrivateKey, bytesToSign)))
+	require.Equal(t, []string{acct1.Address.String(), acct1.Address.String()}, session.SignedAddresses(0).Extract())
+	require.True(t, session.IsComplete())
+
+	session, err = NewMultisigSessionForTransaction(ma, txns.Get(0))
+	require.NoError(t, err)
+	require.NoError(t, session.Sign(acct1.PrivateKey))
+	require.True(t, session.IsComplete())
+	signed, err := session.SignedTransactions()
+	require.NoError(t, err)
+	var stx types.SignedTxn
+	require.NoError(t, msgpack.Decode(signed.Get(0), &stx))
+	msigAddr, err := ma.Address()
+	require.NoError(t, err)
+	addr, err := types.DecodeAddress(msigAddr)
+	require.NoError(t, err)
+	require.True(t, crypto.VerifyMultisig(addr, bytesToSign, stx.Msig))
+
+	// a blob which only fills one of the subsigs of acct1 fills both
+	stx.Msig.Subsigs[2].Sig = types.Signature{}
+	session, err = NewMultisigSessionForTransaction(ma, txns.Get(0))
+	require.NoError(t, err)
+	_, err = session.AddSignedTransaction(msgpack.Encode(&stx))
+	require.NoError(t, err)
+	require.True(t, session.IsComplete())
+}
@@ -0,0 +1,286 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+const (
+	// incentiveEligibilityFee is the fee of an online key registration which makes the account
+	// eligible for staking rewards.
+	incentiveEligibilityFee = 2000000
+	// maxKeyregValidPeriod is the longest range of rounds a participation key may be registered for.
+	maxKeyregValidPeriod = 256*(1<<16) - 1
+)
+
+// participationKeyJSON is the participation key section of the algod endpoints
+// /v2/participation/{participation-id} and /v2/accounts/{address}.
+type participationKeyJSON struct {
+	SelectionKey    string `json:"selection-participation-key"`
+	StateProofKey   string `json:"state-proof-key"`
+	VoteKey         string `json:"vote-participation-key"`
+	VoteFirst       uint64 `json:"vote-first-valid"`
+	VoteLast        uint64 `json:"vote-last-valid"`
+	VoteKeyDilution uint64 `json:"vote-key-dilution"`
+}
+
+// participationKeyInfoJSON is the response of the algod endpoint /v2/participation/{participation-id}.
+type participationKeyInfoJSON struct {
+	Address string                `json:"address"`
+	Key     *participationKeyJSON `json:"key"`
+}
+
+// ParticipationKeyInfo holds the public keys and validity range of a participation key, as needed
+// to register it online with MakeOnlineKeyRegTxn. Its getters return the arguments of
+// MakeKeyRegTxnWithStateProofKey.
+type ParticipationKeyInfo struct {
+	address         types.Address
+	voteKey         [32]byte
+	selectionKey    [32]byte
+	stateProofKey   [64]byte
+	voteFirst       uint64
+	voteLast        uint64
+	voteKeyDilution uint64
+}
+
+// decodeParticipationKey decodes a key given in hex, with or without a 0x prefix, or in standard or
+// URL base64, with or without padding. Hex is tried first, since hex keys are valid base64 as well.
+func decodeParticipationKey(name, encoded string, key []byte) error {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil
+	}
+	decoded, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(encoded, "0x"), "0X"))
+	if err != nil {
+		for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+			if decoded, err = encoding.DecodeString(encoded); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not decode %s: it is neither hex nor base64", name)
+	}
+	if len(decoded) != len(key) {
+		return fmt.Errorf("%s must be %d bytes, got %d", name, len(key), len(decoded))
+	}
+	copy(key, decoded)
+	return nil
+}
+
+// ParseParticipationKeyInfo parses the JSON of a participation key, as exported by node operators
+// from the algod endpoint /v2/participation/{participation-id}. The "key" object of that response,
+// as found in the "participation" of /v2/accounts/{address}, is accepted on its own as well. Keys
+// are validated as with NewParticipationKeyInfo.
+func ParseParticipationKeyInfo(infoJSON string) (*ParticipationKeyInfo, error) {
+	var decoded participationKeyInfoJSON
+	err := json.Unmarshal([]byte(infoJSON), &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode participation key info: %w", err)
+	}
+	key := decoded.Key
+	if key == nil {
+		key = &participationKeyJSON{}
+		err = json.Unmarshal([]byte(infoJSON), key)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode participation key info: %w", err)
+		}
+	}
+	return newParticipationKeyInfo(decoded.Address, key.VoteKey, key.SelectionKey, key.StateProofKey, key.VoteFirst, key.VoteLast, key.VoteKeyDilution)
+}
+
+// NewParticipationKeyInfo creates a ParticipationKeyInfo from the details of a participation key,
+// such as those pasted from a node provider. The keys may be given in hex or base64, and the state
+// proof key may be empty. The account the key was generated for is optional as well.
+//
+// The vote key and selection key must be 32 bytes, the state proof key 64 bytes, and the dilution
+// must be between 1 and the number of rounds the key is valid for.
+func NewParticipationKeyInfo(address, voteKey, selectionKey, stateProofKey string, voteFirst, voteLast, voteKeyDilution *Uint64) (*ParticipationKeyInfo, error) {
+	voteFirstDecoded, err := voteFirst.Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract voteFirst: %v", err)
+	}
+	voteLastDecoded, err := voteLast.Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract voteLast: %v", err)
+	}
+	voteKeyDilutionDecoded, err := voteKeyDilution.Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract voteKeyDilution: %v", err)
+	}
+	return newParticipationKeyInfo(address, voteKey, selectionKey, stateProofKey, voteFirstDecoded, voteLastDecoded, voteKeyDilutionDecoded)
+}
+
+func newParticipationKeyInfo(address, voteKey, selectionKey, stateProofKey string, voteFirst, voteLast, voteKeyDilution uint64) (*ParticipationKeyInfo, error) {
+	info := &ParticipationKeyInfo{
+		voteFirst:       voteFirst,
+		voteLast:        voteLast,
+		voteKeyDilution: voteKeyDilution,
+	}
+	var err error
+	if address != "" {
+		info.address, err = types.DecodeAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid participation key address: %w", err)
+		}
+	}
+	if strings.TrimSpace(voteKey) == "" || strings.TrimSpace(selectionKey) == "" {
+		return nil, errors.New("participation key info must have a vote key and a selection key")
+	}
+	if err = decodeParticipationKey("vote key", voteKey, info.voteKey[:]); err != nil {
+		return nil, err
+	}
+	if err = decodeParticipationKey("selection key", selectionKey, info.selectionKey[:]); err != nil {
+		return nil, err
+	}
+	if err = decodeParticipationKey("state proof key", stateProofKey, info.stateProofKey[:]); err != nil {
+		return nil, err
+	}
+	if info.voteLast < info.voteFirst {
+		return nil, fmt.Errorf("vote last round %d is before vote first round %d", info.voteLast, info.voteFirst)
+	}
+	if info.voteLast-info.voteFirst > maxKeyregValidPeriod {
+		return nil, fmt.Errorf("participation key is valid for more than %d rounds", maxKeyregValidPeriod)
+	}
+	if info.voteKeyDilution == 0 {
+		return nil, errors.New("vote key dilution must not be 0")
+	}
+	// a batch of subkeys larger than the validity range is never used in full, which is a sign
+	// that the rounds or the dilution were mistyped
+	if rounds := info.voteLast - info.voteFirst + 1; info.voteKeyDilution > rounds {
+		return nil, fmt.Errorf("vote key dilution %d is larger than the %d rounds the key is valid for", info.voteKeyDilution, rounds)
+	}
+	return info, nil
+}
+
+// Address returns the account the participation key was generated for, or an empty string if the
+// info does not say.
+func (p *ParticipationKeyInfo) Address() string {
+	if p.address.IsZero() {
+		return ""
+	}
+	return p.address.String()
+}
+
+// VoteKey returns the base64 encoded vote key.
+func (p *ParticipationKeyInfo) VoteKey() string {
+	return base64.StdEncoding.EncodeToString(p.voteKey[:])
+}
+
+// SelectionKey returns the base64 encoded selection key.
+func (p *ParticipationKeyInfo) SelectionKey() string {
+	return base64.StdEncoding.EncodeToString(p.selectionKey[:])
+}
+
+// StateProofKey returns the base64 encoded state proof key, or an empty string if there is none.
+func (p *ParticipationKeyInfo) StateProofKey() string {
+	if p.stateProofKey == [64]byte{} {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(p.stateProofKey[:])
+}
+
+// VoteFirst returns the first round the participation key is valid for.
+func (p *ParticipationKeyInfo) VoteFirst() *Uint64 {
+	voteFirst := MakeUint64(p.voteFirst)
+	return &voteFirst
+}
+
+// VoteLast returns the last round the participation key is valid for.
+func (p *ParticipationKeyInfo) VoteLast() *Uint64 {
+	voteLast := MakeUint64(p.voteLast)
+	return &voteLast
+}
+
+// VoteKeyDilution returns the dilution of the participation key.
+func (p *ParticipationKeyInfo) VoteKeyDilution() *Uint64 {
+	dilution := MakeUint64(p.voteKeyDilution)
+	return &dilution
+}
+
+// MakeOnlineKeyRegTxn constructs the key registration transaction which registers the
+// participation key info online for account.
+//
+// The transaction is rejected by the network unless the validity range of the key overlaps the
+// transaction's, so an error is returned if the key expires before the first valid round of params
+// or only becomes valid after the last one. If incentiveEligible is true, the fee is raised to the
+// 2 Algo which makes the account eligible for staking rewards.
+func MakeOnlineKeyRegTxn(account string, info *ParticipationKeyInfo, params *SuggestedParams, incentiveEligible bool, options *TransactionOptions) ([]byte, error) {
+	if info == nil {
+		return nil, errors.New("participation key info must not be nil")
+	}
+	if address := info.Address(); address != "" && address != account {
+		return nil, fmt.Errorf("participation key was generated for %s, not %s", address, account)
+	}
+	paramsConverted, err := convertSuggestedParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert suggested params: %v", err)
+	}
+	if info.voteLast <= uint64(paramsConverted.FirstRoundValid) {
+		return nil, fmt.Errorf("participation key expires at round %d, not after the first valid round %d", info.voteLast, paramsConverted.FirstRoundValid)
+	}
+	if info.voteFirst > uint64(paramsConverted.LastRoundValid)+1 {
+		return nil, fmt.Errorf("participation key is valid from round %d, after the last valid round %d", info.voteFirst, paramsConverted.LastRoundValid)
+	}
+
+	tx, err := transaction.MakeKeyRegTxnWithStateProofKey(
+		account,
+		nil,
+		paramsConverted,
+		info.VoteKey(),
+		info.SelectionKey(),
+		info.StateProofKey(),
+		info.voteFirst,
+		info.voteLast,
+		info.voteKeyDilution,
+		false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct key reg txn: %v", err)
+	}
+	err = applyTransactionOptions(&tx, options, paramsConverted)
+	if err != nil {
+		return nil, err
+	}
+	if incentiveEligible && tx.Fee < incentiveEligibilityFee {
+		tx.Fee = incentiveEligibilityFee
+	}
+	return msgpack.Encode(&tx), nil
+}
+
+func makeEmptyKeyRegTxn(account string, params *SuggestedParams, nonpart bool, options *TransactionOptions) ([]byte, error) {
+	paramsConverted, err := convertSuggestedParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert suggested params: %v", err)
+	}
+	tx, err := transaction.MakeKeyRegTxnWithStateProofKey(account, nil, paramsConverted, "", "", "", 0, 0, 0, nonpart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct key reg txn: %v", err)
+	}
+	err = applyTransactionOptions(&tx, options, paramsConverted)
+	if err != nil {
+		return nil, err
+	}
+	return msgpack.Encode(&tx), nil
+}
+
+// MakeOfflineKeyRegTxn constructs the key registration transaction which takes account offline.
+// The account can register a participation key online again later.
+func MakeOfflineKeyRegTxn(account string, params *SuggestedParams, options *TransactionOptions) ([]byte, error) {
+	return makeEmptyKeyRegTxn(account, params, false, options)
+}
+
+// MakeNonParticipatingKeyRegTxn constructs the key registration transaction which marks account as
+// non-participating. This is permanent: the account can never go online again, and is excluded
+// from rewards.
+func MakeNonParticipatingKeyRegTxn(account string, params *SuggestedParams, options *TransactionOptions) ([]byte, error) {
+	return makeEmptyKeyRegTxn(account, params, true, options)
+}
@@ -0,0 +1,152 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestParticipationKeyJSON(address string, voteFirst, voteLast, dilution uint64) string {
+	voteKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	selectionKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	stateProofKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 64))
+	return fmt.Sprintf(`{"address":"%s","id":"ABC","effective-first-valid":%d,"key":{"vote-participation-key":"%s",`+
+		`"selection-participation-key":"%s","state-proof-key":"%s","vote-first-valid":%d,"vote-last-valid":%d,"vote-key-dilution":%d}}`,
+		address, voteFirst, voteKey, selectionKey, stateProofKey, voteFirst, voteLast, dilution)
+}
+
+func TestParseParticipationKeyInfo(t *testing.T) {
+	t.Parallel()
+	account := types.Address{1}.String()
+	info, err := ParseParticipationKeyInfo(makeTestParticipationKeyJSON(account, 10, 3000000, 1733))
+	require.NoError(t, err)
+	require.Equal(t, account, info.Address())
+	require.Equal(t, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)), info.VoteKey())
+	require.Equal(t, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 64)), info.StateProofKey())
+	require.Equal(t, MakeUint64(10), *info.VoteFirst())
+	require.Equal(t, MakeUint64(3000000), *info.VoteLast())
+	require.Equal(t, MakeUint64(1733), *info.VoteKeyDilution())
+
+	// the participation section of an account is accepted on its own
+	info, err = ParseParticipationKeyInfo(`{"vote-participation-key":"` + info.VoteKey() + `","selection-participation-key":"` +
+		info.SelectionKey() + `","vote-first-valid":1,"vote-last-valid":100,"vote-key-dilution":10}`)
+	require.NoError(t, err)
+	require.Equal(t, "", info.Address())
+	require.Equal(t, "", info.StateProofKey())
+
+	testcases := []struct {
+		infoJSON string
+		err      string
+	}{
+		{"invalid", "could not decode participation key info"},
+		{`{"key":{"vote-first-valid":1}}`, "must have a vote key and a selection key"},
+		{`{"key":{"vote-participation-key":"AQID","selection-participation-key":"AQID"}}`, "vote key must be 32 bytes, got 3"},
+		{makeTestParticipationKeyJSON(account, 100, 10, 1), "before vote first round"},
+		{makeTestParticipationKeyJSON(account, 0, 1<<24, 1), "valid for more than"},
+		{makeTestParticipationKeyJSON(account, 0, 100, 0), "dilution must not be 0"},
+		{makeTestParticipationKeyJSON("invalid", 0, 100, 1), "invalid participation key address"},
+		{makeTestParticipationKeyJSON(account, 0, 99, 101), "dilution 101 is larger than the 100 rounds"},
+	}
+	for _, testcase := range testcases {
+		_, err := ParseParticipationKeyInfo(testcase.infoJSON)
+		require.ErrorContains(t, err, testcase.err, testcase.infoJSON)
+	}
+}
+
+func TestMakeKeyRegTxns(t *testing.T) {
+	t.Parallel()
+	account := types.Address{1}.String()
+	params := makeTestARC59Params()
+	params.Fee = 1000
+	params.FlatFee = true
+	info, err := ParseParticipationKeyInfo(makeTestParticipationKeyJSON(account, 10, 3000000, 1733))
+	require.NoError(t, err)
+
+	// the online registration matches MakeKeyRegTxnWithStateProofKey
+	encoded, err := MakeOnlineKeyRegTxn(account, info, params, false, nil)
+	require.NoError(t, err)
+	expected, err := MakeKeyRegTxnWithStateProofKey(account, nil, params, info.VoteKey(), info.SelectionKey(), info.StateProofKey(),
+		info.VoteFirst(), info.VoteLast(), info.VoteKeyDilution(), false, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	encoded, err = MakeOnlineKeyRegTxn(account, info, params, true, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(2000000), decodeTestTxn(t, encoded).Fee)
+	params.Fee = 3000000
+	encoded, err = MakeOnlineKeyRegTxn(account, info, params, true, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(3000000), decodeTestTxn(t, encoded).Fee)
+
+	_, err = MakeOnlineKeyRegTxn(types.Address{2}.String(), info, params, false, nil)
+	require.ErrorContains(t, err, "participation key was generated for")
+	expired, err := ParseParticipationKeyInfo(makeTestParticipationKeyJSON(account, 0, 2, 1))
+	require.NoError(t, err)
+	_, err = MakeOnlineKeyRegTxn(account, expired, params, false, nil)
+	require.ErrorContains(t, err, "expires at round 2")
+	future, err := ParseParticipationKeyInfo(makeTestParticipationKeyJSON(account, 1004, 2000, 1))
+	require.NoError(t, err)
+	_, err = MakeOnlineKeyRegTxn(account, future, params, false, nil)
+	require.ErrorContains(t, err, "valid from round 1004")
+
+	encoded, err = MakeOfflineKeyRegTxn(account, params, nil)
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, types.KeyRegistrationTx, txn.Type)
+	require.Equal(t, types.KeyregTxnFields{}, txn.KeyregTxnFields)
+
+	encoded, err = MakeNonParticipatingKeyRegTxn(account, params, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.KeyregTxnFields{Nonparticipation: true}, decodeTestTxn(t, encoded).KeyregTxnFields)
+}
+
+func TestNewParticipationKeyInfo(t *testing.T) {
+	t.Parallel()
+	voteKey := bytes.Repeat([]byte{0xfb}, 32)
+	selectionKey := bytes.Repeat([]byte{0xff}, 32)
+	stateProofKey := bytes.Repeat([]byte{0xfe}, 64)
+	voteFirst := MakeUint64(1000)
+	voteLast := MakeUint64(3000000)
+	dilution := MakeUint64(1730)
+
+	// hex, with or without a prefix, and every base64 variant decode to the same keys
+	expected, err := NewParticipationKeyInfo("", base64.StdEncoding.EncodeToString(voteKey), base64.StdEncoding.EncodeToString(selectionKey),
+		base64.StdEncoding.EncodeToString(stateProofKey), &voteFirst, &voteLast, &dilution)
+	require.NoError(t, err)
+	variants := [][3]string{
+		{hex.EncodeToString(voteKey), "0x" + hex.EncodeToString(selectionKey), " " + hex.EncodeToString(stateProofKey) + "\n"},
+		{base64.URLEncoding.EncodeToString(voteKey), base64.RawStdEncoding.EncodeToString(selectionKey), base64.RawURLEncoding.EncodeToString(stateProofKey)},
+	}
+	for _, variant := range variants {
+		info, err := NewParticipationKeyInfo("", variant[0], variant[1], variant[2], &voteFirst, &voteLast, &dilution)
+		require.NoError(t, err, variant)
+		require.Equal(t, expected, info, variant)
+	}
+
+	// the info feeds straight into MakeKeyRegTxnWithStateProofKey
+	account := types.Address{1}.String()
+	params := makeTestARC59Params()
+	params.Fee = 1000
+	params.FlatFee = true
+	encoded, err := MakeKeyRegTxnWithStateProofKey(account, nil, params, expected.VoteKey(), expected.SelectionKey(), expected.StateProofKey(),
+		expected.VoteFirst(), expected.VoteLast(), expected.VoteKeyDilution(), false, nil)
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, voteKey, txn.VotePK[:])
+	require.Equal(t, selectionKey, txn.SelectionPK[:])
+	require.Equal(t, stateProofKey, txn.StateProofPK[:])
+
+	_, err = NewParticipationKeyInfo("", hex.EncodeToString(voteKey[:31]), hex.EncodeToString(selectionKey), "", &voteFirst, &voteLast, &dilution)
+	require.ErrorContains(t, err, "vote key must be 32 bytes, got 31")
+	_, err = NewParticipationKeyInfo("", hex.EncodeToString(voteKey), hex.EncodeToString(selectionKey), hex.EncodeToString(voteKey), &voteFirst, &voteLast, &dilution)
+	require.ErrorContains(t, err, "state proof key must be 64 bytes, got 32")
+	_, err = NewParticipationKeyInfo("", "not a key!", hex.EncodeToString(selectionKey), "", &voteFirst, &voteLast, &dilution)
+	require.ErrorContains(t, err, "neither hex nor base64")
+	_, err = NewParticipationKeyInfo("", hex.EncodeToString(voteKey), hex.EncodeToString(selectionKey), "", &voteFirst, &voteLast, &Uint64{Upper: 1})
+	require.ErrorContains(t, err, "larger than")
+}
@@ -0,0 +1,299 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+type registeredSigner struct {
+	kind   string
+	signer transaction.TransactionSigner
+}
+
+// SignerRegistry knows how to sign for a set of accounts, and which account currently authorizes
+// each account that was rekeyed. Transactions are signed by the signer of their authorizing account,
+// so a rekeyed sender is signed for by the key, multisig, logic signature or external signer of the
+// account it was rekeyed to, and the signed transaction has its auth address set.
+//
+// A SignerRegistry is a TransactionSigner, and can be used as the signer of every transaction of an
+// AtomicTransactionComposer.
+type SignerRegistry struct {
+	signers   map[types.Address]registeredSigner
+	authAddrs map[types.Address]types.Address
+}
+
+// NewSignerRegistry creates an empty SignerRegistry.
+func NewSignerRegistry() *SignerRegistry {
+	return &SignerRegistry{
+		signers:   make(map[types.Address]registeredSigner),
+		authAddrs: make(map[types.Address]types.Address),
+	}
+}
+
+// AddKey adds the account of the private key sk.
+func (r *SignerRegistry) AddKey(sk []byte) error {
+	account, err := crypto.AccountFromPrivateKey(sk)
+	if err != nil {
+		return err
+	}
+	r.signers[account.Address] = registeredSigner{signerKindAddress, transaction.BasicAccountTransactionSigner{Account: account}}
+	return nil
+}
+
+// AddMultisig adds the multisig account msig with the private keys sks of its contributing
+// addresses. There must be enough private keys to meet the multisig threshold.
+func (r *SignerRegistry) AddMultisig(msig *MultisigAccount, sks *BytesArray) error {
+	signer, err := MakeMultiSigAccountTransactionSigner(msig, sks)
+	if err != nil {
+		return err
+	}
+	addr, err := msig.value.Address()
+	if err != nil {
+		return err
+	}
+	r.signers[addr] = registeredSigner{signerKindMultisig, signer.(internalToExternalSigner).internalSigner}
+	return nil
+}
+
+// AddLogicSig adds the LogicSigAccount ls. A delegated LogicSig signs for its delegating address,
+// and an escrow LogicSig for the address of its program.
+func (r *SignerRegistry) AddLogicSig(ls *LogicSigAccount) error {
+	addr, err := ls.value.Address()
+	if err != nil {
+		return err
+	}
+	r.signers[addr] = registeredSigner{signerKindLogicSig, transaction.LogicSigAccountTransactionSigner{LogicSigAccount: ls.value}}
+	return nil
+}
+
+// AddExternalSigner adds a signer, such as a hardware wallet, for the account address. The signer is
+// only asked to sign transactions authorized by address, and must set their auth address when the
+// sender is a different account, as AttachSignatureWithSigner does.
+func (r *SignerRegistry) AddExternalSigner(address string, signer TransactionSigner) error {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	if signer == nil {
+		return fmt.Errorf("signer for %s must not be nil", address)
+	}
+	r.signers[addr] = registeredSigner{signerKindExternal, externalToInternalSigner{signer}}
+	return nil
+}
+
+// SetAuthAddress records that the account address is authorized by authAddress. An empty
+// authAddress, or address itself, records that the account is not rekeyed.
+func (r *SignerRegistry) SetAuthAddress(address, authAddress string) error {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	if authAddress == "" {
+		delete(r.authAddrs, addr)
+		return nil
+	}
+	authAddr, err := types.DecodeAddress(authAddress)
+	if err != nil {
+		return fmt.Errorf("invalid auth address: %w", err)
+	}
+	r.setAuthAddr(addr, authAddr)
+	return nil
+}
+
+func (r *SignerRegistry) setAuthAddr(addr, authAddr types.Address) {
+	if authAddr.IsZero() || authAddr == addr {
+		delete(r.authAddrs, addr)
+		return
+	}
+	r.authAddrs[addr] = authAddr
+}
+
+// FetchAuthAddress reads the current auth address of the account address with reader and records
+// it as with SetAuthAddress.
+func (r *SignerRegistry) FetchAuthAddress(reader AlgodAccountReader, address string) error {
+	accountInformation, err := reader.AccountInformation(address)
+	if err != nil {
+		return err
+	}
+	var account models.Account
+	err = json.Unmarshal([]byte(accountInformation), &account)
+	if err != nil {
+		return fmt.Errorf("could not decode account information: %w", err)
+	}
+	if account.Address != address {
+		return fmt.Errorf("account information is for %s, not %s", account.Address, address)
+	}
+	return r.SetAuthAddress(address, account.AuthAddr)
+}
+
+// AuthAddress returns the account which currently authorizes the account address. It is address
+// itself unless the account was rekeyed.
+func (r *SignerRegistry) AuthAddress(address string) (string, error) {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+	if authAddr, ok := r.authAddrs[addr]; ok {
+		return authAddr.String(), nil
+	}
+	return address, nil
+}
+
+// resolve returns the authorizing address of each transaction of txGroup. A transaction which
+// rekeys its sender changes the authorizer of the sender's later transactions in the group.
+func (r *SignerRegistry) resolve(txGroup []types.Transaction) []types.Address {
+	rekeyed := make(map[types.Address]types.Address)
+	authorizers := make([]types.Address, len(txGroup))
+	for i, tx := range txGroup {
+		authAddr, ok := rekeyed[tx.Sender]
+		if !ok {
+			authAddr, ok = r.authAddrs[tx.Sender]
+		}
+		if !ok {
+			authAddr = tx.Sender
+		}
+		authorizers[i] = authAddr
+		if !tx.RekeyTo.IsZero() {
+			rekeyed[tx.Sender] = tx.RekeyTo
+		}
+	}
+	return authorizers
+}
+
+type resolvedSigner struct {
+	Index    int    `json:"index"`
+	Sender   string `json:"sender"`
+	AuthAddr string `json:"authAddr"`
+	Kind     string `json:"kind"`
+	CanSign  bool   `json:"canSign"`
+}
+
+// ResolveSigners reports who must sign each transaction of the encoded transaction group txGroup,
+// as a JSON array:
+//
+//	[{"index":0,"sender":"...","authAddr":"...","kind":"address","canSign":true},
+//	 {"index":1,"sender":"...","authAddr":"...","kind":"none","canSign":false}]
+//
+// "authAddr" is the account authorizing the transaction, taking rekeys of earlier transactions in
+// the group into account. "kind" is the kind of signer added for it: "address", "multisig",
+// "logicsig", "external", or "none" if the registry cannot sign for it. "canSign" is true if the
+// transaction can be signed without an external signer.
+func (r *SignerRegistry) ResolveSigners(txGroup *BytesArray) (string, error) {
+	txns, err := decodeTxns(txGroup)
+	if err != nil {
+		return "", err
+	}
+	resolved := make([]resolvedSigner, len(txns))
+	for i, authAddr := range r.resolve(txns) {
+		kind := signerKindNone
+		if signer, ok := r.signers[authAddr]; ok {
+			kind = signer.kind
+		}
+		resolved[i] = resolvedSigner{
+			Index:    i,
+			Sender:   txns[i].Sender.String(),
+			AuthAddr: authAddr.String(),
+			Kind:     kind,
+			CanSign:  kind != signerKindNone && kind != signerKindExternal,
+		}
+	}
+	encoded, err := json.Marshal(resolved)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// sign signs the transactions of txGroup at indexesToSign, grouping them by signer so each signer
+// is called once. If skipMissing is true, transactions which cannot be signed locally are left
+// empty instead of causing an error.
+func (r *SignerRegistry) sign(txGroup []types.Transaction, indexesToSign []int, skipMissing bool) ([][]byte, error) {
+	authorizers := r.resolve(txGroup)
+	positions := make(map[types.Address][]int)
+	var order []types.Address
+	for i, index := range indexesToSign {
+		if index < 0 || index >= len(txGroup) {
+			return nil, fmt.Errorf("index %d is out of range for a group of %d transactions", index, len(txGroup))
+		}
+		authAddr := authorizers[index]
+		signer, ok := r.signers[authAddr]
+		if skipMissing && (!ok || signer.kind == signerKindExternal) {
+			continue
+		}
+		if !ok {
+			return nil, fmt.Errorf("no signer for %s, which authorizes transaction %d", authAddr, index)
+		}
+		if _, seen := positions[authAddr]; !seen {
+			order = append(order, authAddr)
+		}
+		positions[authAddr] = append(positions[authAddr], i)
+	}
+
+	stxs := make([][]byte, len(indexesToSign))
+	for _, authAddr := range order {
+		signerIndexes := make([]int, len(positions[authAddr]))
+		for i, position := range positions[authAddr] {
+			signerIndexes[i] = indexesToSign[position]
+		}
+		signed, err := r.signers[authAddr].signer.SignTransactions(txGroup, signerIndexes)
+		if err != nil {
+			return nil, err
+		}
+		if len(signed) != len(signerIndexes) {
+			return nil, fmt.Errorf("signer for %s returned %d transactions, expected %d", authAddr, len(signed), len(signerIndexes))
+		}
+		for i, position := range positions[authAddr] {
+			stxs[position] = signed[i]
+		}
+	}
+	return stxs, nil
+}
+
+// SignTransactions signs the transactions in txGroup at the indexes specified in indexesToSign
+// with the signers of their authorizing accounts. An error is returned if the registry has no
+// signer for one of them.
+func (r *SignerRegistry) SignTransactions(txGroup *BytesArray, indexesToSign *Int64Array) (*BytesArray, error) {
+	txns, err := decodeTxns(txGroup)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, indexesToSign.Length())
+	for i, index := range indexesToSign.Extract() {
+		indexes[i] = int(index)
+	}
+	stxs, err := r.sign(txns, indexes, false)
+	if err != nil {
+		return nil, err
+	}
+	return &BytesArray{stxs}, nil
+}
+
+// SignLocally signs every transaction of txGroup which can be signed without an external signer.
+// The returned array has an entry for each transaction of the group, which is empty for the
+// transactions left unsigned. See ResolveSigners for who must sign them.
+func (r *SignerRegistry) SignLocally(txGroup *BytesArray) (*BytesArray, error) {
+	txns, err := decodeTxns(txGroup)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(txns))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	stxs, err := r.sign(txns, indexes, true)
+	if err != nil {
+		return nil, err
+	}
+	return &BytesArray{stxs}, nil
+}
+
+// Equals returns true if other is the same SignerRegistry.
+func (r *SignerRegistry) Equals(other TransactionSigner) bool {
+	casted, ok := other.(*SignerRegistry)
+	return ok && casted == r
+}
@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testCountingSigner signs with a key and records the indexes it was asked to sign.
+type testCountingSigner struct {
+	TransactionSigner
+	calls [][]int64
+}
+
+func (s *testCountingSigner) SignTransactions(txGroup *BytesArray, indexesToSign *Int64Array) (*BytesArray, error) {
+	s.calls = append(s.calls, indexesToSign.Extract())
+	return s.TransactionSigner.SignTransactions(txGroup, indexesToSign)
+}
+
+func decodeTestSignedTxn(t *testing.T, encoded []byte) types.SignedTxn {
+	t.Helper()
+	var stx types.SignedTxn
+	require.NoError(t, msgpack.Decode(encoded, &stx))
+	return stx
+}
+
+func TestSignerRegistry(t *testing.T) {
+	t.Parallel()
+	alice := crypto.GenerateAccount()
+	bob := crypto.GenerateAccount()
+	carol := crypto.GenerateAccount()
+	dave := crypto.GenerateAccount()
+	carolSigner, err := MakeBasicAccountSigner(carol.PrivateKey)
+	require.NoError(t, err)
+	external := &testCountingSigner{TransactionSigner: carolSigner}
+
+	registry := NewSignerRegistry()
+	require.NoError(t, registry.AddKey(alice.PrivateKey))
+	require.NoError(t, registry.AddExternalSigner(carol.Address.String(), external))
+	require.NoError(t, registry.SetAuthAddress(bob.Address.String(), alice.Address.String()))
+	authAddr, err := registry.AuthAddress(bob.Address.String())
+	require.NoError(t, err)
+	require.Equal(t, alice.Address.String(), authAddr)
+
+	// bob is signed for by alice, and carol is rekeyed to dave within the group
+	rekeyTxn := decodeTestTxn(t, makeTestComposerPayment(t, carol.Address, carol.Address, 0))
+	rekeyTxn.RekeyTo = dave.Address
+	group := &BytesArray{values: [][]byte{
+		makeTestComposerPayment(t, alice.Address, bob.Address, 1),
+		makeTestComposerPayment(t, bob.Address, alice.Address, 2),
+		msgpack.Encode(&rekeyTxn),
+		makeTestComposerPayment(t, carol.Address, alice.Address, 3),
+	}}
+
+	resolvedJSON, err := registry.ResolveSigners(group)
+	require.NoError(t, err)
+	var resolved []resolvedSigner
+	require.NoError(t, json.Unmarshal([]byte(resolvedJSON), &resolved))
+	require.Equal(t, []resolvedSigner{
+		{0, alice.Address.String(), alice.Address.String(), "address", true},
+		{1, bob.Address.String(), alice.Address.String(), "address", true},
+		{2, carol.Address.String(), carol.Address.String(), "external", false},
+		{3, carol.Address.String(), dave.Address.String(), "none", false},
+	}, resolved)
+
+	signed, err := registry.SignLocally(group)
+	require.NoError(t, err)
+	require.Equal(t, 4, signed.Length())
+	require.True(t, decodeTestSignedTxn(t, signed.Get(0)).AuthAddr.IsZero())
+	require.Equal(t, alice.Address, decodeTestSignedTxn(t, signed.Get(1)).AuthAddr)
+	require.Empty(t, signed.Get(2))
+	require.Empty(t, signed.Get(3))
+	require.Empty(t, external.calls)
+
+	signed, err = registry.SignTransactions(group, &Int64Array{values: []int64{2, 1}})
+	require.NoError(t, err)
+	require.Equal(t, carol.Address, decodeTestSignedTxn(t, signed.Get(0)).Txn.Sender)
+	require.Equal(t, bob.Address, decodeTestSignedTxn(t, signed.Get(1)).Txn.Sender)
+	require.Equal(t, [][]int64{{2}}, external.calls)
+
+	_, err = registry.SignTransactions(group, &Int64Array{values: []int64{3}})
+	require.ErrorContains(t, err, fmt.Sprintf("no signer for %s", dave.Address))
+	_, err = registry.SignTransactions(group, &Int64Array{values: []int64{4}})
+	require.ErrorContains(t, err, "out of range")
+
+	// the registry signs every transaction of a composer
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddTransaction(group.Get(0), registry))
+	require.NoError(t, atc.AddTransaction(group.Get(1), registry))
+	stxs, err := atc.GatherSignatures()
+	require.NoError(t, err)
+	require.Equal(t, alice.Address, decodeTestSignedTxn(t, stxs.Get(1)).AuthAddr)
+	require.True(t, registry.Equals(registry))
+	require.False(t, registry.Equals(NewSignerRegistry()))
+}
+
+func TestSignerRegistryMultisigAndLogicSig(t *testing.T) {
+	t.Parallel()
+	alice := crypto.GenerateAccount()
+	bob := crypto.GenerateAccount()
+	sender := crypto.GenerateAccount()
+	msig, err := MakeMultisigAccount(1, 1, &StringArray{values: []string{alice.Address.String(), bob.Address.String()}})
+	require.NoError(t, err)
+	msigAddr, err := msig.Address()
+	require.NoError(t, err)
+	lsig, err := MakeLogicSigAccountEscrow([]byte{0x1, 0x20, 0x1, 0x1, 0x22}, nil)
+	require.NoError(t, err)
+	lsigAddr, err := lsig.Address()
+	require.NoError(t, err)
+
+	registry := NewSignerRegistry()
+	require.Error(t, registry.AddMultisig(msig, &BytesArray{}))
+	require.NoError(t, registry.AddMultisig(msig, &BytesArray{values: [][]byte{alice.PrivateKey}}))
+	require.NoError(t, registry.AddLogicSig(lsig))
+
+	reader := testAccountReader{
+		sender.Address.String(): fmt.Sprintf(`{"address":"%s","amount":0,"auth-addr":"%s"}`, sender.Address, msigAddr),
+		lsigAddr:                fmt.Sprintf(`{"address":"%s","amount":0}`, sender.Address),
+	}
+	require.NoError(t, registry.FetchAuthAddress(reader, sender.Address.String()))
+	require.ErrorContains(t, registry.FetchAuthAddress(reader, lsigAddr), "account information is for")
+
+	signed, err := registry.SignLocally(&BytesArray{values: [][]byte{
+		makeTestComposerPayment(t, sender.Address, alice.Address, 1),
+		makeTestComposerPayment(t, mustDecodeAddress(t, lsigAddr), alice.Address, 2),
+	}})
+	require.NoError(t, err)
+	stx := decodeTestSignedTxn(t, signed.Get(0))
+	require.Equal(t, msigAddr, stx.AuthAddr.String())
+	require.False(t, stx.Msig.Blank())
+	stx = decodeTestSignedTxn(t, signed.Get(1))
+	require.True(t, stx.AuthAddr.IsZero())
+	require.False(t, stx.Lsig.Blank())
+
+	// clearing the auth address leaves the sender without a signer
+	require.NoError(t, registry.SetAuthAddress(sender.Address.String(), ""))
+	authAddr, err := registry.AuthAddress(sender.Address.String())
+	require.NoError(t, err)
+	require.Equal(t, sender.Address.String(), authAddr)
+}
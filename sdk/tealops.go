@@ -0,0 +1,324 @@
+package sdk
+
+// This file describes the opcodes of the AVM, and their immediate arguments, as needed to
+// disassemble TEAL programs. It follows the opcode specification of go-algorand.
+
+// maxTEALVersion is the highest TEAL version which can be disassembled.
+const maxTEALVersion = 12
+
+// Kinds of immediate arguments of TEAL opcodes
+const (
+	// immUint8 is a single unsigned byte
+	immUint8 = iota
+	// immInt8 is a single signed byte
+	immInt8
+	// immField is a single byte naming a field of the immediate's field group
+	immField
+	// immLabel is a signed 16-bit offset to a branch target
+	immLabel
+	// immLabels is a byte count followed by that many signed 16-bit offsets
+	immLabels
+	// immVarUint is an unsigned varint
+	immVarUint
+	// immBytes is a varint length followed by that many bytes
+	immBytes
+	// immVarUints is a varint count followed by that many unsigned varints
+	immVarUints
+	// immBytesList is a varint count followed by that many length-prefixed byte strings
+	immBytesList
+)
+
+type tealImmediate struct {
+	kind   int
+	fields []string
+}
+
+type tealOpSpec struct {
+	opcode     byte
+	name       string
+	version    uint64
+	immediates []tealImmediate
+}
+
+var (
+	txnFieldNames = []string{
+		"Sender", "Fee", "FirstValid", "FirstValidTime", "LastValid", "Note", "Lease", "Receiver",
+		"Amount", "CloseRemainderTo", "VotePK", "SelectionPK", "VoteFirst", "VoteLast",
+		"VoteKeyDilution", "Type", "TypeEnum", "XferAsset", "AssetAmount", "AssetSender",
+		"AssetReceiver", "AssetCloseTo", "GroupIndex", "TxID", "ApplicationID", "OnCompletion",
+		"ApplicationArgs", "NumAppArgs", "Accounts", "NumAccounts", "ApprovalProgram",
+		"ClearStateProgram", "RekeyTo", "ConfigAsset", "ConfigAssetTotal", "ConfigAssetDecimals",
+		"ConfigAssetDefaultFrozen", "ConfigAssetUnitName", "ConfigAssetName", "ConfigAssetURL",
+		"ConfigAssetMetadataHash", "ConfigAssetManager", "ConfigAssetReserve", "ConfigAssetFreeze",
+		"ConfigAssetClawback", "FreezeAsset", "FreezeAssetAccount", "FreezeAssetFrozen", "Assets",
+		"NumAssets", "Applications", "NumApplications", "GlobalNumUint", "GlobalNumByteSlice",
+		"LocalNumUint", "LocalNumByteSlice", "ExtraProgramPages", "Nonparticipation", "Logs",
+		"NumLogs", "CreatedAssetID", "CreatedApplicationID", "LastLog", "StateProofPK",
+		"ApprovalProgramPages", "NumApprovalProgramPages", "ClearStateProgramPages",
+		"NumClearStateProgramPages", "RejectVersion",
+	}
+	globalFieldNames = []string{
+		"MinTxnFee", "MinBalance", "MaxTxnLife", "ZeroAddress", "GroupSize", "LogicSigVersion",
+		"Round", "LatestTimestamp", "CurrentApplicationID", "CreatorAddress",
+		"CurrentApplicationAddress", "GroupID", "OpcodeBudget", "CallerApplicationID",
+		"CallerApplicationAddress", "AssetCreateMinBalance", "AssetOptInMinBalance", "GenesisHash",
+		"PayoutsEnabled", "PayoutsGoOnlineFee", "PayoutsPercent", "PayoutsMinBalance",
+		"PayoutsMaxBalance",
+	}
+	assetHoldingFieldNames = []string{"AssetBalance", "AssetFrozen"}
+	assetParamsFieldNames  = []string{
+		"AssetTotal", "AssetDecimals", "AssetDefaultFrozen", "AssetUnitName", "AssetName", "AssetURL",
+		"AssetMetadataHash", "AssetManager", "AssetReserve", "AssetFreeze", "AssetClawback",
+		"AssetCreator",
+	}
+	appParamsFieldNames = []string{
+		"AppApprovalProgram", "AppClearStateProgram", "AppGlobalNumUint", "AppGlobalNumByteSlice",
+		"AppLocalNumUint", "AppLocalNumByteSlice", "AppExtraProgramPages", "AppCreator", "AppAddress",
+		"AppVersion",
+	}
+	acctParamsFieldNames = []string{
+		"AcctBalance", "AcctMinBalance", "AcctAuthAddr", "AcctTotalNumUint", "AcctTotalNumByteSlice",
+		"AcctTotalExtraAppPages", "AcctTotalAppsCreated", "AcctTotalAppsOptedIn",
+		"AcctTotalAssetsCreated", "AcctTotalAssets", "AcctTotalBoxes", "AcctTotalBoxBytes",
+		"AcctIncentiveEligible", "AcctLastProposed", "AcctLastHeartbeat",
+	}
+	voterParamsFieldNames = []string{"VoterBalance", "VoterIncentiveEligible"}
+	blockFieldNames       = []string{
+		"BlkSeed", "BlkTimestamp", "BlkProposer", "BlkFeesCollected", "BlkBonus", "BlkBranch",
+		"BlkFeeSink", "BlkProtocol", "BlkTxnCounter", "BlkProposerPayout",
+	}
+	ecdsaCurveNames     = []string{"Secp256k1", "Secp256r1"}
+	base64EncodingNames = []string{"URLEncoding", "StdEncoding"}
+	jsonRefTypeNames    = []string{"JSONString", "JSONUint64", "JSONObject"}
+	vrfStandardNames    = []string{"VrfAlgorand"}
+	ecGroupNames        = []string{"BN254g1", "BN254g2", "BLS12_381g1", "BLS12_381g2"}
+	mimcConfigNames     = []string{"BN254Mp110", "BLS12_381Mp111"}
+)
+
+func field(names []string) tealImmediate {
+	return tealImmediate{kind: immField, fields: names}
+}
+
+var (
+	uint8Imm     = tealImmediate{kind: immUint8}
+	int8Imm      = tealImmediate{kind: immInt8}
+	labelImm     = tealImmediate{kind: immLabel}
+	labelsImm    = tealImmediate{kind: immLabels}
+	varUintImm   = tealImmediate{kind: immVarUint}
+	bytesImm     = tealImmediate{kind: immBytes}
+	varUintsImm  = tealImmediate{kind: immVarUints}
+	bytesListImm = tealImmediate{kind: immBytesList}
+)
+
+func imms(immediates ...tealImmediate) []tealImmediate {
+	return immediates
+}
+
+var tealOpSpecs = []tealOpSpec{
+	{0x00, "err", 1, nil},
+	{0x01, "sha256", 1, nil},
+	{0x02, "keccak256", 1, nil},
+	{0x03, "sha512_256", 1, nil},
+	{0x04, "ed25519verify", 1, nil},
+	{0x05, "ecdsa_verify", 5, imms(field(ecdsaCurveNames))},
+	{0x06, "ecdsa_pk_decompress", 5, imms(field(ecdsaCurveNames))},
+	{0x07, "ecdsa_pk_recover", 5, imms(field(ecdsaCurveNames))},
+	{0x08, "+", 1, nil},
+	{0x09, "-", 1, nil},
+	{0x0a, "/", 1, nil},
+	{0x0b, "*", 1, nil},
+	{0x0c, "<", 1, nil},
+	{0x0d, ">", 1, nil},
+	{0x0e, "<=", 1, nil},
+	{0x0f, ">=", 1, nil},
+	{0x10, "&&", 1, nil},
+	{0x11, "||", 1, nil},
+	{0x12, "==", 1, nil},
+	{0x13, "!=", 1, nil},
+	{0x14, "!", 1, nil},
+	{0x15, "len", 1, nil},
+	{0x16, "itob", 1, nil},
+	{0x17, "btoi", 1, nil},
+	{0x18, "%", 1, nil},
+	{0x19, "|", 1, nil},
+	{0x1a, "&", 1, nil},
+	{0x1b, "^", 1, nil},
+	{0x1c, "~", 1, nil},
+	{0x1d, "mulw", 1, nil},
+	{0x1e, "addw", 2, nil},
+	{0x1f, "divmodw", 4, nil},
+
+	{0x20, "intcblock", 1, imms(varUintsImm)},
+	{0x21, "intc", 1, imms(uint8Imm)},
+	{0x22, "intc_0", 1, nil},
+	{0x23, "intc_1", 1, nil},
+	{0x24, "intc_2", 1, nil},
+	{0x25, "intc_3", 1, nil},
+	{0x26, "bytecblock", 1, imms(bytesListImm)},
+	{0x27, "bytec", 1, imms(uint8Imm)},
+	{0x28, "bytec_0", 1, nil},
+	{0x29, "bytec_1", 1, nil},
+	{0x2a, "bytec_2", 1, nil},
+	{0x2b, "bytec_3", 1, nil},
+	{0x2c, "arg", 1, imms(uint8Imm)},
+	{0x2d, "arg_0", 1, nil},
+	{0x2e, "arg_1", 1, nil},
+	{0x2f, "arg_2", 1, nil},
+	{0x30, "arg_3", 1, nil},
+	{0x31, "txn", 1, imms(field(txnFieldNames))},
+	{0x32, "global", 1, imms(field(globalFieldNames))},
+	{0x33, "gtxn", 1, imms(uint8Imm, field(txnFieldNames))},
+	{0x34, "load", 1, imms(uint8Imm)},
+	{0x35, "store", 1, imms(uint8Imm)},
+	{0x36, "txna", 2, imms(field(txnFieldNames), uint8Imm)},
+	{0x37, "gtxna", 2, imms(uint8Imm, field(txnFieldNames), uint8Imm)},
+	{0x38, "gtxns", 3, imms(field(txnFieldNames))},
+	{0x39, "gtxnsa", 3, imms(field(txnFieldNames), uint8Imm)},
+	{0x3a, "gload", 4, imms(uint8Imm, uint8Imm)},
+	{0x3b, "gloads", 4, imms(uint8Imm)},
+	{0x3c, "gaid", 4, imms(uint8Imm)},
+	{0x3d, "gaids", 4, nil},
+	{0x3e, "loads", 5, nil},
+	{0x3f, "stores", 5, nil},
+
+	{0x40, "bnz", 1, imms(labelImm)},
+	{0x41, "bz", 2, imms(labelImm)},
+	{0x42, "b", 2, imms(labelImm)},
+	{0x43, "return", 2, nil},
+	{0x44, "assert", 3, nil},
+	{0x45, "bury", 8, imms(uint8Imm)},
+	{0x46, "popn", 8, imms(uint8Imm)},
+	{0x47, "dupn", 8, imms(uint8Imm)},
+	{0x48, "pop", 1, nil},
+	{0x49, "dup", 1, nil},
+	{0x4a, "dup2", 2, nil},
+	{0x4b, "dig", 3, imms(uint8Imm)},
+	{0x4c, "swap", 3, nil},
+	{0x4d, "select", 3, nil},
+	{0x4e, "cover", 5, imms(uint8Imm)},
+	{0x4f, "uncover", 5, imms(uint8Imm)},
+
+	{0x50, "concat", 2, nil},
+	{0x51, "substring", 2, imms(uint8Imm, uint8Imm)},
+	{0x52, "substring3", 2, nil},
+	{0x53, "getbit", 3, nil},
+	{0x54, "setbit", 3, nil},
+	{0x55, "getbyte", 3, nil},
+	{0x56, "setbyte", 3, nil},
+	{0x57, "extract", 5, imms(uint8Imm, uint8Imm)},
+	{0x58, "extract3", 5, nil},
+	{0x59, "extract_uint16", 5, nil},
+	{0x5a, "extract_uint32", 5, nil},
+	{0x5b, "extract_uint64", 5, nil},
+	{0x5c, "replace2", 7, imms(uint8Imm)},
+	{0x5d, "replace3", 7, nil},
+	{0x5e, "base64_decode", 7, imms(field(base64EncodingNames))},
+	{0x5f, "json_ref", 7, imms(field(jsonRefTypeNames))},
+
+	{0x60, "balance", 2, nil},
+	{0x61, "app_opted_in", 2, nil},
+	{0x62, "app_local_get", 2, nil},
+	{0x63, "app_local_get_ex", 2, nil},
+	{0x64, "app_global_get", 2, nil},
+	{0x65, "app_global_get_ex", 2, nil},
+	{0x66, "app_local_put", 2, nil},
+	{0x67, "app_global_put", 2, nil},
+	{0x68, "app_local_del", 2, nil},
+	{0x69, "app_global_del", 2, nil},
+	{0x70, "asset_holding_get", 2, imms(field(assetHoldingFieldNames))},
+	{0x71, "asset_params_get", 2, imms(field(assetParamsFieldNames))},
+	{0x72, "app_params_get", 5, imms(field(appParamsFieldNames))},
+	{0x73, "acct_params_get", 6, imms(field(acctParamsFieldNames))},
+	{0x74, "voter_params_get", 11, imms(field(voterParamsFieldNames))},
+	{0x75, "online_stake", 11, nil},
+	{0x78, "min_balance", 3, nil},
+
+	{0x80, "pushbytes", 3, imms(bytesImm)},
+	{0x81, "pushint", 3, imms(varUintImm)},
+	{0x82, "pushbytess", 8, imms(bytesListImm)},
+	{0x83, "pushints", 8, imms(varUintsImm)},
+	{0x84, "ed25519verify_bare", 7, nil},
+	{0x85, "falcon_verify", 12, nil},
+	{0x86, "sumhash512", 12, nil},
+	{0x88, "callsub", 4, imms(labelImm)},
+	{0x89, "retsub", 4, nil},
+	{0x8a, "proto", 8, imms(uint8Imm, uint8Imm)},
+	{0x8b, "frame_dig", 8, imms(int8Imm)},
+	{0x8c, "frame_bury", 8, imms(int8Imm)},
+	{0x8d, "switch", 8, imms(labelsImm)},
+	{0x8e, "match", 8, imms(labelsImm)},
+
+	{0x90, "shl", 4, nil},
+	{0x91, "shr", 4, nil},
+	{0x92, "sqrt", 4, nil},
+	{0x93, "bitlen", 4, nil},
+	{0x94, "exp", 4, nil},
+	{0x95, "expw", 4, nil},
+	{0x96, "bsqrt", 6, nil},
+	{0x97, "divw", 6, nil},
+	{0x98, "sha3_256", 7, nil},
+
+	{0xa0, "b+", 4, nil},
+	{0xa1, "b-", 4, nil},
+	{0xa2, "b/", 4, nil},
+	{0xa3, "b*", 4, nil},
+	{0xa4, "b<", 4, nil},
+	{0xa5, "b>", 4, nil},
+	{0xa6, "b<=", 4, nil},
+	{0xa7, "b>=", 4, nil},
+	{0xa8, "b==", 4, nil},
+	{0xa9, "b!=", 4, nil},
+	{0xaa, "b%", 4, nil},
+	{0xab, "b|", 4, nil},
+	{0xac, "b&", 4, nil},
+	{0xad, "b^", 4, nil},
+	{0xae, "b~", 4, nil},
+	{0xaf, "bzero", 4, nil},
+
+	{0xb0, "log", 5, nil},
+	{0xb1, "itxn_begin", 5, nil},
+	{0xb2, "itxn_field", 5, imms(field(txnFieldNames))},
+	{0xb3, "itxn_submit", 5, nil},
+	{0xb4, "itxn", 5, imms(field(txnFieldNames))},
+	{0xb5, "itxna", 5, imms(field(txnFieldNames), uint8Imm)},
+	{0xb6, "itxn_next", 6, nil},
+	{0xb7, "gitxn", 6, imms(uint8Imm, field(txnFieldNames))},
+	{0xb8, "gitxna", 6, imms(uint8Imm, field(txnFieldNames), uint8Imm)},
+	{0xb9, "box_create", 8, nil},
+	{0xba, "box_extract", 8, nil},
+	{0xbb, "box_replace", 8, nil},
+	{0xbc, "box_del", 8, nil},
+	{0xbd, "box_len", 8, nil},
+	{0xbe, "box_get", 8, nil},
+	{0xbf, "box_put", 8, nil},
+
+	{0xc0, "txnas", 5, imms(field(txnFieldNames))},
+	{0xc1, "gtxnas", 5, imms(uint8Imm, field(txnFieldNames))},
+	{0xc2, "gtxnsas", 5, imms(field(txnFieldNames))},
+	{0xc3, "args", 5, nil},
+	{0xc4, "gloadss", 6, nil},
+	{0xc5, "itxnas", 6, imms(field(txnFieldNames))},
+	{0xc6, "gitxnas", 6, imms(uint8Imm, field(txnFieldNames))},
+
+	{0xd0, "vrf_verify", 7, imms(field(vrfStandardNames))},
+	{0xd1, "block", 7, imms(field(blockFieldNames))},
+	{0xd2, "box_splice", 10, nil},
+	{0xd3, "box_resize", 10, nil},
+
+	{0xe0, "ec_add", 10, imms(field(ecGroupNames))},
+	{0xe1, "ec_scalar_mul", 10, imms(field(ecGroupNames))},
+	{0xe2, "ec_pairing_check", 10, imms(field(ecGroupNames))},
+	{0xe3, "ec_multi_scalar_mul", 10, imms(field(ecGroupNames))},
+	{0xe4, "ec_subgroup_check", 10, imms(field(ecGroupNames))},
+	{0xe5, "ec_map_to", 10, imms(field(ecGroupNames))},
+	{0xe6, "mimc", 11, imms(field(mimcConfigNames))},
+}
+
+// tealOpsByCode indexes tealOpSpecs by opcode.
+var tealOpsByCode = func() [256]*tealOpSpec {
+	var ops [256]*tealOpSpec
+	for i := range tealOpSpecs {
+		ops[tealOpSpecs[i].opcode] = &tealOpSpecs[i]
+	}
+	return ops
+}()
@@ -51,7 +51,7 @@ func convertSuggestedParams(params *SuggestedParams) (internalParams types.Sugge
 
 // MakePaymentTxn constructs a payment transaction using the passed parameters.
 // `from` and `to` addresses should be checksummed, human-readable addresses
-func MakePaymentTxn(from, to string, amount *Uint64, note []byte, closeRemainderTo string, params *SuggestedParams) (encoded []byte, err error) {
+func MakePaymentTxn(from, to string, amount *Uint64, note []byte, closeRemainderTo string, params *SuggestedParams, options *TransactionOptions) (encoded []byte, err error) {
 	internalAmount, err := amount.Extract()
 	if err != nil {
 		err = fmt.Errorf("Could not decode transaction amount: %v", err)
@@ -64,6 +64,9 @@ func MakePaymentTxn(from, to string, amount *Uint64, note []byte, closeRemainder
 	}
 
 	tx, err := transaction.MakePaymentTxn(from, to, internalAmount, note, closeRemainderTo, internalParams)
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -72,13 +75,17 @@ func MakePaymentTxn(from, to string, amount *Uint64, note []byte, closeRemainder
 }
 
 // MakeRekeyTxn constructs a rekey transaction using the passed parameters.
-func MakeRekeyTxn(from, rekeyTo string, params *SuggestedParams) (encoded []byte, err error) {
+// rekeyTo replaces the rekey address of options.
+func MakeRekeyTxn(from, rekeyTo string, params *SuggestedParams, options *TransactionOptions) (encoded []byte, err error) {
 	internalParams, err := convertSuggestedParams(params)
 	if err != nil {
 		return
 	}
 
 	tx, err := transaction.MakePaymentTxn(from, from, 0, nil, "", internalParams)
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	tx.Rekey(rekeyTo)
 	if err == nil {
 		encoded = msgpack.Encode(tx)
@@ -90,7 +97,7 @@ func MakeRekeyTxn(from, rekeyTo string, params *SuggestedParams) (encoded []byte
 // MakeAssetCreateTxn constructs an asset creation transaction using the passed parameters.
 // - account is a checksummed, human-readable address which will send the transaction.
 // - note is a byte array
-func MakeAssetCreateTxn(account string, note []byte, params *SuggestedParams, total *Uint64, decimals int32, defaultFrozen bool, manager, reserve, freeze, clawback, unitName, assetName, url string, metadataHash []byte) (encoded []byte, err error) {
+func MakeAssetCreateTxn(account string, note []byte, params *SuggestedParams, total *Uint64, decimals int32, defaultFrozen bool, manager, reserve, freeze, clawback, unitName, assetName, url string, metadataHash []byte, options *TransactionOptions) (encoded []byte, err error) {
 	if decimals < 0 {
 		err = errNegativeArgument
 		return
@@ -108,6 +115,9 @@ func MakeAssetCreateTxn(account string, note []byte, params *SuggestedParams, to
 	}
 
 	tx, err := transaction.MakeAssetCreateTxn(account, note, internalParams, internalTotal, uint32(decimals), defaultFrozen, manager, reserve, freeze, clawback, unitName, assetName, url, string(metadataHash))
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -120,7 +130,7 @@ func MakeAssetCreateTxn(account string, note []byte, params *SuggestedParams, to
 // cannot be changed after becoming zero); to keep a key
 // unchanged, you must specify that key.
 // - account is a checksummed, human-readable address for which we register the given participation key.
-func MakeAssetConfigTxn(account string, note []byte, params *SuggestedParams, index int64, newManager, newReserve, newFreeze, newClawback string) (encoded []byte, err error) {
+func MakeAssetConfigTxn(account string, note []byte, params *SuggestedParams, index int64, newManager, newReserve, newFreeze, newClawback string, options *TransactionOptions) (encoded []byte, err error) {
 	if index < 0 {
 		err = errNegativeArgument
 		return
@@ -132,6 +142,9 @@ func MakeAssetConfigTxn(account string, note []byte, params *SuggestedParams, in
 	}
 
 	tx, err := transaction.MakeAssetConfigTxn(account, note, internalParams, uint64(index), newManager, newReserve, newFreeze, newClawback, false)
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -148,7 +161,7 @@ func MakeAssetConfigTxn(account string, note []byte, params *SuggestedParams, in
 // - note is an arbitrary byte array
 // - creator is the address of the asset creator
 // - index is the asset index
-func MakeAssetTransferTxn(account, recipient, closeAssetsTo string, amount *Uint64, note []byte, params *SuggestedParams, index int64) (encoded []byte, err error) {
+func MakeAssetTransferTxn(account, recipient, closeAssetsTo string, amount *Uint64, note []byte, params *SuggestedParams, index int64, options *TransactionOptions) (encoded []byte, err error) {
 	if index < 0 {
 		err = errNegativeArgument
 		return
@@ -166,6 +179,9 @@ func MakeAssetTransferTxn(account, recipient, closeAssetsTo string, amount *Uint
 	}
 
 	tx, err := transaction.MakeAssetTransferTxn(account, recipient, internalAmount, note, internalParams, closeAssetsTo, uint64(index))
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -177,7 +193,7 @@ func MakeAssetTransferTxn(account, recipient, closeAssetsTo string, amount *Uint
 // - account is a checksummed, human-readable address that will send the transaction and begin accepting the asset
 // - note is an arbitrary byte array
 // - index is the asset index
-func MakeAssetAcceptanceTxn(account string, note []byte, params *SuggestedParams, index int64) (encoded []byte, err error) {
+func MakeAssetAcceptanceTxn(account string, note []byte, params *SuggestedParams, index int64, options *TransactionOptions) (encoded []byte, err error) {
 	if index < 0 {
 		err = errNegativeArgument
 		return
@@ -189,6 +205,9 @@ func MakeAssetAcceptanceTxn(account string, note []byte, params *SuggestedParams
 	}
 
 	tx, err := transaction.MakeAssetAcceptanceTxn(account, note, internalParams, uint64(index))
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -202,7 +221,7 @@ func MakeAssetAcceptanceTxn(account string, note []byte, params *SuggestedParams
 // - recipient is a checksummed, human-readable address; it will receive the revoked assets
 // - amount defines the number of assets to clawback
 // - index is the asset index
-func MakeAssetRevocationTxn(account, target string, amount *Uint64, recipient string, note []byte, params *SuggestedParams, index int64) (encoded []byte, err error) {
+func MakeAssetRevocationTxn(account, target string, amount *Uint64, recipient string, note []byte, params *SuggestedParams, index int64, options *TransactionOptions) (encoded []byte, err error) {
 	if index < 0 {
 		err = errNegativeArgument
 		return
@@ -220,6 +239,9 @@ func MakeAssetRevocationTxn(account, target string, amount *Uint64, recipient st
 	}
 
 	tx, err := transaction.MakeAssetRevocationTxn(account, target, internalAmount, recipient, note, internalParams, uint64(index))
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -231,7 +253,7 @@ func MakeAssetRevocationTxn(account, target string, amount *Uint64, recipient st
 // All outstanding asset amount must be held by the creator, and this transaction must be issued by the asset manager.
 // - account is a checksummed, human-readable address that will send the transaction; it also must be the asset manager
 // - index is the asset index
-func MakeAssetDestroyTxn(account string, note []byte, params *SuggestedParams, index int64) (encoded []byte, err error) {
+func MakeAssetDestroyTxn(account string, note []byte, params *SuggestedParams, index int64, options *TransactionOptions) (encoded []byte, err error) {
 	if index < 0 {
 		err = errNegativeArgument
 		return
@@ -243,6 +265,9 @@ func MakeAssetDestroyTxn(account string, note []byte, params *SuggestedParams, i
 	}
 
 	tx, err := transaction.MakeAssetDestroyTxn(account, note, internalParams, uint64(index))
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -257,7 +282,7 @@ func MakeAssetDestroyTxn(account string, note []byte, params *SuggestedParams, i
 // - assetIndex is the index for tracking the asset
 // - target is the account to be frozen or unfrozen
 // - newFreezeSetting is the new state of the target account
-func MakeAssetFreezeTxn(account string, note []byte, params *SuggestedParams, assetIndex int64, target string, newFreezeSetting bool) (encoded []byte, err error) {
+func MakeAssetFreezeTxn(account string, note []byte, params *SuggestedParams, assetIndex int64, target string, newFreezeSetting bool, options *TransactionOptions) (encoded []byte, err error) {
 	if assetIndex < 0 {
 		err = errNegativeArgument
 		return
@@ -269,6 +294,9 @@ func MakeAssetFreezeTxn(account string, note []byte, params *SuggestedParams, as
 	}
 
 	tx, err := transaction.MakeAssetFreezeTxn(account, note, internalParams, uint64(assetIndex), target, newFreezeSetting)
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -340,6 +368,7 @@ func MakeApplicationCreateTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if globalSchemaUint < 0 || globalSchemaByteSlice < 0 || localSchemaUint < 0 || localSchemaByteSlice < 0 || extraPages < 0 {
 		err = errNegativeArgument
@@ -388,6 +417,9 @@ func MakeApplicationCreateTx(
 	}
 
 	tx, err := transaction.MakeApplicationCreateTxWithBoxes(optIn, approvalProg, clearProg, globalSchema, localSchema, uint32(extraPages), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -408,6 +440,7 @@ func MakeApplicationUpdateTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if appIdx < 0 {
 		err = errNegativeArgument
@@ -446,6 +479,9 @@ func MakeApplicationUpdateTx(
 	}
 
 	tx, err := transaction.MakeApplicationUpdateTxWithBoxes(uint64(appIdx), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), approvalProg, clearProg, internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -464,6 +500,7 @@ func MakeApplicationDeleteTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if appIdx < 0 {
 		err = errNegativeArgument
@@ -502,6 +539,9 @@ func MakeApplicationDeleteTx(
 	}
 
 	tx, err := transaction.MakeApplicationDeleteTxWithBoxes(uint64(appIdx), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -521,6 +561,7 @@ func MakeApplicationOptInTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if appIdx < 0 {
 		err = errNegativeArgument
@@ -559,6 +600,9 @@ func MakeApplicationOptInTx(
 	}
 
 	tx, err := transaction.MakeApplicationOptInTxWithBoxes(uint64(appIdx), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -578,6 +622,7 @@ func MakeApplicationCloseOutTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if appIdx < 0 {
 		err = errNegativeArgument
@@ -616,6 +661,9 @@ func MakeApplicationCloseOutTx(
 	}
 
 	tx, err := transaction.MakeApplicationCloseOutTxWithBoxes(uint64(appIdx), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -636,6 +684,7 @@ func MakeApplicationClearStateTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if appIdx < 0 {
 		err = errNegativeArgument
@@ -674,6 +723,9 @@ func MakeApplicationClearStateTx(
 	}
 
 	tx, err := transaction.MakeApplicationClearStateTxWithBoxes(uint64(appIdx), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -694,6 +746,7 @@ func MakeApplicationNoOpTx(
 	params *SuggestedParams,
 	sender string,
 	note []byte,
+	options *TransactionOptions,
 ) (encoded []byte, err error) {
 	if appIdx < 0 {
 		err = errNegativeArgument
@@ -732,6 +785,9 @@ func MakeApplicationNoOpTx(
 	}
 
 	tx, err := transaction.MakeApplicationNoOpTxWithBoxes(uint64(appIdx), appArgs.Extract(), accounts.Extract(), internalForeignApps, internalForeignAssets, boxRefs.Extract(), internalParams, senderAddr, note, types.Digest{}, [32]byte{}, types.Address{})
+	if err == nil {
+		err = applyTransactionOptions(&tx, options, internalParams)
+	}
 	if err == nil {
 		encoded = msgpack.Encode(tx)
 	}
@@ -740,7 +796,12 @@ func MakeApplicationNoOpTx(
 }
 
 // MakeOptInAndAssetTransferTxns makes transactions for opting in to one asset
-// and sending the asset to the opted-in receiver.
+// and sending the asset to the opted-in receiver. If the receiver cannot afford the
+// opt in, the group starts with a payment from the sender which funds it.
+//
+// The receiver signs the opt in, so both accounts must be local. closeRemainderTo is
+// ignored. See AssetDeliveryPlanner for receivers which may already be opted in or
+// cannot sign.
 func MakeOptInAndAssetTransferTxns(
 	sender,
 	receiver string,
@@ -753,172 +814,30 @@ func MakeOptInAndAssetTransferTxns(
 	closeRemainderTo string,
 	assetID int64,
 	params *SuggestedParams,
+	options *TransactionOptions,
 ) (transactions *TransactionSignerArray, err error) {
-	var ASSET_OPT_IN_MBR int64 = 100000
-	var FLAT_FEE int64 = 1000
-	var ACCOUNT_MBR int64 = 100000
-
-	if params.Fee > 0 {
-		FLAT_FEE = int64(params.Fee)
-	}
-
-	senderExtractedAmount, _ := senderAlgoAmount.Extract()
-	senderExtractedMinBalance, _ := senderMinBalanceAmount.Extract()
-	receiverExtractedAmount, _ := receiverAlgoAmount.Extract()
-	receiverExtractedMinBalance, _ := receiverMinBalanceAmount.Extract()
-
-	senderExtractedAmountInt64, _ := ConvertUInt64ToInt64(senderExtractedAmount)
-	senderExtractedMinBalanceInt64, _ := ConvertUInt64ToInt64(senderExtractedMinBalance)
-	receiverExtractedAmountInt64, _ := ConvertUInt64ToInt64(receiverExtractedAmount)
-	receiverExtractedMinBalanceInt64, _ := ConvertUInt64ToInt64(receiverExtractedMinBalance)
-
-	// If receiver has enough algo to opt-in to the asset
-	if receiverExtractedAmountInt64-receiverExtractedMinBalanceInt64 >= ASSET_OPT_IN_MBR+FLAT_FEE {
-		optInAmount := MakeUint64(0)
-		receiverOptInTxn, receiverOptInTxnError := MakeAssetTransferTxn(
-			receiver,
-			receiver,
-			"",
-			&optInAmount,
-			nil,
-			params,
-			assetID,
-		)
-		if receiverOptInTxnError != nil {
-			err = receiverOptInTxnError
-			return
-		}
-
-		senderAssetTxn, senderAssetTxnError := MakeAssetTransferTxn(
-			sender,
-			receiver,
-			"",
-			transactionAmount,
-			nil,
-			params,
-			assetID,
-		)
-		if senderAssetTxnError != nil {
-			err = senderAssetTxnError
-			return
-		}
-
-		bytesArrayTxns := BytesArray{values: [][]byte{receiverOptInTxn, senderAssetTxn}}
-		assignedTxns, groupIDError := AssignGroupID(&bytesArrayTxns)
-		if groupIDError != nil {
-			err = groupIDError
-			return
-		}
-
-		transactionSignerItem1 := TransactionSignerItem{
-			signer:      receiver,
-			transaction: assignedTxns.Get(0),
-		}
-		transactionSignerItem2 := TransactionSignerItem{
-			signer:      sender,
-			transaction: assignedTxns.Get(1),
-		}
-		signerArray := []TransactionSignerItem{transactionSignerItem1, transactionSignerItem2}
-
-		transactionList := TransactionSignerArray{
-			signerItems:  signerArray,
-			transactions: assignedTxns,
-		}
-		transactions = &transactionList
-		return
-	} else {
-		// If receiver does not have enough algo to opt-in to the asset
-
-		var receiverExtraAlgoAmount int64 = 0
-		if receiverExtractedAmountInt64 == 0 {
-			receiverExtraAlgoAmount = ACCOUNT_MBR + ASSET_OPT_IN_MBR + FLAT_FEE
-		} else {
-			algoAmount := (ASSET_OPT_IN_MBR + FLAT_FEE) - (receiverExtractedAmountInt64 - receiverExtractedMinBalanceInt64)
-			if algoAmount > 0 {
-				receiverExtraAlgoAmount = algoAmount
-			}
-		}
-
-		if senderExtractedAmountInt64-senderExtractedMinBalanceInt64 < receiverExtraAlgoAmount+FLAT_FEE+FLAT_FEE {
-			err = fmt.Errorf("sender does not have enough algo to cover recivers needs")
-			return
-		}
-
-		var receiverExtraAlgoAmountUint64 uint64 = 0
-		if receiverExtraAlgoAmount > 0 {
-			receiverExtraAlgoAmountUint64 = uint64(receiverExtraAlgoAmount)
-		}
-
-		paymentAMount := MakeUint64(receiverExtraAlgoAmountUint64)
-		paymentTxn, paymentTxnError := MakePaymentTxn(
-			sender,
-			receiver,
-			&paymentAMount,
-			nil,
-			"",
-			params,
-		)
-		if paymentTxnError != nil {
-			err = paymentTxnError
-			return
-		}
-
-		optInAmount := MakeUint64(0)
-		receiverOptInTxn, receiverOptInTxnError := MakeAssetTransferTxn(
-			receiver,
-			receiver,
-			"",
-			&optInAmount,
-			nil,
-			params,
-			assetID,
-		)
-		if receiverOptInTxnError != nil {
-			err = receiverOptInTxnError
-			return
-		}
-
-		senderAssetTxn, senderAssetTxnError := MakeAssetTransferTxn(
-			sender,
-			receiver,
-			"",
-			transactionAmount,
-			nil,
-			params,
-			assetID,
-		)
-		if senderAssetTxnError != nil {
-			err = senderAssetTxnError
-			return
-		}
-
-		bytesArrayTxns := BytesArray{values: [][]byte{paymentTxn, receiverOptInTxn, senderAssetTxn}}
-		assignedTxns, groupIDError := AssignGroupID(&bytesArrayTxns)
-		if groupIDError != nil {
-			err = groupIDError
-			return
-		}
-		transactionSignerItem1 := TransactionSignerItem{
-			signer:      sender,
-			transaction: assignedTxns.Get(0),
-		}
-		transactionSignerItem2 := TransactionSignerItem{
-			signer:      receiver,
-			transaction: assignedTxns.Get(1),
-		}
-		transactionSignerItem3 := TransactionSignerItem{
-			signer:      sender,
-			transaction: assignedTxns.Get(2),
-		}
-		signerArray := []TransactionSignerItem{transactionSignerItem1, transactionSignerItem2, transactionSignerItem3}
+	planner, err := NewAssetDeliveryPlanner(sender, receiver, assetID, transactionAmount)
+	if err != nil {
+		return
+	}
+	planner.SetNote(note)
+	planner.SetTransactionOptions(options)
+	planner.SetReceiverCanSign(true)
+	err = planner.SetSenderBalance(senderAlgoAmount, senderMinBalanceAmount)
+	if err != nil {
+		return
+	}
+	err = planner.SetReceiverState(receiverAlgoAmount, receiverMinBalanceAmount, false)
+	if err != nil {
+		return
+	}
 
-		transactionList := TransactionSignerArray{
-			signerItems:  signerArray,
-			transactions: assignedTxns,
-		}
-		transactions = &transactionList
+	plan, err := planner.Plan(params)
+	if err != nil {
 		return
 	}
+	transactions = plan.Transactions()
+	return
 }
 
 // Calculate the min balance amount needed by the receiver account
@@ -20,7 +20,7 @@ func TestMakePaymentTxn(t *testing.T) {
 		GenesisHash:     mustDecodeB64(t, "JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
 	}
 	amount := MakeUint64(1000)
-	encodedTx, err := MakePaymentTxn(fromAddress, toAddress, &amount, mustDecodeB64(t, "6gAVR0Nsv5Y="), "IDUTJEUIEVSMXTU4LGTJWZ2UE2E6TIODUKU6UW3FU3UKIQQ77RLUBBBFLA", &params)
+	encodedTx, err := MakePaymentTxn(fromAddress, toAddress, &amount, mustDecodeB64(t, "6gAVR0Nsv5Y="), "IDUTJEUIEVSMXTU4LGTJWZ2UE2E6TIODUKU6UW3FU3UKIQQ77RLUBBBFLA", &params, nil)
 	require.NoError(t, err)
 
 	expectedEncodedTx := mustDecodeB64(t, "i6NhbXTNA+ilY2xvc2XEIEDpNJKIJWTLzpxZpptnVCaJ6aHDoqnqW2Wm6KRCH/xXo2ZlZc0EmKJmds0wsqNnZW6sZGV2bmV0LXYzMy4womdoxCAmCyAJoJOohot5WHIvpeVG7eftF+TYXEx4r7BFJpDt0qJsds00mqRub3RlxAjqABVHQ2y/lqNyY3bEIHts4k/rW6zAsWTinCIsV/X2PcOH1DkEglhBHF/hD3wCo3NuZMQg5/D4TQaBHfnzHI2HixFV9GcdUaGFwgCQhmf0SVhwaKGkdHlwZaNwYXk=")
@@ -38,7 +38,7 @@ func TestMakeRekeyTxn(t *testing.T) {
 		GenesisID:       "devnet-v33.0",
 		GenesisHash:     mustDecodeB64(t, "JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
 	}
-	encodedTx, err := MakeRekeyTxn(fromAddress, rekeyToAddress, &params)
+	encodedTx, err := MakeRekeyTxn(fromAddress, rekeyToAddress, &params, nil)
 	require.NoError(t, err)
 
 	expectedEncodedTx := mustDecodeB64(t, "iaNmZWXNA+iiZnbNMLKjZ2VurGRldm5ldC12MzMuMKJnaMQgJgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dKibHbNNJqjcmN2xCDn8PhNBoEd+fMcjYeLEVX0Zx1RoYXCAJCGZ/RJWHBooaVyZWtlecQge2ziT+tbrMCxZOKcIixX9fY9w4fUOQSCWEEcX+EPfAKjc25kxCDn8PhNBoEd+fMcjYeLEVX0Zx1RoYXCAJCGZ/RJWHBooaR0eXBlo3BheQ==")
@@ -90,6 +90,7 @@ func TestMakeApplicationCreateTx(t *testing.T) {
 		&params,
 		types.ZeroAddress.String(),
 		note,
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -133,8 +134,8 @@ func TestMakeOptInAndAssetTransferTxns_SenderFundsReceiver(t *testing.T) {
 		"",
 		assetID,
 		&params,
+		nil,
 	)
-
 	require.NoError(t, err, "Should not fail if sender has enough ALGO to fund receiver")
 	require.NotNil(t, txns)
 	require.Equal(t, 3, len(txns.signerItems), "Should have generated 3 transactions")
@@ -160,8 +161,8 @@ func TestMakeOptInAndAssetTransferTxns_InsufficientSenderFunds(t *testing.T) {
 		&senderAlgoAmount, &senderMinBalance,
 		&receiverAlgoAmount, &receiverMinBalance,
 		nil, "", 12345, &params,
+		nil,
 	)
-
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "sender does not have enough algo")
 }
@@ -201,8 +202,8 @@ func TestMakeOptInAndAssetTransferTxns_ReceiverIsWhaleButNotOptedIn(t *testing.T
 		"",
 		assetID,
 		&params,
+		nil,
 	)
-
 	require.NoError(t, err, "Sender should be able to fund the whale's MBR gap")
 	require.NotNil(t, txns)
 	require.Equal(t, 3, len(txns.signerItems), "Should produce 3 txns (Funding, Opt-in, Transfer)")
@@ -0,0 +1,729 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+const (
+	maxTxnNoteBytes  = 1024
+	maxTxnLife       = 1000
+	maxAssetDecimals = 19
+)
+
+// TransactionBuilder assembles a transaction of any type one field at a time.
+// Every setter returns the builder so calls can be chained. The first invalid
+// value passed to a setter is remembered and returned by Build.
+//
+// Setters which take an address accept an empty string to clear the field.
+type TransactionBuilder struct {
+	txn types.Transaction
+
+	// feeParams is the fee policy of SetSuggestedParams. The fee is computed by
+	// Build so it accounts for every field set on the transaction.
+	feeParams *types.SuggestedParams
+	boxes     []types.AppBoxReference
+	err       error
+}
+
+// NewTransactionBuilder creates a builder for a transaction of the given type:
+// "pay", "keyreg", "acfg", "axfer", "afrz", "appl", "stpf" or "hb".
+func NewTransactionBuilder(txType string) (*TransactionBuilder, error) {
+	if !isKnownTxType(types.TxType(txType)) {
+		return nil, fmt.Errorf("unknown transaction type: %s", txType)
+	}
+	return &TransactionBuilder{txn: types.Transaction{Type: types.TxType(txType)}}, nil
+}
+
+// NewTransactionBuilderFromTxn creates a builder holding every field of an
+// encoded transaction, so it can be modified and built again.
+func NewTransactionBuilderFromTxn(encodedTxn []byte) (*TransactionBuilder, error) {
+	var txn types.Transaction
+	if err := msgpack.Decode(encodedTxn, &txn); err != nil {
+		return nil, fmt.Errorf("could not decode transaction: %v", err)
+	}
+	if !isKnownTxType(txn.Type) {
+		return nil, fmt.Errorf("unknown transaction type: %s", txn.Type)
+	}
+	return &TransactionBuilder{txn: txn}, nil
+}
+
+func isKnownTxType(txType types.TxType) bool {
+	switch txType {
+	case types.PaymentTx, types.KeyRegistrationTx, types.AssetConfigTx, types.AssetTransferTx,
+		types.AssetFreezeTx, types.ApplicationCallTx, types.StateProofTx, types.HeartbeatTx:
+		return true
+	}
+	return false
+}
+
+func (b *TransactionBuilder) fail(format string, args ...interface{}) {
+	if b.err == nil {
+		b.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (b *TransactionBuilder) address(field, value string) (addr types.Address) {
+	if value == "" {
+		return
+	}
+	addr, err := types.DecodeAddress(value)
+	if err != nil {
+		b.fail("invalid %s: %v", field, err)
+	}
+	return
+}
+
+func (b *TransactionBuilder) extract(field string, value *Uint64) uint64 {
+	if value == nil {
+		b.fail("%s must not be nil", field)
+		return 0
+	}
+	extracted, err := value.Extract()
+	if err != nil {
+		b.fail("invalid %s: %v", field, err)
+	}
+	return extracted
+}
+
+func (b *TransactionBuilder) nonNegative(field string, value int64) uint64 {
+	if value < 0 {
+		b.fail("invalid %s: %v", field, errNegativeArgument)
+		return 0
+	}
+	return uint64(value)
+}
+
+func (b *TransactionBuilder) fixed(field string, dst []byte, value []byte) {
+	if len(value) != 0 && len(value) != len(dst) {
+		b.fail("%s must be %d bytes, got %d", field, len(dst), len(value))
+		return
+	}
+	// an empty value clears the field
+	copy(dst, make([]byte, len(dst)))
+	copy(dst, value)
+}
+
+func (b *TransactionBuilder) heartbeat() *types.HeartbeatTxnFields {
+	if b.txn.HeartbeatTxnFields == nil {
+		b.txn.HeartbeatTxnFields = &types.HeartbeatTxnFields{}
+	}
+	return b.txn.HeartbeatTxnFields
+}
+
+// SetSender sets the account which sends the transaction.
+func (b *TransactionBuilder) SetSender(sender string) *TransactionBuilder {
+	b.txn.Sender = b.address("sender", sender)
+	return b
+}
+
+// SetSuggestedParams sets the validity window and genesis of the transaction.
+// Unless SetFee is called afterwards, the fee is computed by Build from the
+// suggested fee, so it includes every field set on the builder.
+func (b *TransactionBuilder) SetSuggestedParams(params *SuggestedParams) *TransactionBuilder {
+	if params == nil {
+		b.fail("suggested params must not be nil")
+		return b
+	}
+	internalParams, err := convertSuggestedParams(params)
+	if err != nil {
+		b.fail("%v", err)
+		return b
+	}
+	b.txn.FirstValid = internalParams.FirstRoundValid
+	b.txn.LastValid = internalParams.LastRoundValid
+	b.txn.GenesisID = internalParams.GenesisID
+	b.SetGenesisHash(internalParams.GenesisHash)
+	b.feeParams = &internalParams
+	return b
+}
+
+// SetFee sets a flat fee in microAlgos, replacing the fee of SetSuggestedParams.
+func (b *TransactionBuilder) SetFee(fee *Uint64) *TransactionBuilder {
+	b.txn.Fee = types.MicroAlgos(b.extract("fee", fee))
+	b.feeParams = nil
+	return b
+}
+
+// SetFirstValid sets the first round in which the transaction is valid.
+func (b *TransactionBuilder) SetFirstValid(round int64) *TransactionBuilder {
+	b.txn.FirstValid = types.Round(b.nonNegative("first valid round", round))
+	return b
+}
+
+// SetLastValid sets the last round in which the transaction is valid.
+func (b *TransactionBuilder) SetLastValid(round int64) *TransactionBuilder {
+	b.txn.LastValid = types.Round(b.nonNegative("last valid round", round))
+	return b
+}
+
+// SetNote sets the note of the transaction.
+func (b *TransactionBuilder) SetNote(note []byte) *TransactionBuilder {
+	b.txn.Note = note
+	return b
+}
+
+// SetGenesisID sets the genesis ID of the network.
+func (b *TransactionBuilder) SetGenesisID(genesisID string) *TransactionBuilder {
+	b.txn.GenesisID = genesisID
+	return b
+}
+
+// SetGenesisHash sets the 32 byte genesis hash of the network.
+func (b *TransactionBuilder) SetGenesisHash(genesisHash []byte) *TransactionBuilder {
+	b.fixed("genesis hash", b.txn.GenesisHash[:], genesisHash)
+	return b
+}
+
+// SetGroup sets the 32 byte group ID of the transaction.
+func (b *TransactionBuilder) SetGroup(group []byte) *TransactionBuilder {
+	b.fixed("group", b.txn.Group[:], group)
+	return b
+}
+
+// SetLease sets the 32 byte lease of the transaction.
+func (b *TransactionBuilder) SetLease(lease []byte) *TransactionBuilder {
+	b.fixed("lease", b.txn.Lease[:], lease)
+	return b
+}
+
+// SetRekeyTo sets the account the sender is rekeyed to.
+func (b *TransactionBuilder) SetRekeyTo(rekeyTo string) *TransactionBuilder {
+	b.txn.RekeyTo = b.address("rekey address", rekeyTo)
+	return b
+}
+
+// SetReceiver sets the receiver of a payment.
+func (b *TransactionBuilder) SetReceiver(receiver string) *TransactionBuilder {
+	b.txn.Receiver = b.address("receiver", receiver)
+	return b
+}
+
+// SetAmount sets the amount of a payment in microAlgos.
+func (b *TransactionBuilder) SetAmount(amount *Uint64) *TransactionBuilder {
+	b.txn.Amount = types.MicroAlgos(b.extract("amount", amount))
+	return b
+}
+
+// SetCloseRemainderTo sets the account which receives the remaining balance
+// of the sender of a payment.
+func (b *TransactionBuilder) SetCloseRemainderTo(closeRemainderTo string) *TransactionBuilder {
+	b.txn.CloseRemainderTo = b.address("close remainder to address", closeRemainderTo)
+	return b
+}
+
+// SetVoteKey sets the 32 byte participation vote key of a key registration.
+func (b *TransactionBuilder) SetVoteKey(voteKey []byte) *TransactionBuilder {
+	b.fixed("vote key", b.txn.VotePK[:], voteKey)
+	return b
+}
+
+// SetSelectionKey sets the 32 byte VRF selection key of a key registration.
+func (b *TransactionBuilder) SetSelectionKey(selectionKey []byte) *TransactionBuilder {
+	b.fixed("selection key", b.txn.SelectionPK[:], selectionKey)
+	return b
+}
+
+// SetStateProofKey sets the 64 byte state proof key of a key registration.
+func (b *TransactionBuilder) SetStateProofKey(stateProofKey []byte) *TransactionBuilder {
+	b.fixed("state proof key", b.txn.StateProofPK[:], stateProofKey)
+	return b
+}
+
+// SetVoteFirst sets the first round the participation keys are valid for.
+func (b *TransactionBuilder) SetVoteFirst(voteFirst *Uint64) *TransactionBuilder {
+	b.txn.VoteFirst = types.Round(b.extract("vote first", voteFirst))
+	return b
+}
+
+// SetVoteLast sets the last round the participation keys are valid for.
+func (b *TransactionBuilder) SetVoteLast(voteLast *Uint64) *TransactionBuilder {
+	b.txn.VoteLast = types.Round(b.extract("vote last", voteLast))
+	return b
+}
+
+// SetVoteKeyDilution sets the key dilution of the participation keys.
+func (b *TransactionBuilder) SetVoteKeyDilution(voteKeyDilution *Uint64) *TransactionBuilder {
+	b.txn.VoteKeyDilution = b.extract("vote key dilution", voteKeyDilution)
+	return b
+}
+
+// SetNonparticipation marks the sender of a key registration as permanently
+// non-participating.
+func (b *TransactionBuilder) SetNonparticipation(nonparticipation bool) *TransactionBuilder {
+	b.txn.Nonparticipation = nonparticipation
+	return b
+}
+
+// SetConfigAsset sets the asset reconfigured or destroyed by an asset config
+// transaction. Zero creates a new asset.
+func (b *TransactionBuilder) SetConfigAsset(assetID int64) *TransactionBuilder {
+	b.txn.ConfigAsset = types.AssetIndex(b.nonNegative("config asset", assetID))
+	return b
+}
+
+// SetAssetTotal sets the total number of units of a new asset.
+func (b *TransactionBuilder) SetAssetTotal(total *Uint64) *TransactionBuilder {
+	b.txn.AssetParams.Total = b.extract("asset total", total)
+	return b
+}
+
+// SetAssetDecimals sets the number of decimals of a new asset.
+func (b *TransactionBuilder) SetAssetDecimals(decimals int32) *TransactionBuilder {
+	if decimals < 0 {
+		b.fail("invalid asset decimals: %v", errNegativeArgument)
+		return b
+	}
+	b.txn.AssetParams.Decimals = uint32(decimals)
+	return b
+}
+
+// SetAssetDefaultFrozen sets whether holdings of a new asset start frozen.
+func (b *TransactionBuilder) SetAssetDefaultFrozen(defaultFrozen bool) *TransactionBuilder {
+	b.txn.AssetParams.DefaultFrozen = defaultFrozen
+	return b
+}
+
+// SetAssetUnitName sets the unit name of a new asset.
+func (b *TransactionBuilder) SetAssetUnitName(unitName string) *TransactionBuilder {
+	b.txn.AssetParams.UnitName = unitName
+	return b
+}
+
+// SetAssetName sets the name of a new asset.
+func (b *TransactionBuilder) SetAssetName(assetName string) *TransactionBuilder {
+	b.txn.AssetParams.AssetName = assetName
+	return b
+}
+
+// SetAssetURL sets the URL of a new asset.
+func (b *TransactionBuilder) SetAssetURL(url string) *TransactionBuilder {
+	b.txn.AssetParams.URL = url
+	return b
+}
+
+// SetAssetMetadataHash sets the 32 byte metadata hash of a new asset.
+func (b *TransactionBuilder) SetAssetMetadataHash(metadataHash []byte) *TransactionBuilder {
+	b.fixed("asset metadata hash", b.txn.AssetParams.MetadataHash[:], metadataHash)
+	return b
+}
+
+// SetAssetManager sets the manager address of an asset.
+func (b *TransactionBuilder) SetAssetManager(manager string) *TransactionBuilder {
+	b.txn.AssetParams.Manager = b.address("asset manager", manager)
+	return b
+}
+
+// SetAssetReserve sets the reserve address of an asset.
+func (b *TransactionBuilder) SetAssetReserve(reserve string) *TransactionBuilder {
+	b.txn.AssetParams.Reserve = b.address("asset reserve", reserve)
+	return b
+}
+
+// SetAssetFreeze sets the freeze address of an asset.
+func (b *TransactionBuilder) SetAssetFreeze(freeze string) *TransactionBuilder {
+	b.txn.AssetParams.Freeze = b.address("asset freeze", freeze)
+	return b
+}
+
+// SetAssetClawback sets the clawback address of an asset.
+func (b *TransactionBuilder) SetAssetClawback(clawback string) *TransactionBuilder {
+	b.txn.AssetParams.Clawback = b.address("asset clawback", clawback)
+	return b
+}
+
+// SetXferAsset sets the asset moved by an asset transfer.
+func (b *TransactionBuilder) SetXferAsset(assetID int64) *TransactionBuilder {
+	b.txn.XferAsset = types.AssetIndex(b.nonNegative("transfer asset", assetID))
+	return b
+}
+
+// SetAssetAmount sets the number of units moved by an asset transfer.
+func (b *TransactionBuilder) SetAssetAmount(amount *Uint64) *TransactionBuilder {
+	b.txn.AssetAmount = b.extract("asset amount", amount)
+	return b
+}
+
+// SetAssetSender sets the account an asset is clawed back from.
+func (b *TransactionBuilder) SetAssetSender(assetSender string) *TransactionBuilder {
+	b.txn.AssetSender = b.address("asset sender", assetSender)
+	return b
+}
+
+// SetAssetReceiver sets the receiver of an asset transfer.
+func (b *TransactionBuilder) SetAssetReceiver(assetReceiver string) *TransactionBuilder {
+	b.txn.AssetReceiver = b.address("asset receiver", assetReceiver)
+	return b
+}
+
+// SetAssetCloseTo sets the account which receives the remaining holding of
+// the sender of an asset transfer.
+func (b *TransactionBuilder) SetAssetCloseTo(assetCloseTo string) *TransactionBuilder {
+	b.txn.AssetCloseTo = b.address("asset close to address", assetCloseTo)
+	return b
+}
+
+// SetFreezeAccount sets the account whose holding is frozen or unfrozen.
+func (b *TransactionBuilder) SetFreezeAccount(freezeAccount string) *TransactionBuilder {
+	b.txn.FreezeAccount = b.address("freeze account", freezeAccount)
+	return b
+}
+
+// SetFreezeAsset sets the asset of an asset freeze transaction.
+func (b *TransactionBuilder) SetFreezeAsset(assetID int64) *TransactionBuilder {
+	b.txn.FreezeAsset = types.AssetIndex(b.nonNegative("freeze asset", assetID))
+	return b
+}
+
+// SetAssetFrozen sets the new frozen state of an asset freeze transaction.
+func (b *TransactionBuilder) SetAssetFrozen(frozen bool) *TransactionBuilder {
+	b.txn.AssetFrozen = frozen
+	return b
+}
+
+// SetApplicationID sets the called application. Zero creates a new application.
+func (b *TransactionBuilder) SetApplicationID(appID int64) *TransactionBuilder {
+	b.txn.ApplicationID = types.AppIndex(b.nonNegative("application id", appID))
+	return b
+}
+
+// SetOnCompletion sets the OnCompletion action of an application call. The
+// accepted values are the same as for NewAddMethodCallParams.
+func (b *TransactionBuilder) SetOnCompletion(onComplete int) *TransactionBuilder {
+	if onComplete < 0 || types.OnCompletion(onComplete) > types.DeleteApplicationOC {
+		b.fail("invalid OnCompletion value: %d", onComplete)
+		return b
+	}
+	b.txn.OnCompletion = types.OnCompletion(onComplete)
+	return b
+}
+
+// SetApplicationArgs sets the arguments of an application call.
+func (b *TransactionBuilder) SetApplicationArgs(args *BytesArray) *TransactionBuilder {
+	b.txn.ApplicationArgs = nil
+	if args != nil && args.Length() != 0 {
+		b.txn.ApplicationArgs = args.Extract()
+	}
+	return b
+}
+
+// SetAccounts sets the foreign accounts of an application call.
+func (b *TransactionBuilder) SetAccounts(accounts *StringArray) *TransactionBuilder {
+	b.txn.Accounts = nil
+	if accounts == nil {
+		return b
+	}
+	for _, account := range accounts.Extract() {
+		b.txn.Accounts = append(b.txn.Accounts, b.address("account", account))
+	}
+	return b
+}
+
+// SetForeignApps sets the foreign applications of an application call.
+func (b *TransactionBuilder) SetForeignApps(foreignApps *Int64Array) *TransactionBuilder {
+	b.txn.ForeignApps = nil
+	if foreignApps == nil {
+		return b
+	}
+	for _, appID := range foreignApps.Extract() {
+		b.txn.ForeignApps = append(b.txn.ForeignApps, types.AppIndex(b.nonNegative("foreign app", appID)))
+	}
+	return b
+}
+
+// SetForeignAssets sets the foreign assets of an application call.
+func (b *TransactionBuilder) SetForeignAssets(foreignAssets *Int64Array) *TransactionBuilder {
+	b.txn.ForeignAssets = nil
+	if foreignAssets == nil {
+		return b
+	}
+	for _, assetID := range foreignAssets.Extract() {
+		b.txn.ForeignAssets = append(b.txn.ForeignAssets, types.AssetIndex(b.nonNegative("foreign asset", assetID)))
+	}
+	return b
+}
+
+// SetBoxReferences sets the boxes of an application call. Each box names an
+// application by ID, which Build resolves against the foreign applications.
+func (b *TransactionBuilder) SetBoxReferences(boxes *AppBoxRefArray) *TransactionBuilder {
+	b.boxes = []types.AppBoxReference{}
+	if boxes != nil {
+		b.boxes = append(b.boxes, boxes.Extract()...)
+	}
+	return b
+}
+
+// AddAccessAddress appends an account to the access list of an application call.
+func (b *TransactionBuilder) AddAccessAddress(address string) *TransactionBuilder {
+	b.txn.Access = append(b.txn.Access, types.ResourceRef{Address: b.address("access address", address)})
+	return b
+}
+
+// AddAccessAsset appends an asset to the access list of an application call.
+func (b *TransactionBuilder) AddAccessAsset(assetID int64) *TransactionBuilder {
+	b.txn.Access = append(b.txn.Access, types.ResourceRef{Asset: types.AssetIndex(b.nonNegative("access asset", assetID))})
+	return b
+}
+
+// AddAccessApp appends an application to the access list of an application call.
+func (b *TransactionBuilder) AddAccessApp(appID int64) *TransactionBuilder {
+	b.txn.Access = append(b.txn.Access, types.ResourceRef{App: types.AppIndex(b.nonNegative("access app", appID))})
+	return b
+}
+
+// AddAccessHolding appends an asset holding to the access list of an
+// application call. The indices are 1-based positions of earlier entries of
+// the access list, and an address index of 0 means the sender.
+func (b *TransactionBuilder) AddAccessHolding(addressIndex, assetIndex int64) *TransactionBuilder {
+	b.txn.Access = append(b.txn.Access, types.ResourceRef{Holding: types.HoldingRef{
+		Address: b.nonNegative("holding address index", addressIndex),
+		Asset:   b.nonNegative("holding asset index", assetIndex),
+	}})
+	return b
+}
+
+// AddAccessLocals appends a local state to the access list of an application
+// call. The indices are 1-based positions of earlier entries of the access
+// list, and an index of 0 means the sender or the called application.
+func (b *TransactionBuilder) AddAccessLocals(addressIndex, appIndex int64) *TransactionBuilder {
+	b.txn.Access = append(b.txn.Access, types.ResourceRef{Locals: types.LocalsRef{
+		Address: b.nonNegative("locals address index", addressIndex),
+		App:     b.nonNegative("locals app index", appIndex),
+	}})
+	return b
+}
+
+// AddAccessBox appends a box to the access list of an application call. The
+// app index is the 1-based position of an earlier application entry of the
+// access list, or 0 for the called application.
+func (b *TransactionBuilder) AddAccessBox(appIndex int64, name []byte) *TransactionBuilder {
+	b.txn.Access = append(b.txn.Access, types.ResourceRef{Box: types.BoxReference{
+		ForeignAppIdx: b.nonNegative("box app index", appIndex),
+		Name:          name,
+	}})
+	return b
+}
+
+// SetLocalStateSchema sets the local state schema of a new application.
+func (b *TransactionBuilder) SetLocalStateSchema(numUint, numByteSlice int64) *TransactionBuilder {
+	b.txn.LocalStateSchema = types.StateSchema{
+		NumUint:      b.nonNegative("local uints", numUint),
+		NumByteSlice: b.nonNegative("local byte slices", numByteSlice),
+	}
+	return b
+}
+
+// SetGlobalStateSchema sets the global state schema of a new application.
+func (b *TransactionBuilder) SetGlobalStateSchema(numUint, numByteSlice int64) *TransactionBuilder {
+	b.txn.GlobalStateSchema = types.StateSchema{
+		NumUint:      b.nonNegative("global uints", numUint),
+		NumByteSlice: b.nonNegative("global byte slices", numByteSlice),
+	}
+	return b
+}
+
+// SetApprovalProgram sets the compiled approval program of an application.
+func (b *TransactionBuilder) SetApprovalProgram(program []byte) *TransactionBuilder {
+	b.txn.ApprovalProgram = program
+	return b
+}
+
+// SetClearStateProgram sets the compiled clear state program of an application.
+func (b *TransactionBuilder) SetClearStateProgram(program []byte) *TransactionBuilder {
+	b.txn.ClearStateProgram = program
+	return b
+}
+
+// SetExtraProgramPages sets the number of extra program pages of a new application.
+func (b *TransactionBuilder) SetExtraProgramPages(extraPages int32) *TransactionBuilder {
+	if extraPages < 0 {
+		b.fail("invalid extra program pages: %v", errNegativeArgument)
+		return b
+	}
+	b.txn.ExtraProgramPages = uint32(extraPages)
+	return b
+}
+
+// SetRejectVersion sets the lowest application version the call is rejected for.
+func (b *TransactionBuilder) SetRejectVersion(version int64) *TransactionBuilder {
+	b.txn.RejectVersion = b.nonNegative("reject version", version)
+	return b
+}
+
+// SetStateProofType sets the type of a state proof transaction.
+func (b *TransactionBuilder) SetStateProofType(stateProofType int64) *TransactionBuilder {
+	b.txn.StateProofType = types.StateProofType(b.nonNegative("state proof type", stateProofType))
+	return b
+}
+
+// SetStateProof sets the msgpack encoded state proof of a state proof transaction.
+func (b *TransactionBuilder) SetStateProof(encodedStateProof []byte) *TransactionBuilder {
+	var stateProof types.StateProof
+	if err := msgpack.Decode(encodedStateProof, &stateProof); err != nil {
+		b.fail("could not decode state proof: %v", err)
+		return b
+	}
+	b.txn.StateProof = stateProof
+	return b
+}
+
+// SetStateProofMessage sets the msgpack encoded message of a state proof transaction.
+func (b *TransactionBuilder) SetStateProofMessage(encodedMessage []byte) *TransactionBuilder {
+	var message types.Message
+	if err := msgpack.Decode(encodedMessage, &message); err != nil {
+		b.fail("could not decode state proof message: %v", err)
+		return b
+	}
+	b.txn.Message = message
+	return b
+}
+
+// SetHeartbeatAddress sets the account a heartbeat proves online.
+func (b *TransactionBuilder) SetHeartbeatAddress(address string) *TransactionBuilder {
+	b.heartbeat().HbAddress = b.address("heartbeat address", address)
+	return b
+}
+
+// SetHeartbeatProof sets the participation key signature of a heartbeat. The
+// signatures are 64 bytes and the keys 32 bytes.
+func (b *TransactionBuilder) SetHeartbeatProof(sig, pk, pk2, pk1Sig, pk2Sig []byte) *TransactionBuilder {
+	proof := &b.heartbeat().HbProof
+	b.fixed("heartbeat signature", proof.Sig[:], sig)
+	b.fixed("heartbeat public key", proof.PK[:], pk)
+	b.fixed("heartbeat second public key", proof.PK2[:], pk2)
+	b.fixed("heartbeat first key signature", proof.PK1Sig[:], pk1Sig)
+	b.fixed("heartbeat second key signature", proof.PK2Sig[:], pk2Sig)
+	return b
+}
+
+// SetHeartbeatSeed sets the 32 byte block seed signed by a heartbeat.
+func (b *TransactionBuilder) SetHeartbeatSeed(seed []byte) *TransactionBuilder {
+	b.fixed("heartbeat seed", b.heartbeat().HbSeed[:], seed)
+	return b
+}
+
+// SetHeartbeatVoteID sets the 32 byte vote key of the account of a heartbeat.
+func (b *TransactionBuilder) SetHeartbeatVoteID(voteID []byte) *TransactionBuilder {
+	b.fixed("heartbeat vote id", b.heartbeat().HbVoteID[:], voteID)
+	return b
+}
+
+// SetHeartbeatKeyDilution sets the key dilution of the account of a heartbeat.
+func (b *TransactionBuilder) SetHeartbeatKeyDilution(keyDilution *Uint64) *TransactionBuilder {
+	b.heartbeat().HbKeyDilution = b.extract("heartbeat key dilution", keyDilution)
+	return b
+}
+
+// Build validates the transaction and returns it encoded as msgpack.
+func (b *TransactionBuilder) Build() (encoded []byte, err error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	txn := b.txn
+	if b.boxes != nil {
+		txn.BoxReferences, err = resolveBoxReferences(b.boxes, txn.ForeignApps, txn.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = validateBuiltTxn(&txn); err != nil {
+		return nil, err
+	}
+
+	if b.feeParams != nil {
+		if err = setTxnFee(&txn, *b.feeParams); err != nil {
+			return nil, err
+		}
+	}
+
+	return msgpack.Encode(&txn), nil
+}
+
+func resolveBoxReferences(boxes []types.AppBoxReference, foreignApps []types.AppIndex, appID types.AppIndex) ([]types.BoxReference, error) {
+	var resolved []types.BoxReference
+	for _, box := range boxes {
+		ref := types.BoxReference{Name: box.Name}
+		found := box.AppID == 0
+		for i, foreignApp := range foreignApps {
+			if !found && uint64(foreignApp) == box.AppID {
+				ref.ForeignAppIdx = uint64(i + 1)
+				found = true
+			}
+		}
+		// the called application may also be referenced by its ID
+		found = found || types.AppIndex(box.AppID) == appID
+		if !found {
+			return nil, fmt.Errorf("the app id %d provided for this box is not in the foreign apps", box.AppID)
+		}
+		resolved = append(resolved, ref)
+	}
+	return resolved, nil
+}
+
+func validateBuiltTxn(txn *types.Transaction) error {
+	if txn.Sender.IsZero() {
+		return errors.New("transaction sender is not set")
+	}
+	if txn.GenesisHash == (types.Digest{}) {
+		return errors.New("transaction must contain a genesis hash")
+	}
+	if txn.LastValid < txn.FirstValid {
+		return fmt.Errorf("last valid round %d is before first valid round %d", txn.LastValid, txn.FirstValid)
+	}
+	if txn.LastValid-txn.FirstValid > maxTxnLife {
+		return fmt.Errorf("validity window of %d rounds is longer than %d rounds", txn.LastValid-txn.FirstValid, maxTxnLife)
+	}
+	if len(txn.Note) > maxTxnNoteBytes {
+		return fmt.Errorf("note is %d bytes, more than the maximum of %d", len(txn.Note), maxTxnNoteBytes)
+	}
+
+	sections := []struct {
+		txType types.TxType
+		fields interface{}
+		empty  interface{}
+	}{
+		{types.PaymentTx, txn.PaymentTxnFields, types.PaymentTxnFields{}},
+		{types.KeyRegistrationTx, txn.KeyregTxnFields, types.KeyregTxnFields{}},
+		{types.AssetConfigTx, txn.AssetConfigTxnFields, types.AssetConfigTxnFields{}},
+		{types.AssetTransferTx, txn.AssetTransferTxnFields, types.AssetTransferTxnFields{}},
+		{types.AssetFreezeTx, txn.AssetFreezeTxnFields, types.AssetFreezeTxnFields{}},
+		{types.ApplicationCallTx, txn.ApplicationFields, types.ApplicationFields{}},
+		{types.StateProofTx, txn.StateProofTxnFields, types.StateProofTxnFields{}},
+	}
+	for _, section := range sections {
+		if section.txType != txn.Type && !reflect.DeepEqual(section.fields, section.empty) {
+			return fmt.Errorf("%s transaction sets fields of %s transactions", txn.Type, section.txType)
+		}
+	}
+	if txn.Type != types.HeartbeatTx && txn.HeartbeatTxnFields != nil {
+		return fmt.Errorf("%s transaction sets fields of %s transactions", txn.Type, types.HeartbeatTx)
+	}
+
+	switch txn.Type {
+	case types.KeyRegistrationTx:
+		if txn.VoteLast < txn.VoteFirst {
+			return fmt.Errorf("vote last round %d is before vote first round %d", txn.VoteLast, txn.VoteFirst)
+		}
+	case types.AssetConfigTx:
+		if txn.AssetParams.Decimals > maxAssetDecimals {
+			return fmt.Errorf("asset decimals must be at most %d", maxAssetDecimals)
+		}
+	case types.ApplicationCallTx:
+		if len(txn.Access) != 0 && (len(txn.Accounts) != 0 || len(txn.ForeignApps) != 0 ||
+			len(txn.ForeignAssets) != 0 || len(txn.BoxReferences) != 0) {
+			return errors.New("application calls with an access list cannot use foreign accounts, apps, assets or boxes")
+		}
+	case types.HeartbeatTx:
+		if txn.HeartbeatTxnFields == nil || txn.HbAddress.IsZero() {
+			return errors.New("heartbeat address is not set")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package sdk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeTestTxn(t *testing.T, encoded []byte) types.Transaction {
+	t.Helper()
+	var txn types.Transaction
+	require.NoError(t, msgpack.Decode(encoded, &txn))
+	return txn
+}
+
+func TestTransactionBuilderPayment(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	receiver := types.Address{2}.String()
+	amount := MakeUint64(5000)
+	params := makeTestARC59Params()
+	params.Fee = 10
+
+	// without header extras the builder matches MakePaymentTxn, including the per byte fee
+	expected, err := MakePaymentTxn(sender, receiver, &amount, []byte("note"), "", params, nil)
+	require.NoError(t, err)
+	builder, err := NewTransactionBuilder("pay")
+	require.NoError(t, err)
+	encoded, err := builder.SetSender(sender).SetReceiver(receiver).SetAmount(&amount).
+		SetNote([]byte("note")).SetSuggestedParams(params).Build()
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	// the fee grows with the lease and rekey address set after the suggested params
+	lease := bytes.Repeat([]byte{7}, 32)
+	encoded, err = builder.SetLease(lease).SetRekeyTo(types.Address{3}.String()).Build()
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, [32]byte(lease), [32]byte(txn.Lease))
+	require.Equal(t, types.Address{3}, txn.RekeyTo)
+	require.Greater(t, txn.Fee, decodeTestTxn(t, expected).Fee)
+
+	fee := MakeUint64(2000)
+	encoded, err = builder.SetFee(&fee).SetFirstValid(10).SetLastValid(20).SetRekeyTo("").Build()
+	require.NoError(t, err)
+	txn = decodeTestTxn(t, encoded)
+	require.Equal(t, types.MicroAlgos(2000), txn.Fee)
+	require.Equal(t, types.Round(10), txn.FirstValid)
+	require.Equal(t, types.Round(20), txn.LastValid)
+	require.True(t, txn.RekeyTo.IsZero())
+}
+
+func TestTransactionBuilderApplicationCall(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	params := makeTestARC59Params()
+	params.Fee = 1000
+	params.FlatFee = true
+	args := &BytesArray{values: [][]byte{[]byte("arg")}}
+	accounts := &StringArray{values: []string{types.Address{2}.String()}}
+	apps := &Int64Array{values: []int64{20}}
+	assets := &Int64Array{values: []int64{30}}
+	boxes := &AppBoxRefArray{}
+	require.NoError(t, boxes.Append(20, []byte("foreign")))
+	require.NoError(t, boxes.Append(10, []byte("own")))
+
+	expected, err := MakeApplicationNoOpTx(10, args, accounts, apps, assets, boxes, params, sender, nil, nil)
+	require.NoError(t, err)
+	builder, err := NewTransactionBuilder("appl")
+	require.NoError(t, err)
+	encoded, err := builder.SetSender(sender).SetSuggestedParams(params).SetApplicationID(10).
+		SetApplicationArgs(args).SetAccounts(accounts).SetForeignApps(apps).SetForeignAssets(assets).
+		SetBoxReferences(boxes).Build()
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	// access lists cannot be combined with the other reference lists
+	_, err = builder.AddAccessAddress(types.Address{2}.String()).Build()
+	require.ErrorContains(t, err, "access list")
+	encoded, err = builder.SetAccounts(nil).SetForeignApps(nil).SetForeignAssets(nil).SetBoxReferences(nil).
+		AddAccessAsset(30).AddAccessHolding(1, 2).AddAccessBox(0, []byte("own")).
+		SetOnCompletion(int(types.OptInOC)).SetRejectVersion(2).Build()
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, []types.ResourceRef{
+		{Address: types.Address{2}},
+		{Asset: 30},
+		{Holding: types.HoldingRef{Address: 1, Asset: 2}},
+		{Box: types.BoxReference{Name: []byte("own")}},
+	}, txn.Access)
+	require.Equal(t, types.OptInOC, txn.OnCompletion)
+	require.Nil(t, txn.BoxReferences)
+
+	builder, err = NewTransactionBuilder("appl")
+	require.NoError(t, err)
+	unknownBox := &AppBoxRefArray{}
+	require.NoError(t, unknownBox.Append(99, []byte("box")))
+	_, err = builder.SetSender(sender).SetSuggestedParams(params).SetApplicationID(10).SetBoxReferences(unknownBox).Build()
+	require.ErrorContains(t, err, "not in the foreign apps")
+}
+
+func TestTransactionBuilderHeartbeat(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	builder, err := NewTransactionBuilder("hb")
+	require.NoError(t, err)
+	keyDilution := MakeUint64(10000)
+	sig := bytes.Repeat([]byte{1}, 64)
+	key := bytes.Repeat([]byte{2}, 32)
+	encoded, err := builder.SetSender(sender).SetSuggestedParams(makeTestARC59Params()).
+		SetHeartbeatAddress(types.Address{4}.String()).SetHeartbeatProof(sig, key, key, sig, sig).
+		SetHeartbeatSeed(key).SetHeartbeatVoteID(key).SetHeartbeatKeyDilution(&keyDilution).Build()
+	require.NoError(t, err)
+
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, types.HeartbeatTx, txn.Type)
+	require.Equal(t, types.Address{4}, txn.HbAddress)
+	require.Equal(t, key, txn.HbProof.PK2[:])
+	require.Equal(t, uint64(10000), txn.HbKeyDilution)
+
+	// decoded transactions are built back to the same bytes
+	decoded, err := NewTransactionBuilderFromTxn(encoded)
+	require.NoError(t, err)
+	rebuilt, err := decoded.Build()
+	require.NoError(t, err)
+	require.Equal(t, encoded, rebuilt)
+
+	_, err = NewTransactionBuilderFromTxn([]byte("invalid"))
+	require.Error(t, err)
+}
+
+func TestTransactionBuilderStateProof(t *testing.T) {
+	t.Parallel()
+	message := types.Message{VotersCommitment: []byte("voters"), FirstAttestedRound: 257, LastAttestedRound: 512}
+	stateProof := types.StateProof{SignedWeight: 100, PositionsToReveal: []uint64{1, 2}}
+	builder, err := NewTransactionBuilder("stpf")
+	require.NoError(t, err)
+	encoded, err := builder.SetSender(types.Address{1}.String()).SetSuggestedParams(makeTestARC59Params()).
+		SetStateProofType(0).SetStateProof(msgpack.Encode(&stateProof)).SetStateProofMessage(msgpack.Encode(&message)).Build()
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, message, txn.Message)
+	require.Equal(t, uint64(100), txn.StateProof.SignedWeight)
+}
+
+func TestTransactionBuilderInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := NewTransactionBuilder("unknown")
+	require.ErrorContains(t, err, "unknown transaction type")
+
+	newBuilder := func(txType string) *TransactionBuilder {
+		builder, err := NewTransactionBuilder(txType)
+		require.NoError(t, err)
+		return builder.SetSender(types.Address{1}.String()).SetSuggestedParams(makeTestARC59Params())
+	}
+
+	testcases := []struct {
+		name    string
+		builder *TransactionBuilder
+		err     string
+	}{
+		{"short lease", newBuilder("pay").SetLease([]byte{1}), "lease must be 32 bytes"},
+		{"invalid address", newBuilder("pay").SetReceiver("invalid"), "invalid receiver"},
+		{"first error wins", newBuilder("pay").SetFirstValid(-1).SetReceiver("invalid"), "first valid round"},
+		{"nil amount", newBuilder("pay").SetAmount(nil), "amount must not be nil"},
+		{"no sender", newBuilder("pay").SetSender(""), "sender is not set"},
+		{"no genesis hash", newBuilder("pay").SetGenesisHash(nil), "genesis hash"},
+		{"reversed window", newBuilder("pay").SetLastValid(1), "before first valid round"},
+		{"long window", newBuilder("pay").SetLastValid(1003), "longer than 1000 rounds"},
+		{"long note", newBuilder("pay").SetNote(make([]byte, 1025)), "note is 1025 bytes"},
+		{"foreign fields", newBuilder("pay").SetXferAsset(1), "pay transaction sets fields of axfer transactions"},
+		{"heartbeat fields", newBuilder("axfer").SetHeartbeatSeed(nil), "sets fields of hb transactions"},
+		{"no heartbeat address", newBuilder("hb"), "heartbeat address is not set"},
+		{"decimals", newBuilder("acfg").SetAssetDecimals(20), "decimals must be at most 19"},
+		{"vote range", newBuilder("keyreg").SetVoteFirst(&Uint64{Lower: 2}), "before vote first round"},
+		{"on completion", newBuilder("appl").SetOnCompletion(6), "invalid OnCompletion value"},
+		{"state proof", newBuilder("stpf").SetStateProof([]byte("invalid")), "could not decode state proof"},
+	}
+	for _, testcase := range testcases {
+		_, err := testcase.builder.Build()
+		require.ErrorContains(t, err, testcase.err, testcase.name)
+	}
+}
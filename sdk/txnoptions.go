@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TransactionOptions holds the optional lease and rekey address accepted by
+// every transaction constructor. Passing nil options leaves both unset.
+type TransactionOptions struct {
+	lease         [32]byte
+	rekeyTo       types.Address
+	localAccounts map[types.Address]bool
+}
+
+// NewTransactionOptions creates empty transaction options.
+func NewTransactionOptions() *TransactionOptions {
+	return &TransactionOptions{localAccounts: make(map[types.Address]bool)}
+}
+
+// SetLease sets the 32 byte lease of the transaction. While the transaction is
+// valid, no other transaction of the sender with the same lease can be
+// confirmed. An empty lease clears it.
+func (o *TransactionOptions) SetLease(lease []byte) error {
+	if len(lease) != 0 && len(lease) != len(o.lease) {
+		return fmt.Errorf("lease must be %d bytes, got %d", len(o.lease), len(lease))
+	}
+	o.lease = [32]byte{}
+	copy(o.lease[:], lease)
+	return nil
+}
+
+// SetRekeyTo sets the account the sender is rekeyed to. An empty address clears it.
+func (o *TransactionOptions) SetRekeyTo(rekeyTo string) error {
+	if rekeyTo == "" {
+		o.rekeyTo = types.Address{}
+		return nil
+	}
+	addr, err := types.DecodeAddress(rekeyTo)
+	if err != nil {
+		return fmt.Errorf("invalid rekey address: %v", err)
+	}
+	o.rekeyTo = addr
+	return nil
+}
+
+// AddLocalAccount marks an account as one whose keys are held by the app.
+func (o *TransactionOptions) AddLocalAccount(address string) error {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return err
+	}
+	if o.localAccounts == nil {
+		o.localAccounts = make(map[types.Address]bool)
+	}
+	o.localAccounts[addr] = true
+	return nil
+}
+
+// RekeyWarning returns true if transactions made with these options rekey the
+// sender to an account not added with AddLocalAccount. The app cannot sign for
+// the sender after such a transaction is confirmed.
+func (o *TransactionOptions) RekeyWarning() bool {
+	return !o.rekeyTo.IsZero() && !o.localAccounts[o.rekeyTo]
+}
+
+// applyTransactionOptions sets the options on a transaction made with params,
+// updating a per byte fee for the larger transaction.
+func applyTransactionOptions(tx *types.Transaction, options *TransactionOptions, params types.SuggestedParams) error {
+	if options == nil || (options.lease == [32]byte{} && options.rekeyTo.IsZero()) {
+		return nil
+	}
+	tx.Lease = options.lease
+	tx.RekeyTo = options.rekeyTo
+	return setTxnFee(tx, params)
+}
+
+// setTxnFee sets the fee of a transaction the same way the Make functions of
+// the go-algorand-sdk do: the size is estimated with the suggested fee set.
+func setTxnFee(tx *types.Transaction, params types.SuggestedParams) error {
+	tx.Fee = params.Fee
+	if params.FlatFee {
+		return nil
+	}
+	size, err := transaction.EstimateSize(*tx)
+	if err != nil {
+		return fmt.Errorf("could not estimate transaction size: %v", err)
+	}
+	tx.Fee = types.MicroAlgos(size * uint64(params.Fee))
+	if tx.Fee < transaction.MinTxnFee {
+		tx.Fee = transaction.MinTxnFee
+	}
+	return nil
+}
+
+// groupOptions splits the options of a group of transactions sent by one
+// account. The lease goes on the first transaction, and the rekey on the last
+// one since the sender cannot sign transactions after it in the group.
+type groupOptions struct {
+	options   *TransactionOptions
+	leaseUsed bool
+}
+
+func (g *groupOptions) next(last bool) *TransactionOptions {
+	if g.options == nil {
+		return nil
+	}
+	options := &TransactionOptions{}
+	if !g.leaseUsed {
+		options.lease = g.options.lease
+		g.leaseUsed = true
+	}
+	if last {
+		options.rekeyTo = g.options.rekeyTo
+	}
+	return options
+}
@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestTxnOptions(t *testing.T, rekeyTo types.Address) (*TransactionOptions, [32]byte) {
+	t.Helper()
+	var lease [32]byte
+	copy(lease[:], bytes.Repeat([]byte{7}, 32))
+	options := NewTransactionOptions()
+	require.NoError(t, options.SetLease(lease[:]))
+	require.NoError(t, options.SetRekeyTo(rekeyTo.String()))
+	return options, lease
+}
+
+func TestTransactionOptions(t *testing.T) {
+	t.Parallel()
+	options := NewTransactionOptions()
+	require.False(t, options.RekeyWarning())
+	require.ErrorContains(t, options.SetLease([]byte{1, 2}), "lease must be 32 bytes")
+	require.ErrorContains(t, options.SetRekeyTo("invalid"), "invalid rekey address")
+	require.Error(t, options.AddLocalAccount("invalid"))
+
+	require.NoError(t, options.SetRekeyTo(types.Address{3}.String()))
+	require.True(t, options.RekeyWarning())
+	require.NoError(t, options.AddLocalAccount(types.Address{3}.String()))
+	require.False(t, options.RekeyWarning())
+	require.NoError(t, options.SetRekeyTo(types.Address{4}.String()))
+	require.True(t, options.RekeyWarning())
+	require.NoError(t, options.SetRekeyTo(""))
+	require.False(t, options.RekeyWarning())
+
+	// zero value options, as created by gomobile, accept local accounts as well
+	options = &TransactionOptions{}
+	require.NoError(t, options.SetRekeyTo(types.Address{3}.String()))
+	require.NoError(t, options.AddLocalAccount(types.Address{3}.String()))
+	require.False(t, options.RekeyWarning())
+}
+
+func TestMakeTxnWithOptions(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	receiver := types.Address{2}.String()
+	options, lease := makeTestTxnOptions(t, types.Address{3})
+	amount := MakeUint64(10)
+	params := makeTestARC59Params()
+	params.Fee = 10
+
+	// the per byte fee covers the lease and rekey address, like the transaction builder
+	encoded, err := MakePaymentTxn(sender, receiver, &amount, nil, "", params, options)
+	require.NoError(t, err)
+	builder, err := NewTransactionBuilder("pay")
+	require.NoError(t, err)
+	expected, err := builder.SetSender(sender).SetReceiver(receiver).SetAmount(&amount).SetSuggestedParams(params).
+		SetLease(lease[:]).SetRekeyTo(types.Address{3}.String()).Build()
+	require.NoError(t, err)
+	require.Equal(t, expected, encoded)
+
+	params.FlatFee = true
+	encoded, err = MakeAssetTransferTxn(sender, receiver, "", &amount, nil, params, 5, options)
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, encoded)
+	require.Equal(t, lease, txn.Lease)
+	require.Equal(t, types.Address{3}, txn.RekeyTo)
+	require.Equal(t, types.MicroAlgos(10), txn.Fee)
+
+	// the rekey address of MakeRekeyTxn wins over the options
+	encoded, err = MakeRekeyTxn(sender, types.Address{4}.String(), params, options)
+	require.NoError(t, err)
+	txn = decodeTestTxn(t, encoded)
+	require.Equal(t, lease, txn.Lease)
+	require.Equal(t, types.Address{4}, txn.RekeyTo)
+}
+
+func TestARC59TxnsWithOptions(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	receiver := types.Address{2}.String()
+	options, lease := makeTestTxnOptions(t, types.Address{3})
+	amount := MakeUint64(10)
+	info := &ARC59SendInfo{
+		InnerTxCount:               1,
+		MinimumBalanceRequirement:  &Uint64{Lower: 1000},
+		ReceiverAlgoNeededForClaim: &Uint64{},
+	}
+
+	// the lease goes on the first transaction and the rekey on the last one
+	group, err := MakeARC59SendTxnWithInfo(sender, receiver, &amount, 1, 2, makeTestARC59Params(), info, options)
+	require.NoError(t, err)
+	txns, err := decodeTxns(group)
+	require.NoError(t, err)
+	require.Len(t, txns, 4)
+	for i, txn := range txns {
+		require.Equal(t, i == 0, txn.Lease == lease, i)
+		require.Equal(t, i == 3, txn.RekeyTo == types.Address{3}, i)
+	}
+
+	// a single transaction gets both
+	group, err = MakeARC59RejectTxn(receiver, "", sender, 1, 2, makeTestARC59Params(), false, options)
+	require.NoError(t, err)
+	txns, err = decodeTxns(group)
+	require.NoError(t, err)
+	require.Len(t, txns, 1)
+	require.Equal(t, lease, txns[0].Lease)
+	require.Equal(t, types.Address{3}, txns[0].RekeyTo)
+}
+
+func TestOptInAndAssetTransferWithOptions(t *testing.T) {
+	t.Parallel()
+	sender := types.Address{1}.String()
+	receiver := types.Address{2}.String()
+	options, lease := makeTestTxnOptions(t, types.Address{3})
+	amount := MakeUint64(10)
+	senderBalance := MakeUint64(10000000)
+	minBalance := MakeUint64(100000)
+	zero := MakeUint64(0)
+	params := makeTestARC59Params()
+	params.Fee = 1000
+	params.FlatFee = true
+
+	group, err := MakeOptInAndAssetTransferTxns(sender, receiver, &amount, &senderBalance, &minBalance, &zero, &zero, nil, "", 5, params, options)
+	require.NoError(t, err)
+	txns := decodeTestGroup(t, group)
+	require.Len(t, txns, 3)
+	require.Equal(t, lease, txns[0].Lease)
+	require.True(t, txns[0].RekeyTo.IsZero())
+	require.Equal(t, types.Address{2}, txns[1].Sender)
+	require.Equal(t, [32]byte{}, txns[1].Lease)
+	require.True(t, txns[1].RekeyTo.IsZero())
+	require.Equal(t, [32]byte{}, txns[2].Lease)
+	require.Equal(t, types.Address{3}, txns[2].RekeyTo)
+}
+
+func TestAddMethodCallParamsWithOptions(t *testing.T) {
+	t.Parallel()
+	options, lease := makeTestTxnOptions(t, types.Address{3})
+	method, err := abi.MethodFromSignature("add()uint32")
+	require.NoError(t, err)
+	params := &AddMethodCallParams{
+		value: transaction.AddMethodCallParams{
+			AppID:  4,
+			Method: method,
+			Sender: types.Address{1},
+			Signer: externalToInternalSigner{},
+		},
+	}
+	params.SetTransactionOptions(options)
+
+	atc := NewAtomicTransactionComposer()
+	require.NoError(t, atc.AddMethodCall(params))
+	group, err := atc.BuildGroup()
+	require.NoError(t, err)
+	txn := decodeTestTxn(t, group.Get(0))
+	require.Equal(t, lease, txn.Lease)
+	require.Equal(t, types.Address{3}, txn.RekeyTo)
+
+	params.SetTransactionOptions(nil)
+	require.Equal(t, [32]byte{}, params.value.Lease)
+	require.True(t, params.value.RekeyTo.IsZero())
+}
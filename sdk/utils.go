@@ -84,6 +84,20 @@ func (tsa *TransactionSignerArray) GetTxn(index int) []byte {
 	return tsa.transactions.Get(index)
 }
 
+// TransactionGroupArray is an ordered list of transaction groups, each with the address which must
+// sign every transaction of the group.
+type TransactionGroupArray struct {
+	groups []*TransactionSignerArray
+}
+
+func (tga *TransactionGroupArray) Length() int {
+	return len(tga.groups)
+}
+
+func (tga *TransactionGroupArray) Get(index int) *TransactionSignerArray {
+	return tga.groups[index]
+}
+
 type TransactionSignerItem struct {
 	signer      string
 	transaction []byte